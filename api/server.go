@@ -0,0 +1,178 @@
+// Package api serves the detector's current detections over HTTP for ad-hoc inspection
+// and UI integration.
+package api
+
+import (
+	"cmp"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kloudmate/polylang-detector/detector"
+)
+
+// shutdownTimeout bounds how long in-flight requests are given to finish on shutdown
+const shutdownTimeout = 5 * time.Second
+
+// WorkloadDetections is the response of GET /detections/{namespace}/{workload}
+type WorkloadDetections struct {
+	Namespace      string                   `json:"namespace"`
+	WorkloadName   string                   `json:"workload_name"`
+	WorkloadKind   string                   `json:"workload_kind"`
+	LastDetectedAt time.Time                `json:"last_detected_at"`
+	Containers     []detector.ContainerInfo `json:"containers"`
+	// PrimaryLanguage is the language of the workload's main container, leaving sidecars aside
+	PrimaryLanguage  string `json:"primary_language"`
+	PrimaryContainer string `json:"primary_container,omitempty"`
+}
+
+// errorResponse is the body of non-2xx responses
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Server serves the detection cache as JSON, without the containers' environment variables:
+//
+//	GET /detections                          every cached container
+//	GET /detections?namespace=X              cached containers in namespace X
+//	GET /detections/{namespace}/{workload}   one workload's containers, 404 if not cached
+type Server struct {
+	Addr  string
+	cache *detector.LanguageCache
+	token string
+	// options decide which containers are sidecars when picking a workload's primary language
+	options detector.DetectionOptions
+}
+
+// NewServer returns a server for cache. An empty token disables authentication.
+func NewServer(addr string, cache *detector.LanguageCache, token string) *Server {
+	return &Server{Addr: addr, cache: cache, token: token}
+}
+
+// NewServerFromEnv returns a server listening on KM_API_ADDR and requiring the bearer token
+// KM_API_TOKEN (if set), or nil when KM_API_ADDR is unset.
+func NewServerFromEnv(pd *detector.PolylangDetector) *Server {
+	addr := os.Getenv("KM_API_ADDR")
+	if addr == "" {
+		return nil
+	}
+	server := NewServer(addr, pd.Cache, os.Getenv("KM_API_TOKEN"))
+	server.options = pd.Options
+	return server
+}
+
+// Handler returns the API's HTTP handler
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /detections", s.listDetections)
+	mux.HandleFunc("GET /detections/{namespace}/{workload}", s.getWorkload)
+	return s.authenticate(mux)
+}
+
+// ListenAndServe serves the API on the server's address until ctx is done
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	return s.serve(ctx, listener)
+}
+
+// serve serves the API on listener until ctx is done, then shuts down gracefully
+func (s *Server) serve(ctx context.Context, listener net.Listener) error {
+	server := &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// authenticate rejects requests without the configured bearer token
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	if s.token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "unauthorized"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// listDetections serves every cached container, optionally filtered by ?namespace=
+func (s *Server) listDetections(w http.ResponseWriter, r *http.Request) {
+	namespace := r.URL.Query().Get("namespace")
+
+	detections := []detector.ContainerInfo{}
+	for _, info := range s.cache.GetAllActiveContainers() {
+		if namespace == "" || info.Namespace == namespace {
+			detections = append(detections, info.Redacted())
+		}
+	}
+	sortContainers(detections)
+
+	writeJSON(w, http.StatusOK, detections)
+}
+
+// getWorkload serves one workload's cached containers
+func (s *Server) getWorkload(w http.ResponseWriter, r *http.Request) {
+	entry, found := s.cache.GetWorkload(r.PathValue("namespace"), r.PathValue("workload"))
+	if !found {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "workload not found"})
+		return
+	}
+
+	summary := entry.Summarize(s.options)
+	response := WorkloadDetections{
+		Namespace:        entry.Namespace,
+		WorkloadName:     entry.WorkloadName,
+		WorkloadKind:     entry.WorkloadKind,
+		LastDetectedAt:   entry.LastDetectedAt,
+		Containers:       make([]detector.ContainerInfo, 0, len(entry.Containers)),
+		PrimaryLanguage:  summary.PrimaryLanguage,
+		PrimaryContainer: summary.PrimaryContainer,
+	}
+	for _, info := range entry.Containers {
+		response.Containers = append(response.Containers, info.Redacted())
+	}
+	sortContainers(response.Containers)
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// sortContainers orders detections by namespace, pod and container for stable output
+func sortContainers(containers []detector.ContainerInfo) {
+	slices.SortFunc(containers, func(a, b detector.ContainerInfo) int {
+		return cmp.Or(
+			cmp.Compare(a.Namespace, b.Namespace),
+			cmp.Compare(a.PodName, b.PodName),
+			cmp.Compare(a.ContainerName, b.ContainerName),
+		)
+	})
+}
+
+// writeJSON writes body as a JSON response with the given status
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kloudmate/polylang-detector/detector"
+)
+
+// newTestCache returns a cache holding two workloads in "shop" and one in "billing"
+func newTestCache() *detector.LanguageCache {
+	cache := detector.NewLanguageCache(time.Hour)
+	cache.UpdateWorkloadContainer("shop", "checkout", "Deployment", detector.ContainerInfo{
+		Namespace: "shop", PodName: "checkout-1", ContainerName: "app", Language: "Java",
+		Version: "21", Confidence: "high", ConfidenceScore: 90,
+		Evidence: []string{"[proc] java process detected as Java with high confidence"},
+	})
+	cache.UpdateWorkloadContainer("shop", "cart", "Deployment", detector.ContainerInfo{
+		Namespace: "shop", PodName: "cart-1", ContainerName: "api", Language: "Go", Confidence: "high", ConfidenceScore: 90,
+	})
+	cache.UpdateWorkloadContainer("billing", "invoices", "StatefulSet", detector.ContainerInfo{
+		Namespace: "billing", PodName: "invoices-0", ContainerName: "worker", Language: "Python", Confidence: "medium", ConfidenceScore: 60,
+	})
+	return cache
+}
+
+// get issues a request against the server's handler and decodes the JSON response into out
+func get(t *testing.T, server *Server, target, token string, out any) int {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("%s: expected JSON content type, got %q", target, ct)
+	}
+	if out != nil && rec.Code == http.StatusOK {
+		if err := json.NewDecoder(rec.Body).Decode(out); err != nil {
+			t.Fatalf("%s: failed to decode response: %v", target, err)
+		}
+	}
+	return rec.Code
+}
+
+func TestListDetectionsFiltersByNamespace(t *testing.T) {
+	server := NewServer("", newTestCache(), "")
+
+	var all []detector.ContainerInfo
+	if code := get(t, server, "/detections", "", &all); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if len(all) != 3 || all[0].Namespace != "billing" {
+		t.Errorf("expected 3 detections sorted by namespace, got %+v", all)
+	}
+
+	var shop []detector.ContainerInfo
+	get(t, server, "/detections?namespace=shop", "", &shop)
+	if len(shop) != 2 || shop[0].PodName != "cart-1" || shop[1].PodName != "checkout-1" {
+		t.Errorf("expected the two shop detections, got %+v", shop)
+	}
+
+	var none []detector.ContainerInfo
+	get(t, server, "/detections?namespace=payments", "", &none)
+	if none == nil || len(none) != 0 {
+		t.Errorf("expected an empty list for an unknown namespace, got %+v", none)
+	}
+}
+
+func TestGetWorkloadDetections(t *testing.T) {
+	server := NewServer("", newTestCache(), "")
+
+	var workload WorkloadDetections
+	if code := get(t, server, "/detections/shop/checkout", "", &workload); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if workload.WorkloadKind != "Deployment" || len(workload.Containers) != 1 {
+		t.Fatalf("unexpected workload response %+v", workload)
+	}
+	if workload.PrimaryLanguage != "Java" || workload.PrimaryContainer != "app" {
+		t.Errorf("expected the app container to be primary, got %s (%s)", workload.PrimaryContainer, workload.PrimaryLanguage)
+	}
+	container := workload.Containers[0]
+	if container.Confidence != "high" || container.Version != "21" || len(container.Evidence) != 1 {
+		t.Errorf("expected confidence, version and evidence in the response, got %+v", container)
+	}
+
+	if code := get(t, server, "/detections/shop/unknown", "", nil); code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown workload, got %d", code)
+	}
+}
+
+func TestAPIRequiresBearerToken(t *testing.T) {
+	server := NewServer("", newTestCache(), "secret")
+
+	for _, token := range []string{"", "wrong"} {
+		if code := get(t, server, "/detections", token, nil); code != http.StatusUnauthorized {
+			t.Errorf("token %q: expected 401, got %d", token, code)
+		}
+	}
+	if code := get(t, server, "/detections", "secret", nil); code != http.StatusOK {
+		t.Errorf("expected 200 with the configured token, got %d", code)
+	}
+}
+
+func TestServeStopsOnContextCancel(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- NewServer("", newTestCache(), "").serve(ctx, listener) }()
+
+	resp, err := http.Get("http://" + listener.Addr().String() + "/detections/billing/invoices")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200, got %d", resp.StatusCode)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected a clean shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop after cancel")
+	}
+}
+
+func TestDetectionsOmitEnvVars(t *testing.T) {
+	cache := detector.NewLanguageCache(time.Hour)
+	cache.UpdateWorkloadContainer("shop", "checkout", "Deployment", detector.ContainerInfo{
+		Namespace: "shop", PodName: "checkout-1", ContainerName: "app", Language: "Java",
+		EnvVars: map[string]string{"DB_PASSWORD": "hunter2"},
+	})
+	server := NewServer("", cache, "")
+
+	var all []detector.ContainerInfo
+	get(t, server, "/detections", "", &all)
+	if len(all) != 1 || all[0].EnvVars != nil {
+		t.Errorf("expected env vars to be stripped from the list, got %+v", all)
+	}
+
+	var workload WorkloadDetections
+	get(t, server, "/detections/shop/checkout", "", &workload)
+	if len(workload.Containers) != 1 || workload.Containers[0].EnvVars != nil {
+		t.Errorf("expected env vars to be stripped from the workload, got %+v", workload.Containers)
+	}
+}
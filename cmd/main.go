@@ -9,10 +9,13 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/kloudmate/polylang-detector/api"
 	"github.com/kloudmate/polylang-detector/detector"
 	"github.com/kloudmate/polylang-detector/pkg/logger"
 	"github.com/kloudmate/polylang-detector/rpc"
+	"github.com/kloudmate/polylang-detector/sink"
 	"github.com/kloudmate/polylang-detector/workload"
+	"go.uber.org/zap"
 )
 
 var (
@@ -45,12 +48,64 @@ func main() {
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
 	langDetector := detector.NewPolylangDetector(k8sConfig, k8sClient, domainLogger)
-
-	go func() {
-		if err := langDetector.DialWithRetry(ctx, time.Second*10); err != nil {
-			domainLogger.Error("RPC connection permanently failed")
+	langDetector.DetectorVersion = version
+	langDetector.DetectorCommit = commit
+	fileSink, err := sink.NewFileSinkFromEnv()
+	if err != nil {
+		domainLogger.Error("Failed to initialize file sink", zap.Error(err))
+		os.Exit(1)
+	}
+	if fileSink != nil {
+		langDetector.Sinks = append(langDetector.Sinks, fileSink)
+	}
+	// Dry-run pushes nothing anywhere, so only the local file sink is kept
+	if !langDetector.DryRun {
+		otlpSink, err := sink.NewOTLPSinkFromEnv(ctx)
+		if err != nil {
+			domainLogger.Error("Failed to initialize OTLP sink", zap.Error(err))
+			os.Exit(1)
 		}
-	}()
+		if otlpSink != nil {
+			otlpSink.ConfidenceUpgrades = langDetector.Cache.ConfidenceUpgrades
+			langDetector.Sinks = append(langDetector.Sinks, otlpSink)
+		}
+		if webhookSink := sink.NewWebhookSinkFromEnv(domainLogger); webhookSink != nil {
+			langDetector.BatchSinks = append(langDetector.BatchSinks, webhookSink)
+		}
+	}
+
+	if err := langDetector.ValidateServerAddr(); err != nil {
+		domainLogger.Error("Invalid updater RPC address", zap.Error(err))
+		os.Exit(1)
+	}
+
+	if langDetector.DryRun {
+		domainLogger.Info("Dry run enabled, detection results are logged instead of sent to the updater")
+	} else if langDetector.ServerAddr == "" {
+		domainLogger.Info("No updater RPC address set, detection results are only written to the configured sinks")
+	} else {
+		go func() {
+			if err := langDetector.DialWithRetry(ctx, time.Second); err != nil {
+				domainLogger.Error("RPC connection permanently failed")
+			}
+		}()
+	}
+
+	if queryServer := rpc.NewQueryServerFromEnv(langDetector); queryServer != nil {
+		go func() {
+			if err := queryServer.ListenAndServe(ctx); err != nil {
+				domainLogger.Error("Detection query server failed", zap.String("address", queryServer.Addr), zap.Error(err))
+			}
+		}()
+	}
+
+	if apiServer := api.NewServerFromEnv(langDetector); apiServer != nil {
+		go func() {
+			if err := apiServer.ListenAndServe(ctx); err != nil {
+				domainLogger.Error("Detection API server failed", zap.String("address", apiServer.Addr), zap.Error(err))
+			}
+		}()
+	}
 
 	go workload.ScanPodsEbpf(ctx, k8sClient, langDetector, &wg)
 	go rpc.SendDataToUpdater(langDetector, k8sClient, k8sConfig, ctx, &wg)
@@ -3,8 +3,13 @@ package detector
 import (
 	"crypto/sha256"
 	"fmt"
+	"maps"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/kloudmate/polylang-detector/detector/inspectors"
 )
 
 // LanguageCache provides thread-safe caching of language detection results
@@ -14,6 +19,16 @@ type LanguageCache struct {
 	mu            sync.RWMutex
 	cache         map[string]*CacheEntry         // Image-based cache: key -> CacheEntry
 	workloadCache map[string]*WorkloadCacheEntry // Workload-based cache: namespace/workloadName -> WorkloadCacheEntry
+
+	// OnLanguageChange, if set, is called when a workload container's language or framework
+	// differs from the one previously cached for it. It is called with the cache lock held.
+	OnLanguageChange func(workloadName string, previous, current ContainerInfo)
+	// OnConfidenceUpgrade, if set, is called when a workload container is re-detected as the
+	// same language with higher confidence than it was ever cached with. It is called with
+	// the cache lock held.
+	OnConfidenceUpgrade func(workloadName string, previous, current ContainerInfo)
+
+	confidenceUpgrades atomic.Int64
 }
 
 // CacheEntry represents a cached detection result (no expiration)
@@ -27,6 +42,22 @@ type WorkloadCacheEntry struct {
 	WorkloadName string
 	WorkloadKind string
 	Containers   map[string]ContainerInfo // containerName -> ContainerInfo
+
+	// LastDetectedAt is when the workload's containers were last stored, used to judge staleness
+	LastDetectedAt time.Time
+	// DetectionCount counts how many times detection results were stored for the workload
+	DetectionCount int
+
+	// bestConfidence is the highest confidence seen per container and language
+	// (containerName/language), so a lower-confidence detection in between doesn't make the
+	// next higher one count as an upgrade again
+	bestConfidence map[string]inspectors.Confidence
+}
+
+// recordDetection stamps the entry as refreshed now
+func (e *WorkloadCacheEntry) recordDetection() {
+	e.LastDetectedAt = time.Now()
+	e.DetectionCount++
 }
 
 // NewLanguageCache creates a new cache (ttl parameter kept for compatibility but not used)
@@ -91,16 +122,24 @@ func (lc *LanguageCache) SetWorkload(namespace, workloadName, workloadKind strin
 	defer lc.mu.Unlock()
 
 	key := namespace + "/" + workloadName
-	lc.workloadCache[key] = &WorkloadCacheEntry{
+	entry := &WorkloadCacheEntry{
 		Namespace:    namespace,
 		WorkloadName: workloadName,
 		WorkloadKind: workloadKind,
 		Containers:   containers,
 	}
+	if previous, exists := lc.workloadCache[key]; exists {
+		entry.DetectionCount = previous.DetectionCount
+		entry.bestConfidence = previous.bestConfidence
+	}
+	entry.recordDetection()
+	lc.workloadCache[key] = entry
 }
 
-// UpdateWorkloadContainer updates a single container in a workload's cache
-func (lc *LanguageCache) UpdateWorkloadContainer(namespace, workloadName, workloadKind string, info ContainerInfo) {
+// UpdateWorkloadContainer updates a single container in a workload's cache. If the container
+// was cached with a different language or framework, the stored and returned info are flagged
+// as a language change so the updater can treat them as an update rather than a create.
+func (lc *LanguageCache) UpdateWorkloadContainer(namespace, workloadName, workloadKind string, info ContainerInfo) ContainerInfo {
 	lc.mu.Lock()
 	defer lc.mu.Unlock()
 
@@ -116,18 +155,58 @@ func (lc *LanguageCache) UpdateWorkloadContainer(namespace, workloadName, worklo
 		}
 		lc.workloadCache[key] = entry
 	}
+	if entry.bestConfidence == nil {
+		entry.bestConfidence = make(map[string]inspectors.Confidence)
+	}
 
+	info.LanguageChanged, info.PreviousLanguage = false, ""
+	bestKey := info.ContainerName + "/" + info.Language
+	best := entry.bestConfidence[bestKey]
+	if previous, cached := entry.Containers[info.ContainerName]; cached {
+		if previous.Language != info.Language || previous.Framework != info.Framework {
+			info.LanguageChanged = true
+			info.PreviousLanguage = previous.Language
+			if lc.OnLanguageChange != nil {
+				lc.OnLanguageChange(workloadName, previous, info)
+			}
+		}
+		if previous.Language == info.Language {
+			best = max(best, previous.confidenceScore())
+			if info.confidenceScore() > best {
+				lc.confidenceUpgrades.Add(1)
+				if lc.OnConfidenceUpgrade != nil {
+					lc.OnConfidenceUpgrade(workloadName, previous, info)
+				}
+			}
+		}
+	}
+
+	entry.bestConfidence[bestKey] = max(best, info.confidenceScore())
 	entry.Containers[info.ContainerName] = info
+	entry.recordDetection()
+	return info
 }
 
-// GetWorkload retrieves cached detection results for a workload
+// ConfidenceUpgrades returns how many times a cached container was re-detected with higher
+// confidence than it was ever cached with
+func (lc *LanguageCache) ConfidenceUpgrades() int64 {
+	return lc.confidenceUpgrades.Load()
+}
+
+// GetWorkload retrieves cached detection results for a workload. The entry is a copy, so
+// it can be read while detection keeps updating the cache.
 func (lc *LanguageCache) GetWorkload(namespace, workloadName string) (*WorkloadCacheEntry, bool) {
 	lc.mu.RLock()
 	defer lc.mu.RUnlock()
 
 	key := namespace + "/" + workloadName
 	entry, exists := lc.workloadCache[key]
-	return entry, exists
+	if !exists {
+		return nil, false
+	}
+	snapshot := *entry
+	snapshot.Containers = maps.Clone(entry.Containers)
+	return &snapshot, true
 }
 
 // RemoveWorkload completely removes a workload from the cache
@@ -139,7 +218,8 @@ func (lc *LanguageCache) RemoveWorkload(namespace, workloadName string) {
 	delete(lc.workloadCache, key)
 }
 
-// GetAllActiveWorkloads returns all workloads in the cache
+// GetAllActiveWorkloads returns all workloads in the cache, least recently detected first,
+// so a reconciliation pass can re-scan the stalest workloads before the rest
 func (lc *LanguageCache) GetAllActiveWorkloads() []WorkloadCacheEntry {
 	lc.mu.RLock()
 	defer lc.mu.RUnlock()
@@ -148,6 +228,9 @@ func (lc *LanguageCache) GetAllActiveWorkloads() []WorkloadCacheEntry {
 	for _, entry := range lc.workloadCache {
 		workloads = append(workloads, *entry)
 	}
+	slices.SortFunc(workloads, func(a, b WorkloadCacheEntry) int {
+		return a.LastDetectedAt.Compare(b.LastDetectedAt)
+	})
 
 	return workloads
 }
@@ -0,0 +1,137 @@
+package detector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kloudmate/polylang-detector/detector/inspectors"
+)
+
+func TestUpdateWorkloadContainerFlagsLanguageChange(t *testing.T) {
+	cache := NewLanguageCache(0)
+
+	var changes []string
+	cache.OnLanguageChange = func(workloadName string, previous, current ContainerInfo) {
+		changes = append(changes, workloadName+": "+previous.Language+" -> "+current.Language)
+	}
+
+	java := ContainerInfo{Namespace: "shop", ContainerName: "app", Language: "Java", Framework: "Spring Boot"}
+	if stored := cache.UpdateWorkloadContainer("shop", "checkout", "Deployment", java); stored.LanguageChanged {
+		t.Error("expected the first detection not to be flagged as a change")
+	}
+
+	python := ContainerInfo{Namespace: "shop", ContainerName: "app", Language: "Python", Framework: "FastAPI"}
+	stored := cache.UpdateWorkloadContainer("shop", "checkout", "Deployment", python)
+	if !stored.LanguageChanged || stored.PreviousLanguage != "Java" {
+		t.Errorf("expected change from Java to be flagged, got changed=%v previous=%q", stored.LanguageChanged, stored.PreviousLanguage)
+	}
+	if len(changes) != 1 || changes[0] != "checkout: Java -> Python" {
+		t.Errorf("expected one change event, got %v", changes)
+	}
+
+	// A steady-state report of the same language clears the flag
+	if stored := cache.UpdateWorkloadContainer("shop", "checkout", "Deployment", python); stored.LanguageChanged {
+		t.Error("expected an unchanged detection not to be flagged")
+	}
+	entry, _ := cache.GetWorkload("shop", "checkout")
+	if entry.Containers["app"].LanguageChanged {
+		t.Error("expected the cached entry to be cleared of the change flag")
+	}
+	if len(changes) != 1 {
+		t.Errorf("expected no further change events, got %v", changes)
+	}
+}
+
+func TestUpdateWorkloadContainerReportsConfidenceUpgrades(t *testing.T) {
+	tests := []struct {
+		name       string
+		previous   inspectors.Confidence
+		current    inspectors.Confidence
+		wantEvents int
+	}{
+		{"upgrade", inspectors.ConfidenceMedium, inspectors.ConfidenceHigh, 1},
+		{"downgrade", inspectors.ConfidenceHigh, inspectors.ConfidenceMedium, 0},
+		{"no change", inspectors.ConfidenceHigh, inspectors.ConfidenceHigh, 0},
+	}
+
+	for _, tt := range tests {
+		cache := NewLanguageCache(time.Hour)
+		var upgrades []string
+		cache.OnConfidenceUpgrade = func(workloadName string, previous, current ContainerInfo) {
+			upgrades = append(upgrades, workloadName+": "+previous.Confidence+" -> "+current.Confidence)
+		}
+
+		info := ContainerInfo{Namespace: "shop", ContainerName: "app", Language: "Java"}
+		info.setConfidence(tt.previous)
+		cache.UpdateWorkloadContainer("shop", "checkout", "Deployment", info)
+		info.setConfidence(tt.current)
+		cache.UpdateWorkloadContainer("shop", "checkout", "Deployment", info)
+
+		if len(upgrades) != tt.wantEvents || cache.ConfidenceUpgrades() != int64(tt.wantEvents) {
+			t.Errorf("%s: expected %d upgrade events, got %v (counter %d)", tt.name, tt.wantEvents, upgrades, cache.ConfidenceUpgrades())
+		}
+		if tt.wantEvents == 1 && upgrades[0] != "checkout: medium -> high" {
+			t.Errorf("%s: unexpected upgrade event %q", tt.name, upgrades[0])
+		}
+	}
+}
+
+func TestAlternatingConfidenceCountsOneUpgrade(t *testing.T) {
+	cache := NewLanguageCache(time.Hour)
+	info := ContainerInfo{Namespace: "shop", ContainerName: "app", Language: "Java"}
+
+	// eBPF exec events and /proc rescans keep reporting the same container at different confidences
+	for range 3 {
+		info.setConfidence(inspectors.ConfidenceMedium)
+		cache.UpdateWorkloadContainer("shop", "checkout", "Deployment", info)
+		info.setConfidence(inspectors.ConfidenceHigh)
+		cache.UpdateWorkloadContainer("shop", "checkout", "Deployment", info)
+	}
+
+	if upgrades := cache.ConfidenceUpgrades(); upgrades != 1 {
+		t.Errorf("expected one upgrade above the previous best, got %d", upgrades)
+	}
+}
+
+func TestUpdateWorkloadContainerRecordsDetectionTime(t *testing.T) {
+	cache := NewLanguageCache(0)
+	info := ContainerInfo{Namespace: "shop", ContainerName: "app", Language: "Go"}
+
+	before := time.Now()
+	cache.UpdateWorkloadContainer("shop", "cart", "Deployment", info)
+	entry, _ := cache.GetWorkload("shop", "cart")
+	first := entry.LastDetectedAt
+	if first.Before(before) || entry.DetectionCount != 1 {
+		t.Fatalf("expected a fresh timestamp and count 1, got %v and %d", first, entry.DetectionCount)
+	}
+
+	cache.UpdateWorkloadContainer("shop", "cart", "Deployment", info)
+	entry, _ = cache.GetWorkload("shop", "cart")
+	if !entry.LastDetectedAt.After(first) || entry.DetectionCount != 2 {
+		t.Errorf("expected the timestamp to advance and count 2, got %v and %d", entry.LastDetectedAt, entry.DetectionCount)
+	}
+
+	cache.SetWorkload("shop", "cart", "Deployment", map[string]ContainerInfo{"app": info})
+	entry, _ = cache.GetWorkload("shop", "cart")
+	if entry.DetectionCount != 3 {
+		t.Errorf("expected SetWorkload to keep counting, got %d", entry.DetectionCount)
+	}
+}
+
+func TestGetAllActiveWorkloadsOldestFirst(t *testing.T) {
+	cache := NewLanguageCache(0)
+	for _, name := range []string{"cart", "checkout", "search"} {
+		cache.UpdateWorkloadContainer("shop", name, "Deployment", ContainerInfo{Namespace: "shop", ContainerName: "app"})
+	}
+	// Refreshing cart makes it the most recently detected
+	cache.UpdateWorkloadContainer("shop", "cart", "Deployment", ContainerInfo{Namespace: "shop", ContainerName: "app"})
+
+	workloads := cache.GetAllActiveWorkloads()
+	var order []string
+	for _, workload := range workloads {
+		order = append(order, workload.WorkloadName)
+	}
+	if len(order) != 3 || order[0] != "checkout" || order[2] != "cart" {
+		t.Errorf("expected stalest workload first, got %v", order)
+	}
+}
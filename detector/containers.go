@@ -0,0 +1,125 @@
+package detector
+
+import (
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Container classes reported on ContainerInfo.ContainerClass
+const (
+	ContainerClassApp       = "app"
+	ContainerClassInit      = "init"
+	ContainerClassEphemeral = "ephemeral"
+)
+
+// podContainer is a container spec together with its class
+type podContainer struct {
+	Container corev1.Container
+	Class     string
+}
+
+// podContainers returns the app containers of a pod followed by its init containers
+// (when includeInit is set) and ephemeral debug containers
+func podContainers(pod *corev1.Pod, includeInit bool) []podContainer {
+	var containers []podContainer
+	for _, c := range pod.Spec.Containers {
+		containers = append(containers, podContainer{Container: c, Class: ContainerClassApp})
+	}
+	if includeInit {
+		for _, c := range pod.Spec.InitContainers {
+			containers = append(containers, podContainer{Container: c, Class: ContainerClassInit})
+		}
+	}
+	for _, ec := range pod.Spec.EphemeralContainers {
+		containers = append(containers, podContainer{Container: corev1.Container(ec.EphemeralContainerCommon), Class: ContainerClassEphemeral})
+	}
+	return containers
+}
+
+// allContainerStatuses returns app, init, and ephemeral container statuses of a pod
+func allContainerStatuses(pod *corev1.Pod) []corev1.ContainerStatus {
+	statuses := make([]corev1.ContainerStatus, 0,
+		len(pod.Status.ContainerStatuses)+len(pod.Status.InitContainerStatuses)+len(pod.Status.EphemeralContainerStatuses))
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+	statuses = append(statuses, pod.Status.InitContainerStatuses...)
+	statuses = append(statuses, pod.Status.EphemeralContainerStatuses...)
+	return statuses
+}
+
+// containerTerminated reports whether a container's current instance has exited
+func containerTerminated(pod *corev1.Pod, containerName string) bool {
+	for _, status := range allContainerStatuses(pod) {
+		if status.Name == containerName {
+			return status.State.Terminated != nil
+		}
+	}
+	return false
+}
+
+// containerImageRef returns the resolved image digest (ImageID) from the container's status,
+// falling back to the image tag from the spec when the status isn't populated yet.
+// Mutable tags such as :latest can point at different content, so the digest makes a better cache key.
+func containerImageRef(pod *corev1.Pod, container *corev1.Container) string {
+	for _, status := range allContainerStatuses(pod) {
+		if status.Name == container.Name && status.ImageID != "" {
+			return status.ImageID
+		}
+	}
+	return container.Image
+}
+
+// containerRuntime returns the runtime running a container (e.g. "containerd", "docker", "cri-o"),
+// read from the prefix of its status's container ID, or "" when the status isn't populated yet
+func containerRuntime(pod *corev1.Pod, containerName string) string {
+	for _, status := range allContainerStatuses(pod) {
+		if status.Name == containerName {
+			return runtimeFromContainerID(status.ContainerID)
+		}
+	}
+	return ""
+}
+
+// containerStatusID returns the ID of a container's current instance, without the
+// "<runtime>://" prefix, or "" when the status isn't populated yet
+func containerStatusID(pod *corev1.Pod, containerName string) string {
+	for _, status := range allContainerStatuses(pod) {
+		if status.Name == containerName {
+			_, id, _ := strings.Cut(status.ContainerID, "://")
+			return id
+		}
+	}
+	return ""
+}
+
+// runtimeFromContainerID returns the runtime prefix of a "<runtime>://<id>" container ID
+func runtimeFromContainerID(containerID string) string {
+	runtime, _, found := strings.Cut(containerID, "://")
+	if !found {
+		return ""
+	}
+	return runtime
+}
+
+// forEachContainer runs fn for every container with at most limit calls in flight.
+// fn receives the container's index so callers can store results in a pre-sized slice
+// and keep the output order deterministic.
+func forEachContainer(containers []podContainer, limit int, fn func(i int, pc podContainer)) {
+	if limit <= 0 {
+		limit = 1
+	}
+
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i, pc := range containers {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, pc podContainer) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i, pc)
+		}(i, pc)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,303 @@
+package detector
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPodContainersIncludesInitAndEphemeral(t *testing.T) {
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "migrate", Image: "flyway:10"}},
+			Containers:     []corev1.Container{{Name: "api", Image: "api-node:1.0"}},
+			EphemeralContainers: []corev1.EphemeralContainer{
+				{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger", Image: "busybox"}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		includeInit bool
+		expected    map[string]string
+	}{
+		{
+			name:        "init containers enabled",
+			includeInit: true,
+			expected:    map[string]string{"api": ContainerClassApp, "migrate": ContainerClassInit, "debugger": ContainerClassEphemeral},
+		},
+		{
+			name:        "init containers disabled",
+			includeInit: false,
+			expected:    map[string]string{"api": ContainerClassApp, "debugger": ContainerClassEphemeral},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			containers := podContainers(pod, tt.includeInit)
+			if len(containers) != len(tt.expected) {
+				t.Fatalf("expected %d containers, got %d", len(tt.expected), len(containers))
+			}
+			if containers[0].Container.Name != "api" {
+				t.Errorf("expected app containers first, got %s", containers[0].Container.Name)
+			}
+			for _, pc := range containers {
+				if class := tt.expected[pc.Container.Name]; class != pc.Class {
+					t.Errorf("container %s: expected class %q, got %q", pc.Container.Name, class, pc.Class)
+				}
+			}
+		})
+	}
+}
+
+func TestFindContainerByIDResolvesInitContainer(t *testing.T) {
+	initID := "containerd://aaaaaaaaaaaa1111"
+	appID := "containerd://bbbbbbbbbbbb2222"
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "migrate", Image: "migrate-python:1.0"}},
+			Containers:     []corev1.Container{{Name: "api", Image: "api-java:1.0"}},
+		},
+		Status: corev1.PodStatus{
+			InitContainerStatuses: []corev1.ContainerStatus{{Name: "migrate", ContainerID: initID}},
+			ContainerStatuses:     []corev1.ContainerStatus{{Name: "api", ContainerID: appID}},
+		},
+	}
+
+	ed := &EBPFDetector{podIndexer: newTestPodIndexer(t, pod)}
+
+	_, container, class := ed.findContainerByID("aaaaaaaaaaaa")
+	if container == nil || container.Name != "migrate" || class != ContainerClassInit {
+		t.Errorf("expected init container migrate, got %v (%s)", container, class)
+	}
+
+	_, container, class = ed.findContainerByID("bbbbbbbbbbbb")
+	if container == nil || container.Name != "api" || class != ContainerClassApp {
+		t.Errorf("expected app container api, got %v (%s)", container, class)
+	}
+}
+
+func TestForEachContainerBoundsConcurrency(t *testing.T) {
+	containers := make([]podContainer, 12)
+	for i := range containers {
+		containers[i] = podContainer{Container: corev1.Container{Name: fmt.Sprintf("c%d", i)}, Class: ContainerClassApp}
+	}
+
+	var inFlight, maxInFlight int32
+	seen := make([]string, len(containers))
+	forEachContainer(containers, 3, func(i int, pc podContainer) {
+		current := atomic.AddInt32(&inFlight, 1)
+		for {
+			observed := atomic.LoadInt32(&maxInFlight)
+			if current <= observed || atomic.CompareAndSwapInt32(&maxInFlight, observed, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		seen[i] = pc.Container.Name
+		atomic.AddInt32(&inFlight, -1)
+	})
+
+	if maxInFlight > 3 {
+		t.Errorf("expected at most 3 concurrent detections, observed %d", maxInFlight)
+	}
+	for i, name := range seen {
+		if name != containers[i].Container.Name {
+			t.Errorf("container %d was not detected (got %q)", i, name)
+		}
+	}
+}
+
+func BenchmarkForEachContainer(b *testing.B) {
+	containers := make([]podContainer, 8)
+	for i := 0; i < b.N; i++ {
+		forEachContainer(containers, 4, func(int, podContainer) {
+			time.Sleep(100 * time.Microsecond)
+		})
+	}
+}
+
+func TestContainerImageRefUsesDigest(t *testing.T) {
+	container := corev1.Container{Name: "app", Image: "myapp:latest"}
+	podWithDigest := func(digest string) *corev1.Pod {
+		return &corev1.Pod{
+			Spec: corev1.PodSpec{Containers: []corev1.Container{container}},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "app", Image: "myapp:latest", ImageID: digest}},
+			},
+		}
+	}
+
+	first := containerImageRef(podWithDigest("docker.io/library/myapp@sha256:aaaa"), &container)
+	second := containerImageRef(podWithDigest("docker.io/library/myapp@sha256:bbbb"), &container)
+	pending := containerImageRef(podWithDigest(""), &container)
+
+	if pending != "myapp:latest" {
+		t.Errorf("expected fallback to image tag, got %q", pending)
+	}
+
+	lc := NewLanguageCache(0)
+	if lc.generateKey(first, nil) == lc.generateKey(second, nil) {
+		t.Error("expected different digests under the same tag to produce different cache keys")
+	}
+}
+
+func TestContainerRuntimeFromStatusPrefix(t *testing.T) {
+	tests := []struct {
+		containerID string
+		want        string
+	}{
+		{"docker://" + testContainerID, "docker"},
+		{"containerd://" + testContainerID, "containerd"},
+		{"cri-o://" + testContainerID, "cri-o"},
+		{testContainerID, ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		pod := &corev1.Pod{Status: corev1.PodStatus{
+			InitContainerStatuses: []corev1.ContainerStatus{{Name: "app", ContainerID: tt.containerID}},
+		}}
+		if got := containerRuntime(pod, "app"); got != tt.want {
+			t.Errorf("containerRuntime(%q) = %q, want %q", tt.containerID, got, tt.want)
+		}
+		if got := containerRuntime(pod, "sidecar"); got != "" {
+			t.Errorf("expected no runtime for a container without status, got %q", got)
+		}
+	}
+}
+
+func TestContainersToScanSkipsSidecars(t *testing.T) {
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "istio-init", Image: "istio/proxyv2"}},
+			Containers: []corev1.Container{
+				{Name: "istio-proxy", Image: "istio/proxyv2"},
+				{Name: "app", Image: "shop/api:1.0"},
+			},
+		},
+	}
+
+	opts := DetectionOptions{ScanInitContainers: true, SkipContainerNames: defaultSkipContainerNames}
+	containers := opts.containersToScan(pod)
+
+	if len(containers) != 1 || containers[0].Container.Name != "app" {
+		t.Fatalf("expected only the app container, got %+v", containers)
+	}
+}
+
+func TestContainersToScanSkipsTerminatedContainers(t *testing.T) {
+	always := corev1.ContainerRestartPolicyAlways
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{
+				{Name: "migrate", Image: "flyway:10"},
+				{Name: "log-forwarder", Image: "shop/forwarder:1.0", RestartPolicy: &always},
+			},
+			Containers: []corev1.Container{{Name: "app", Image: "shop/api:1.0"}},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			InitContainerStatuses: []corev1.ContainerStatus{
+				{Name: "migrate", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0, Reason: "Completed"}}},
+				{Name: "log-forwarder", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+			},
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "app", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+			},
+		},
+	}
+
+	var names []string
+	for _, pc := range (DetectionOptions{ScanInitContainers: true}).containersToScan(pod) {
+		names = append(names, pc.Container.Name)
+	}
+	if !slices.Equal(names, []string{"app", "log-forwarder"}) {
+		t.Errorf("expected the completed init container to be skipped and the native sidecar kept, got %v", names)
+	}
+}
+
+func TestContainersToScanSkipsIgnoredContainers(t *testing.T) {
+	t.Setenv("KM_IGNORED_CONTAINER_NAMES", "fluent-bit, re:.*-reloader, re:[invalid")
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "fluent-bit", Image: "fluent/fluent-bit"},
+				{Name: "config-reloader", Image: "jimmidyson/configmap-reload"},
+				{Name: "reloader-api", Image: "shop/reloader-api:1.0"},
+				{Name: "app", Image: "shop/api:1.0"},
+			},
+		},
+	}
+
+	opts := NewDetectionOptionsFromEnv()
+	var names []string
+	for _, pc := range opts.containersToScan(pod) {
+		names = append(names, pc.Container.Name)
+	}
+
+	if !slices.Equal(names, []string{"reloader-api", "app"}) {
+		t.Fatalf("expected only reloader-api and app to be scanned, got %v", names)
+	}
+	if !opts.SkipsContainer("config-reloader") || opts.SkipsContainer("app") {
+		t.Error("expected SkipsContainer to apply the ignored container names")
+	}
+	if errs := opts.IgnoredContainers.Errors(); len(errs) != 1 || !strings.Contains(errs[0].Error(), "re:[invalid") {
+		t.Errorf("expected the invalid pattern to be reported, got %v", errs)
+	}
+}
+
+func TestShouldDetectPod(t *testing.T) {
+	readyPod := func() *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "checkout"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}, {Name: "worker"}}},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "app", Ready: true},
+					{Name: "worker", Ready: true},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(*corev1.Pod)
+		want   bool
+	}{
+		{"running and ready", func(*corev1.Pod) {}, true},
+		{"pending", func(p *corev1.Pod) { p.Status.Phase = corev1.PodPending }, false},
+		{"succeeded", func(p *corev1.Pod) { p.Status.Phase = corev1.PodSucceeded }, false},
+		{"failed", func(p *corev1.Pod) { p.Status.Phase = corev1.PodFailed }, false},
+		{"terminating", func(p *corev1.Pod) {
+			now := metav1.Now()
+			p.DeletionTimestamp = &now
+		}, false},
+		{"container not ready", func(p *corev1.Pod) { p.Status.ContainerStatuses[1].Ready = false }, false},
+		{"container status missing", func(p *corev1.Pod) { p.Status.ContainerStatuses = p.Status.ContainerStatuses[:1] }, false},
+		{"ignored container not ready", func(p *corev1.Pod) {
+			p.Spec.Containers = append(p.Spec.Containers, corev1.Container{Name: "config-reloader"})
+			p.Status.ContainerStatuses = append(p.Status.ContainerStatuses, corev1.ContainerStatus{Name: "config-reloader"})
+		}, true},
+	}
+
+	options := DetectionOptions{IgnoredContainers: NewContainerNameMatcher([]string{"re:.*-reloader"})}
+
+	for _, tt := range tests {
+		pod := readyPod()
+		tt.mutate(pod)
+		if got := options.ShouldDetectPod(pod); got != tt.want {
+			t.Errorf("%s: ShouldDetectPod = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package detector
+
+import (
+	"github.com/kloudmate/polylang-detector/detector/inspectors"
+	"github.com/kloudmate/polylang-detector/detector/process"
+)
+
+// DetectByPID detects the language of a single running process, for callers that monitor
+// processes without a Kubernetes pod. If the process is a shell wrapper, the application it
+// started is inspected instead. When inspectors disagree the returned error is an
+// *inspectors.ErrLanguageDetectionConflict, which callers can match with errors.As.
+func DetectByPID(pid int) (*inspectors.DetectionResult, error) {
+	_, result, err := detectPID(inspectors.NewLanguageDetector(), pid)
+	return result, err
+}
+
+// detectPID builds the process context for pid (following shell wrappers) and runs
+// detection on it, returning the context that was inspected along with the result
+func detectPID(ld *inspectors.LanguageDetector, pid int) (*process.ProcessContext, *inspectors.DetectionResult, error) {
+	procCtx, err := process.GetProcessContext(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Entrypoint scripts run the application as a child of a shell; detect on that child instead
+	procCtx = process.FollowShellWrapper(procCtx)
+
+	result, err := ld.Detect(procCtx)
+	if err != nil {
+		return procCtx, nil, err
+	}
+	return procCtx, result, nil
+}
@@ -0,0 +1,36 @@
+package detector
+
+import (
+	"testing"
+
+	"github.com/kloudmate/polylang-detector/detector/inspectors"
+	"github.com/kloudmate/polylang-detector/internal/proctest"
+)
+
+func TestDetectByPIDFollowsShellWrapper(t *testing.T) {
+	procRoot := proctest.New(t)
+	proctest.WriteProcess(t, procRoot, proctest.Process{PID: 1, Cmdline: "/bin/sh\x00/entrypoint.sh\x00", Cgroup: "0::/\n"})
+	proctest.WriteProcess(t, procRoot, proctest.Process{
+		PID:     7,
+		PPID:    1,
+		Exe:     "/usr/local/bin/python3.12",
+		Cmdline: "python3\x00-m\x00gunicorn\x00app:app\x00",
+		Cgroup:  "0::/\n",
+	})
+
+	result, err := DetectByPID(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Language != inspectors.LanguagePython {
+		t.Errorf("expected Python from the wrapped process, got %s", result.Language)
+	}
+}
+
+func TestDetectByPIDMissingProcess(t *testing.T) {
+	proctest.New(t)
+
+	if _, err := DetectByPID(99); err == nil {
+		t.Error("expected an error for a process that doesn't exist")
+	}
+}
@@ -1,7 +1,7 @@
 package detector
 
 import (
-	"bufio"
+	"cmp"
 	"context"
 	"fmt"
 	"log/slog"
@@ -14,10 +14,10 @@ import (
 	"github.com/kloudmate/polylang-detector/detector/process"
 	runtimedetector "github.com/odigos-io/runtime-detector"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
@@ -25,25 +25,57 @@ import (
 
 // EBPFDetector uses the pattern: watch pods, then inspect with eBPF
 type EBPFDetector struct {
-	Clientset        *kubernetes.Clientset
+	Clientset        kubernetes.Interface
 	LanguageDetector *inspectors.LanguageDetector
 	Cache            *LanguageCache
 	Logger           *zap.Logger
 	processEvents    chan runtimedetector.ProcessEvent
 	runtimeDetector  *runtimedetector.Detector
 	processedPods    sync.Map
+	processes        *processTracker
 	queue            chan ContainerInfo
 	informerFactory  informers.SharedInformerFactory
+	podIndexer       cache.Indexer
+	Options          DetectionOptions
+	scanPool         *PodScanPool
 	stopCh           chan struct{}
+	cancel           context.CancelFunc // stops everything Start launched
+	running          sync.WaitGroup     // goroutines launched by Start
+	stopOnce         sync.Once
+	pendingMu        sync.Mutex
+	pendingPods      map[string]*pendingPod // namespace/name -> debounced detection
+	podDebounce      time.Duration          // defaults to podEventDebounce
+
+	// ShouldMonitorNamespace filters which namespaces are detected; nil monitors all of them
+	ShouldMonitorNamespace func(namespace string) bool
+	// ShouldEnqueue decides which results are sent to the config updater; nil uses
+	// Options.ShouldEnqueue
+	ShouldEnqueue func(info ContainerInfo, logger *zap.Logger) bool
+	// SendPodResult, if set, receives each scanned pod's result
+	SendPodResult func(result PodDetectionResult)
+}
+
+// slogLevel maps a zap level to the closest slog level
+func slogLevel(level zapcore.Level) slog.Level {
+	switch {
+	case level <= zapcore.DebugLevel:
+		return slog.LevelDebug
+	case level == zapcore.InfoLevel:
+		return slog.LevelInfo
+	case level == zapcore.WarnLevel:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
 }
 
 // NewEBPFDetector creates a new eBPF-based detector
-func NewEBPFDetector(clientset *kubernetes.Clientset, cache *LanguageCache, logger *zap.Logger, queue chan ContainerInfo) (*EBPFDetector, error) {
+func NewEBPFDetector(clientset *kubernetes.Clientset, languageCache *LanguageCache, logger *zap.Logger, queue chan ContainerInfo) (*EBPFDetector, error) {
 	processEvents := make(chan runtimedetector.ProcessEvent, 1000)
 
-	// Convert zap.Logger to slog.Logger
+	// Convert zap.Logger to slog.Logger, keeping the zap logger's level
 	slogLogger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
+		Level: slogLevel(logger.Level()),
 	}))
 
 	// Create runtime detector - we'll use it to inspect processes
@@ -59,29 +91,48 @@ func NewEBPFDetector(clientset *kubernetes.Clientset, cache *LanguageCache, logg
 	// Create informer factory for watching Kubernetes resources
 	informerFactory := informers.NewSharedInformerFactory(clientset, 30*time.Second)
 
+	// Index pods by container ID so eBPF process events can be mapped back to their pod
+	podInformer := informerFactory.Core().V1().Pods().Informer()
+	if err := podInformer.AddIndexers(cache.Indexers{containerIDIndex: podContainerIDIndexFunc}); err != nil {
+		return nil, fmt.Errorf("failed to add pod container ID indexer: %w", err)
+	}
+
 	return &EBPFDetector{
 		Clientset:        clientset,
 		LanguageDetector: inspectors.NewLanguageDetector(),
-		Cache:            cache,
+		Cache:            languageCache,
 		Logger:           logger,
 		processEvents:    processEvents,
 		runtimeDetector:  runtimeDetector,
+		processes:        newProcessTracker(defaultProcessTrackerSize),
 		queue:            queue,
 		informerFactory:  informerFactory,
+		podIndexer:       podInformer.GetIndexer(),
 		stopCh:           make(chan struct{}),
 	}, nil
 }
 
-// Start begins the detection: watch pods, inspect each one
+// Start begins the detection: watch pods, inspect each one. Everything it launches runs
+// until ctx is done or Stop is called.
 func (ed *EBPFDetector) Start(ctx context.Context) error {
 	ed.Logger.Info("Starting eBPF detector")
+	ctx, ed.cancel = context.WithCancel(ctx)
+
+	// Pod detection is bounded by the scan worker pool, shared by informer events and the periodic scan
+	ed.scanPool = NewPodScanPool(ed.Options.ScanWorkers)
 
-	// Setup informers for lifecycle management
-	ed.setupInformers()
+	// Setup informers for pod-driven detection and lifecycle management
+	ed.setupInformers(ctx)
 
 	// Start informers
 	ed.informerFactory.Start(ed.stopCh)
 
+	// Handle context cancellation
+	ed.goRunning(func() {
+		<-ctx.Done()
+		close(ed.stopCh)
+	})
+
 	// Wait for cache sync
 	ed.Logger.Info("Waiting for informer caches to sync")
 	if !cache.WaitForCacheSync(ed.stopCh,
@@ -94,141 +145,417 @@ func (ed *EBPFDetector) Start(ctx context.Context) error {
 	}
 	ed.Logger.Info("Informer caches synced successfully")
 
-	// Start the runtime detector
-	go func() {
-		if err := ed.runtimeDetector.Run(ctx); err != nil {
-			ed.Logger.Error("Runtime detector stopped", zap.Error(err))
-		}
-	}()
+	// Start the runtime detector; it closes processEvents when it stops
+	if ed.runtimeDetector != nil {
+		ed.goRunning(func() {
+			if err := ed.runtimeDetector.Run(ctx); err != nil {
+				ed.Logger.Error("Runtime detector stopped", zap.Error(err))
+			}
+		})
+	}
 
 	// Process eBPF events in background
-	go ed.consumeProcessEvents(ctx)
+	ed.goRunning(func() { ed.consumeProcessEvents(ctx) })
 
-	// Main loop: periodically scan all pods
-	go ed.scanPodsLoop(ctx)
+	// Periodically rescan all pods as a safety net for missed informer events
+	ed.goRunning(func() { ed.scanPodsLoop(ctx) })
 
 	// Start reconciliation loop to sync cache with cluster state
-	go ed.reconciliationLoop(ctx)
+	ed.goRunning(func() { ed.reconciliationLoop(ctx) })
 
-	// Handle context cancellation
+	return nil
+}
+
+// goRunning runs fn in a goroutine that Stop waits for
+func (ed *EBPFDetector) goRunning(fn func()) {
+	ed.running.Add(1)
 	go func() {
-		<-ctx.Done()
-		close(ed.stopCh)
+		defer ed.running.Done()
+		fn()
 	}()
+}
 
-	return nil
+// Stop shuts the detector down and waits until nothing it started is left running: the
+// runtime detector, the event consumer, the rescan and reconcile loops, the informers, and
+// pod detections in flight. Pending debounced detections are dropped. Stop may be called
+// more than once, and after the context passed to Start is done.
+func (ed *EBPFDetector) Stop() {
+	ed.stopOnce.Do(func() {
+		if ed.cancel != nil {
+			ed.cancel()
+		}
+		ed.running.Wait()
+		if ed.informerFactory != nil {
+			ed.informerFactory.Shutdown()
+		}
+
+		ed.pendingMu.Lock()
+		for key, pending := range ed.pendingPods {
+			pending.timer.Stop()
+			delete(ed.pendingPods, key)
+		}
+		ed.pendingMu.Unlock()
+
+		if ed.scanPool != nil {
+			ed.scanPool.Wait()
+		}
+		ed.Logger.Info("eBPF detector stopped")
+	})
+}
+
+// enqueue sends a detection to the queue, giving up if the detector stops while the queue is full
+func (ed *EBPFDetector) enqueue(info ContainerInfo) {
+	select {
+	case ed.queue <- info:
+	case <-ed.stopCh:
+	}
 }
 
 // consumeProcessEvents processes events from eBPF ( runtime detector provides process discovery)
 func (ed *EBPFDetector) consumeProcessEvents(ctx context.Context) {
 	ed.Logger.Info("Starting to consume runtime detector process events")
 
-	// processMap tracks PIDs to their detected languages to avoid duplicate detections
-	processMap := make(map[int]string)
-
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case event := <-ed.processEvents:
-			// runtime detector gives us process exec events
-			// We use these to know when new processes start, then detect their language
-			if event.EventType == runtimedetector.ProcessExecEvent && event.ExecDetails != nil {
-				// Skip if we've already processed this PID
-				if _, exists := processMap[event.PID]; exists {
-					continue
-				}
-
-				// Log the process we found
-				ed.Logger.Info("detected new process",
-					zap.Int("pid", event.PID),
-					zap.String("exe", event.ExecDetails.ExePath),
-					zap.String("cmdline_preview", truncateString(event.ExecDetails.CmdLine, 100)),
-				)
-
-				// Now detect the language using our language detector
-				procCtx := &process.ProcessContext{
-					PID:        event.PID,
-					Executable: event.ExecDetails.ExePath,
-					Cmdline:    event.ExecDetails.CmdLine,
-					Environ:    event.ExecDetails.Environments,
-				}
-
-				result, err := ed.LanguageDetector.Detect(procCtx)
-				if err == nil && result != nil && result.Language != inspectors.LanguageUnknown {
-					processMap[event.PID] = string(result.Language)
-
-					ed.Logger.Info("Detected language from process event",
-						zap.Int("pid", event.PID),
-						zap.String("language", string(result.Language)),
-						zap.String("framework", result.Framework),
-						zap.String("confidence", result.Confidence),
-					)
-
-					// TODO: Map this PID back to a pod/container and update the cache
-					// This requires maintaining a PID->Pod mapping
-				}
+		case event, ok := <-ed.processEvents:
+			if !ok {
+				return
 			}
+			ed.handleProcessEvent(event)
 		}
 	}
 }
 
-// scanPodsLoop periodically scans all running pods
-func (ed *EBPFDetector) scanPodsLoop(ctx context.Context) {
-	ed.Logger.Info("Starting pod scanning loop")
+// handleProcessEvent detects the language for exec events and forgets PIDs on exit
+func (ed *EBPFDetector) handleProcessEvent(event runtimedetector.ProcessEvent) {
+	// Exited processes no longer need tracking - this keeps the tracker from growing as PIDs churn
+	if event.EventType == runtimedetector.ProcessExitEvent {
+		ed.processes.Remove(event.PID)
+		return
+	}
 
-	ticker := time.NewTicker(30 * time.Second)
-	defer ticker.Stop()
+	// runtime detector gives us process exec events
+	// We use these to know when new processes start, then detect their language
+	if event.EventType != runtimedetector.ProcessExecEvent || event.ExecDetails == nil {
+		return
+	}
 
-	// Initial scan
-	ed.scanAllRunningPods(ctx)
+	// Skip if we've already processed this PID
+	if ed.processes.Seen(event.PID) {
+		return
+	}
 
-	for {
-		select {
-		case <-ctx.Done():
+	// Log the process we found
+	ed.Logger.Info("detected new process",
+		zap.Int("pid", event.PID),
+		zap.String("exe", event.ExecDetails.ExePath),
+		zap.String("cmdline_preview", truncateString(event.ExecDetails.CmdLine, 100)),
+	)
+
+	// Now detect the language using our language detector
+	procCtx := &process.ProcessContext{
+		PID:        event.PID,
+		Executable: event.ExecDetails.ExePath,
+		Cmdline:    event.ExecDetails.CmdLine,
+		Environ:    event.ExecDetails.Environments,
+	}
+
+	result, err := ed.LanguageDetector.Detect(procCtx)
+	if err == nil && result != nil && result.Language != inspectors.LanguageUnknown {
+		ed.processes.Add(event.PID, string(result.Language))
+
+		ed.Logger.Info("Detected language from process event",
+			zap.Int("pid", event.PID),
+			zap.String("language", string(result.Language)),
+			zap.String("framework", result.Framework),
+			zap.String("version", result.Version),
+			zap.Stringer("confidence", result.Confidence),
+		)
+
+		// A process manager's daemon (e.g. PM2's) runs no application code; the exec events of the
+		// processes it starts report the application instead
+		if result.ProcessRole == "manager" {
 			return
-		case <-ticker.C:
-			ed.scanAllRunningPods(ctx)
 		}
+		ed.enqueueProcessResult(event.PID, result)
+	}
+}
+
+// enqueueProcessResult maps a detected process back to its pod/container via the
+// process cgroup and the informer's container ID index, then caches and enqueues it
+func (ed *EBPFDetector) enqueueProcessResult(pid int, result *inspectors.DetectionResult) {
+	procCtx, err := process.GetProcessContext(pid)
+	if err != nil || procCtx.ContainerID == "" {
+		ed.Logger.Debug("Process is not running in a known container",
+			zap.Int("pid", pid),
+			zap.Error(err),
+		)
+		return
+	}
+
+	pod, container, class := ed.findContainerByID(procCtx.ContainerID)
+	if pod == nil || container == nil {
+		ed.Logger.Debug("No pod found for container",
+			zap.Int("pid", pid),
+			zap.String("container_id", procCtx.ContainerID),
+		)
+		return
+	}
+
+	if !ed.monitorsNamespace(pod.Namespace) || ed.Options.SkipsContainer(container.Name) {
+		return
+	}
+
+	containerEnvVars := make(map[string]string)
+	for _, env := range container.Env {
+		if env.Value != "" {
+			containerEnvVars[env.Name] = env.Value
+		}
+	}
+
+	// Respect an existing cache entry - scan-based detection of the same image takes precedence
+	imageRef := containerImageRef(pod, container)
+	if _, found := ed.Cache.Get(imageRef, containerEnvVars); found {
+		return
+	}
+
+	workloadName, workloadKind := getWorkloadInfo(ed.Clientset, pod)
+	info := ContainerInfo{
+		PodName:             pod.Name,
+		Namespace:           pod.Namespace,
+		ContainerName:       container.Name,
+		ContainerClass:      class,
+		Runtime:             containerRuntime(pod, container.Name),
+		Image:               container.Image,
+		Kind:                workloadKind,
+		DeploymentName:      workloadName,
+		EnvVars:             containerEnvVars,
+		DetectedAt:          time.Now(),
+		Language:            string(result.Language),
+		Framework:           result.Framework,
+		Version:             result.Version,
+		FrameworkVersion:    result.FrameworkVersion,
+		AppServer:           result.AppServer,
+		RuntimeMode:         result.RuntimeMode,
+		ProcessRole:         result.ProcessRole,
+		WorkerCount:         result.WorkerCount,
+		WebappContexts:      result.WebappContexts,
+		GuestLanguage:       string(result.GuestLanguage),
+		AgentDetected:       result.AgentDetected,
+		Agents:              result.Agents,
+		AlreadyInstrumented: result.AlreadyInstrumented,
+		Evidence:            []string{fmt.Sprintf("Detected via eBPF process exec event with %s confidence", result.Confidence)},
+	}
+	info.setConfidence(result.Confidence)
+	info.setBinaryInfo(procCtx)
+	ed.Options.WorkloadIdentity.Apply(&info, pod)
+
+	ed.Cache.Set(imageRef, containerEnvVars, info)
+	info = ed.Cache.UpdateWorkloadContainer(info.Namespace, workloadName, workloadKind, info)
+
+	if ed.shouldEnqueue(info, ed.Logger) {
+		ed.enqueue(info)
 	}
 }
 
-// scanAllRunningPods scans all running pods and detects languages
+// monitorsNamespace reports whether pods in namespace should be detected
+func (ed *EBPFDetector) monitorsNamespace(namespace string) bool {
+	return ed.ShouldMonitorNamespace == nil || ed.ShouldMonitorNamespace(namespace)
+}
+
+// shouldEnqueue reports whether a result is sent to the config updater
+func (ed *EBPFDetector) shouldEnqueue(info ContainerInfo, logger *zap.Logger) bool {
+	if ed.ShouldEnqueue != nil {
+		return ed.ShouldEnqueue(info, logger)
+	}
+	return ed.Options.ShouldEnqueue(info, logger)
+}
+
+// findContainerByID looks up the pod, container spec, and container class owning a (short) container ID
+func (ed *EBPFDetector) findContainerByID(containerID string) (*corev1.Pod, *corev1.Container, string) {
+	if ed.podIndexer == nil {
+		return nil, nil, ""
+	}
+
+	objs, err := ed.podIndexer.ByIndex(containerIDIndex, shortContainerID(containerID))
+	if err != nil || len(objs) == 0 {
+		return nil, nil, ""
+	}
+
+	pod := objs[0].(*corev1.Pod)
+	for _, status := range allContainerStatuses(pod) {
+		if shortContainerID(status.ContainerID) != shortContainerID(containerID) {
+			continue
+		}
+		for _, pc := range podContainers(pod, true) {
+			if pc.Container.Name == status.Name {
+				container := pc.Container
+				return pod, &container, pc.Class
+			}
+		}
+	}
+
+	return nil, nil, ""
+}
+
+// containerIDIndex is the pod indexer name keyed on short container IDs
+const containerIDIndex = "containerID"
+
+// podContainerIDIndexFunc indexes a pod by the short IDs of its running containers
+func podContainerIDIndexFunc(obj interface{}) ([]string, error) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return nil, nil
+	}
+
+	var ids []string
+	for _, status := range allContainerStatuses(pod) {
+		if id := shortContainerID(status.ContainerID); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// shortContainerID strips the runtime prefix (containerd://, docker://, cri-o://)
+// and truncates to the 12-character form used in cgroup paths
+func shortContainerID(containerID string) string {
+	if idx := strings.Index(containerID, "://"); idx >= 0 {
+		containerID = containerID[idx+3:]
+	}
+	if len(containerID) > 12 {
+		return containerID[:12]
+	}
+	return containerID
+}
+
+// podRescanInterval is how often all running pods are rescanned. Pods are normally detected as
+// soon as the informer reports them running; the rescan only catches anything that was missed.
+const podRescanInterval = 5 * time.Minute
+
+// podEventDebounce delays informer-triggered detection so a burst of updates for a pod
+// (e.g. status changes while its containers start) results in a single detection
+const podEventDebounce = 2 * time.Second
+
+// pendingPod is a pod waiting for its debounced detection
+type pendingPod struct {
+	pod   *corev1.Pod
+	timer *time.Timer
+}
+
+// schedulePodDetection detects a running pod's languages once its informer events settle.
+// Pods not ready for detection, already detected, or in unmonitored namespaces are ignored.
+func (ed *EBPFDetector) schedulePodDetection(ctx context.Context, pod *corev1.Pod) {
+	if !ed.Options.ShouldDetectPod(pod) || !ed.monitorsNamespace(pod.Namespace) {
+		return
+	}
+	key := pod.Namespace + "/" + pod.Name
+	if _, exists := ed.processedPods.Load(key); exists {
+		return
+	}
+
+	debounce := ed.podDebounce
+	if debounce <= 0 {
+		debounce = podEventDebounce
+	}
+
+	ed.pendingMu.Lock()
+	defer ed.pendingMu.Unlock()
+
+	// A newer event restarts the wait and replaces the pod with its latest state
+	if pending, exists := ed.pendingPods[key]; exists {
+		pending.pod = pod
+		pending.timer.Reset(debounce)
+		return
+	}
+
+	if ed.pendingPods == nil {
+		ed.pendingPods = make(map[string]*pendingPod)
+	}
+	pending := &pendingPod{pod: pod}
+	pending.timer = time.AfterFunc(debounce, func() {
+		ed.pendingMu.Lock()
+		pod := pending.pod
+		delete(ed.pendingPods, key)
+		ed.pendingMu.Unlock()
+
+		if _, exists := ed.processedPods.Load(key); exists || ctx.Err() != nil {
+			return
+		}
+		ed.scanPool.Go(ctx, func() { ed.detectPodLanguages(ctx, pod) })
+	})
+	ed.pendingPods[key] = pending
+}
+
+// scanPodsLoop periodically scans all running pods, starting with an initial scan
+func (ed *EBPFDetector) scanPodsLoop(ctx context.Context) {
+	ed.Logger.Info("Starting pod scanning loop")
+
+	RunPeriodic(ctx, "pod rescan", cmp.Or(ed.Options.RescanInterval, podRescanInterval), ed.scanAllRunningPods, ed.Logger)
+}
+
+// scanAllRunningPods scans all running pods and detects languages, returning once every
+// detection it started has finished
 func (ed *EBPFDetector) scanAllRunningPods(ctx context.Context) {
+	// Every log line of this cycle carries its scan ID, including those of concurrent detections
+	ctx, _ = WithNewScanID(ctx)
+	logger := ScanLogger(ctx, ed.Logger)
+
 	pods, err := ed.Clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{
 		FieldSelector: "status.phase=Running",
 	})
 	if err != nil {
-		ed.Logger.Error("Failed to list pods", zap.Error(err))
+		logger.Error("Failed to list pods", zap.Error(err))
 		return
 	}
 
-	ed.Logger.Info("Scanning pods", zap.Int("count", len(pods.Items)))
+	logger.Info("Scanning pods", zap.Int("count", len(pods.Items)))
 
-	for _, pod := range pods.Items {
+	// Interleave namespaces so large namespaces don't starve smaller ones within a cycle
+	var detections sync.WaitGroup
+	for _, pod := range InterleavePodsByNamespace(pods.Items) {
 		// Skip if already processed
 		key := pod.Namespace + "/" + pod.Name
 		if _, exists := ed.processedPods.Load(key); exists {
 			continue
 		}
 
-		// Skip ignored namespaces (should be checked by caller)
-		// For now, process all pods
+		// Skip namespaces excluded by KM_K8S_MONITORED_NAMESPACES / KM_IGNORED_NS, and pods
+		// that are terminating or not ready (the list is only filtered to the Running phase)
+		if !ed.monitorsNamespace(pod.Namespace) || !ed.Options.ShouldDetectPod(&pod) {
+			continue
+		}
 
-		go ed.detectPodLanguages(ctx, &pod)
+		detections.Add(1)
+		if !ed.scanPool.Go(ctx, func() {
+			defer detections.Done()
+			ed.detectPodLanguages(ctx, &pod)
+		}) {
+			detections.Done()
+			break
+		}
 	}
+	detections.Wait()
 }
 
 // detectPodLanguages detects languages for all containers in a pod
 func (ed *EBPFDetector) detectPodLanguages(ctx context.Context, pod *corev1.Pod) {
 	key := pod.Namespace + "/" + pod.Name
+	logger := ScanLogger(ctx, ed.Logger)
 
-	ed.Logger.Info("Detecting languages for pod",
+	logger.Info("Detecting languages for pod",
 		zap.String("namespace", pod.Namespace),
 		zap.String("pod", pod.Name),
 	)
 
-	for _, container := range pod.Spec.Containers {
+	// Inspect containers in parallel; cache and queue writes are safe for concurrent use
+	containers := ed.Options.containersToScan(pod)
+	detected := make([]*ContainerInfo, len(containers))
+	forEachContainer(containers, ed.Options.ContainerConcurrency, func(i int, pc podContainer) {
+		container := pc.Container
+
 		// Check cache first
 		containerEnvVars := make(map[string]string)
 		for _, env := range container.Env {
@@ -237,8 +564,9 @@ func (ed *EBPFDetector) detectPodLanguages(ctx context.Context, pod *corev1.Pod)
 			}
 		}
 
-		if cachedInfo, found := ed.Cache.Get(container.Image, containerEnvVars); found {
-			ed.Logger.Debug("Cache hit",
+		imageRef := containerImageRef(pod, &container)
+		if cachedInfo, found := ed.Cache.Get(imageRef, containerEnvVars); found {
+			logger.Debug("Cache hit",
 				zap.String("image", container.Image),
 				zap.String("language", cachedInfo.Language),
 			)
@@ -248,53 +576,71 @@ func (ed *EBPFDetector) detectPodLanguages(ctx context.Context, pod *corev1.Pod)
 			info.PodName = pod.Name
 			info.Namespace = pod.Namespace
 			info.ContainerName = container.Name
+			info.Image = container.Image
+			info.ContainerClass = pc.Class
+			info.Runtime = containerRuntime(pod, container.Name)
 			info.DetectedAt = time.Now()
 
 			// Get workload name and kind (uses Deployment when available)
 			workloadName, workloadKind := getWorkloadInfo(ed.Clientset, pod)
 			info.DeploymentName = workloadName
 			info.Kind = workloadKind
+			ed.Options.WorkloadIdentity.Apply(&info, pod)
 
 			// Update workload cache with correct workload info
-			ed.Cache.UpdateWorkloadContainer(
+			info = ed.Cache.UpdateWorkloadContainer(
 				info.Namespace,
 				workloadName,
 				workloadKind,
 				info,
 			)
 
-			if _, ok := OtelSupportedLanguages[info.Language]; ok {
-				ed.queue <- info
+			detected[i] = &info
+			if ed.shouldEnqueue(info, logger) {
+				ed.enqueue(info)
 			}
-			continue
+			return
 		}
 
 		// Detect using proc inspection (fallback to traditional method)
 		containerInfo := ed.detectContainerLanguage(ctx, pod, &container)
 		if containerInfo != nil && containerInfo.Language != "Unknown" {
-			ed.Logger.Info("Detected language",
+			containerInfo.ContainerClass = pc.Class
+			logger.Info("Detected language",
 				zap.String("namespace", pod.Namespace),
 				zap.String("pod", pod.Name),
 				zap.String("container", container.Name),
+				zap.String("runtime", containerInfo.Runtime),
 				zap.String("language", containerInfo.Language),
 			)
 
 			// Cache the result (image-based cache)
-			ed.Cache.Set(container.Image, containerEnvVars, *containerInfo)
+			ed.Cache.Set(imageRef, containerEnvVars, *containerInfo)
 
 			// Update workload cache
-			ed.Cache.UpdateWorkloadContainer(
+			*containerInfo = ed.Cache.UpdateWorkloadContainer(
 				containerInfo.Namespace,
 				containerInfo.DeploymentName,
 				containerInfo.Kind,
 				*containerInfo,
 			)
 
+			detected[i] = containerInfo
 			// Send to queue
-			if _, ok := OtelSupportedLanguages[containerInfo.Language]; ok {
-				ed.queue <- *containerInfo
+			if ed.shouldEnqueue(*containerInfo, logger) {
+				ed.enqueue(*containerInfo)
+			}
+		}
+	})
+
+	if ed.SendPodResult != nil {
+		var infos []ContainerInfo
+		for _, info := range detected {
+			if info != nil {
+				infos = append(infos, *info)
 			}
 		}
+		ed.SendPodResult(NewPodDetectionResult(pod.Namespace, pod.Name, infos, ed.Options))
 	}
 
 	// Mark as processed
@@ -303,10 +649,12 @@ func (ed *EBPFDetector) detectPodLanguages(ctx context.Context, pod *corev1.Pod)
 
 // detectContainerLanguage detects language for a specific container in a pod
 func (ed *EBPFDetector) detectContainerLanguage(ctx context.Context, pod *corev1.Pod, container *corev1.Container) *ContainerInfo {
+	logger := ScanLogger(ctx, ed.Logger)
 	info := &ContainerInfo{
 		PodName:       pod.Name,
 		Namespace:     pod.Namespace,
 		ContainerName: container.Name,
+		Runtime:       containerRuntime(pod, container.Name),
 		Image:         container.Image,
 		EnvVars:       make(map[string]string),
 		DetectedAt:    time.Now(),
@@ -323,26 +671,29 @@ func (ed *EBPFDetector) detectContainerLanguage(ctx context.Context, pod *corev1
 	workloadName, workloadKind := getWorkloadInfo(ed.Clientset, pod)
 	info.DeploymentName = workloadName
 	info.Kind = workloadKind
+	ed.Options.WorkloadIdentity.Apply(info, pod)
 
-	// Find processes belonging to this specific container
-	// Expected mount root: /kubepods/<pod-uid>/containers/<container-name>/
-	pids := findProcessesInContainer(pod.UID, container.Name)
+	// Find the processes of this specific container by matching its ID against each
+	// process's cgroup, so a pod's other containers keep their own processes
+	containerID := containerStatusID(pod, container.Name)
+	pids, _ := process.GetContainerPIDs(containerID)
 
 	if len(pids) == 0 {
-		ed.Logger.Info("No processes found for container",
+		logger.Info("No processes found for container",
 			zap.String("namespace", pod.Namespace),
 			zap.String("pod", pod.Name),
 			zap.String("container", container.Name),
-			zap.String("pod_uid", string(pod.UID)),
+			zap.String("runtime", info.Runtime),
+			zap.String("container_id", containerID),
 			zap.String("image", container.Image),
 		)
 		info.Language = "Unknown"
-		info.Confidence = "low"
+		info.setConfidence(inspectors.ConfidenceLow)
 		return info
 	}
 
-	// Detect language from the first process that gives us a result
-	ed.Logger.Debug("Found processes for container",
+	// Detect language from the first process that gives us a result, longest-running first
+	logger.Debug("Found processes for container",
 		zap.String("namespace", pod.Namespace),
 		zap.String("pod", pod.Name),
 		zap.String("container", container.Name),
@@ -350,10 +701,11 @@ func (ed *EBPFDetector) detectContainerLanguage(ctx context.Context, pod *corev1
 		zap.Ints("pids", pids),
 	)
 
-	for _, pid := range pids {
+	var manager *processDetection
+	for _, pid := range process.OrderByStartTime(pids) {
 		procCtx, err := process.GetProcessContext(pid)
 		if err != nil {
-			ed.Logger.Info("Failed to get process context",
+			logger.Info("Failed to get process context",
 				zap.String("namespace", pod.Namespace),
 				zap.String("pod", pod.Name),
 				zap.String("container", container.Name),
@@ -363,7 +715,10 @@ func (ed *EBPFDetector) detectContainerLanguage(ctx context.Context, pod *corev1
 			continue
 		}
 
-		ed.Logger.Info("Got process context, attempting detection",
+		// Entrypoint scripts run the application as a child of a shell; detect on that child instead
+		procCtx = process.FollowShellWrapper(procCtx)
+
+		logger.Info("Got process context, attempting detection",
 			zap.String("namespace", pod.Namespace),
 			zap.String("pod", pod.Name),
 			zap.String("container", container.Name),
@@ -374,7 +729,7 @@ func (ed *EBPFDetector) detectContainerLanguage(ctx context.Context, pod *corev1
 
 		result, err := ed.LanguageDetector.Detect(procCtx)
 		if err != nil || result == nil || result.Language == inspectors.LanguageUnknown {
-			ed.Logger.Info("Language detection failed or unknown",
+			logger.Info("Language detection failed or unknown",
 				zap.String("namespace", pod.Namespace),
 				zap.String("pod", pod.Name),
 				zap.String("container", container.Name),
@@ -386,116 +741,57 @@ func (ed *EBPFDetector) detectContainerLanguage(ctx context.Context, pod *corev1
 			continue
 		}
 
+		// A process manager's daemon runs no application code; fall back to it only if none of
+		// the processes it manages is detected
+		if result.ProcessRole == "manager" {
+			if manager == nil {
+				manager = &processDetection{pid: pid, procCtx: procCtx, result: result}
+			}
+			continue
+		}
+
 		// Found a language!
-		info.Language = string(result.Language)
-		info.Framework = result.Framework
-		info.Confidence = result.Confidence
-		info.Evidence = []string{fmt.Sprintf("Detected via cgroup-based process discovery with %s confidence", result.Confidence)}
+		setProcessDetection(info, processDetection{pid: pid, procCtx: procCtx, result: result})
+		return info
+	}
+
+	if manager != nil {
+		setProcessDetection(info, *manager)
 		return info
 	}
 
 	info.Language = "Unknown"
-	info.Confidence = "low"
+	info.setConfidence(inspectors.ConfidenceLow)
 	return info
 }
 
-// findProcessesInContainer finds all PIDs for processes in a specific container
-// Uses cgroup-based detection that works across all Kubernetes platforms (GKE, EKS, AKS, on-prem)
-func findProcessesInContainer(podUID types.UID, containerName string) []int {
-	// Get all processes
-	allPids, err := process.FindAllProcesses()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "[DEBUG] Failed to find processes: %v\n", err)
-		return nil
-	}
-
-	fmt.Fprintf(os.Stderr, "[DEBUG] Searching for pod %s, container %s in %d processes\n",
-		podUID, containerName, len(allPids))
-
-	var matchingPids []int
-	procDir := process.GetProcDir()
-	checked := 0
-	sampleLimit := 5
-	samplesShown := 0
-
-	for _, pid := range allPids {
-		// Check if this process belongs to the container by examining cgroup
-		cgroupPath := fmt.Sprintf("%s/%d/cgroup", procDir, pid)
-
-		// Show first few samples for debugging
-		if samplesShown < sampleLimit {
-			if data, err := os.ReadFile(cgroupPath); err == nil {
-				firstLine := strings.Split(string(data), "\n")[0]
-				if len(firstLine) > 100 {
-					firstLine = firstLine[:100] + "..."
-				}
-				fmt.Fprintf(os.Stderr, "[DEBUG] Sample PID %d cgroup: %s\n", pid, firstLine)
-				samplesShown++
-			}
-		}
-
-		if isPodContainerProcess(cgroupPath, podUID, containerName) {
-			matchingPids = append(matchingPids, pid)
-		}
-		checked++
-	}
-
-	fmt.Fprintf(os.Stderr, "[DEBUG] Checked %d processes, found %d matches for pod %s\n",
-		checked, len(matchingPids), podUID)
-
-	return matchingPids
+// processDetection is the detection result of one process of a container
+type processDetection struct {
+	pid     int
+	procCtx *process.ProcessContext
+	result  *inspectors.DetectionResult
 }
 
-// isPodContainerProcess checks if a process belongs to a specific container
-// by examining its cgroup information - works across all K8s platforms
-func isPodContainerProcess(cgroupPath string, podUID types.UID, containerName string) bool {
-	file, err := os.Open(cgroupPath)
-	if err != nil {
-		// Process might have terminated or we don't have permission
-		return false
-	}
-	defer file.Close()
-
-	// Prepare both UID formats for maximum compatibility:
-	// - With dashes: 8eb9b7bf-0432-40ad-ba5e-34a9fa74501a (cgroup v1, EKS, AKS, older K8s)
-	// - With underscores: 8eb9b7bf_0432_40ad_ba5e_34a9fa74501a (cgroup v2, GKE, modern K8s)
-	podUIDDashes := string(podUID)
-	podUIDUnderscores := strings.ReplaceAll(podUIDDashes, "-", "_")
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		// Platform-specific cgroup path patterns:
-		// GKE (cgroup v2):         0::/kubepods-besteffort-pod8eb9b7bf_0432_40ad_ba5e_34a9fa74501a.slice/cri-containerd-<container-id>.scope
-		// EKS (cgroup v1):         11:cpuset:/kubepods/besteffort/pod8eb9b7bf-0432-40ad-ba5e-34a9fa74501a/<container-id>
-		// AKS (cgroup v1/v2):      Similar patterns depending on K8s version
-		// On-prem Docker:          10:memory:/kubepods/burstable/pod8eb9b7bf-0432-40ad-ba5e-34a9fa74501a/<docker-id>
-		// On-prem containerd:      Similar to cloud providers
-		// On-prem CRI-O:           Similar patterns with crio prefix
-
-		// Check for pod UID in either format
-		hasPodUID := strings.Contains(line, podUIDDashes) || strings.Contains(line, podUIDUnderscores)
-
-		if hasPodUID {
-			// Additional verification: For multi-container pods, try to match container
-			// However, container name matching is unreliable across platforms, so we use a best-effort approach
-
-			// If this is a pause/infrastructure container, skip it
-			// Pause containers often have "pause" or "POD" in their cgroup path
-			lowerLine := strings.ToLower(line)
-			if strings.Contains(lowerLine, "/pause") || strings.Contains(lowerLine, "/pod.slice") {
-				continue
-			}
-
-			// If we have a container name, try to match it (best effort)
-			// This works on some platforms but not all - we don't want to miss detections
-			// so we'll accept any non-pause container from the correct pod
-			return true
-		}
-	}
-
-	return false
+// setProcessDetection records a process's detection result on a container's info
+func setProcessDetection(info *ContainerInfo, detection processDetection) {
+	result := detection.result
+	info.Language = string(result.Language)
+	info.Framework = result.Framework
+	info.Version = result.Version
+	info.FrameworkVersion = result.FrameworkVersion
+	info.AppServer = result.AppServer
+	info.RuntimeMode = result.RuntimeMode
+	info.ProcessRole = result.ProcessRole
+	info.WorkerCount = result.WorkerCount
+	info.WebappContexts = result.WebappContexts
+	info.GuestLanguage = string(result.GuestLanguage)
+	info.AgentDetected = result.AgentDetected
+	info.Agents = result.Agents
+	info.AlreadyInstrumented = result.AlreadyInstrumented
+	info.setConfidence(result.Confidence)
+	info.setBinaryInfo(detection.procCtx)
+	info.Ports = process.ListeningPorts(detection.pid)
+	info.Evidence = []string{fmt.Sprintf("Detected via cgroup-based process discovery with %s confidence", result.Confidence)}
 }
 
 // truncateString truncates a string to the specified length
@@ -507,10 +803,20 @@ func truncateString(s string, maxLen int) string {
 }
 
 // setupInformers configures informers for watching Kubernetes resources
-func (ed *EBPFDetector) setupInformers() {
-	// Pod informer - watch for pod deletion
+func (ed *EBPFDetector) setupInformers(ctx context.Context) {
+	// Pod informer - detect pods as they become running, and watch for pod deletion
 	podInformer := ed.informerFactory.Core().V1().Pods().Informer()
 	podInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if pod, ok := obj.(*corev1.Pod); ok {
+				ed.schedulePodDetection(ctx, pod)
+			}
+		},
+		UpdateFunc: func(_, newObj interface{}) {
+			if pod, ok := newObj.(*corev1.Pod); ok {
+				ed.schedulePodDetection(ctx, pod)
+			}
+		},
 		DeleteFunc: func(obj interface{}) {
 			pod := obj.(*corev1.Pod)
 			key := pod.Namespace + "/" + pod.Name
@@ -566,19 +872,15 @@ func (ed *EBPFDetector) setupInformers() {
 
 // reconciliationLoop periodically reconciles cache with actual cluster state
 func (ed *EBPFDetector) reconciliationLoop(ctx context.Context) {
-	ticker := time.NewTicker(5 * time.Minute)
-	defer ticker.Stop()
-
 	ed.Logger.Info("Starting reconciliation loop")
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			ed.reconcileCache(ctx)
-		}
+	reconciles := &periodicCycle{
+		name:     "cache reconciliation",
+		interval: cmp.Or(ed.Options.ReconcileInterval, defaultReconcileInterval),
+		run:      ed.reconcileCache,
+		logger:   ed.Logger,
 	}
+	reconciles.loop(ctx)
 }
 
 // reconcileCache syncs the cache with actual cluster state
@@ -590,25 +892,7 @@ func (ed *EBPFDetector) reconcileCache(ctx context.Context) {
 
 	// Check each cached workload against cluster state
 	for _, workload := range cachedWorkloads {
-		exists := false
-
-		switch workload.WorkloadKind {
-		case "Deployment":
-			_, err := ed.Clientset.AppsV1().Deployments(workload.Namespace).Get(ctx, workload.WorkloadName, metav1.GetOptions{})
-			exists = err == nil
-
-		case "DaemonSet":
-			_, err := ed.Clientset.AppsV1().DaemonSets(workload.Namespace).Get(ctx, workload.WorkloadName, metav1.GetOptions{})
-			exists = err == nil
-
-		case "ReplicaSet":
-			_, err := ed.Clientset.AppsV1().ReplicaSets(workload.Namespace).Get(ctx, workload.WorkloadName, metav1.GetOptions{})
-			exists = err == nil
-
-		case "StatefulSet":
-			_, err := ed.Clientset.AppsV1().StatefulSets(workload.Namespace).Get(ctx, workload.WorkloadName, metav1.GetOptions{})
-			exists = err == nil
-		}
+		exists := workloadExists(ctx, ed.Clientset, workload.Namespace, workload.WorkloadName, workload.WorkloadKind)
 
 		// If workload no longer exists, remove it from cache immediately
 		if !exists {
@@ -0,0 +1,603 @@
+package detector
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/kloudmate/polylang-detector/detector/inspectors"
+	"github.com/kloudmate/polylang-detector/internal/proctest"
+	runtimedetector "github.com/odigos-io/runtime-detector"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+const testContainerID = "3f4e5d6c7b8a9f0e1d2c3b4a5f6e7d8c9b0a1f2e3d4c5b6a7f8e9d0c1b2a3f4e"
+
+// newTestPodIndexer returns a pod indexer with the container ID index, seeded with pods
+func newTestPodIndexer(t *testing.T, pods ...*corev1.Pod) cache.Indexer {
+	t.Helper()
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{containerIDIndex: podContainerIDIndexFunc})
+	for _, pod := range pods {
+		if err := indexer.Add(pod); err != nil {
+			t.Fatalf("failed to index pod: %v", err)
+		}
+	}
+	return indexer
+}
+
+func TestHandleProcessEventEnqueuesMappedContainer(t *testing.T) {
+	procRoot := proctest.New(t)
+	proctest.WriteProcess(t, procRoot, proctest.Process{
+		PID:     4242,
+		Cmdline: "java\x00-jar\x00/app/app.jar\x00",
+		Cgroup:  "0::/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod1234.slice/cri-containerd-" + testContainerID + ".scope\n",
+	})
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "checkout"},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{Name: "app", Image: "checkout:1.0"},
+		}},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "app", ContainerID: "containerd://" + testContainerID},
+		}},
+	}
+	queue := make(chan ContainerInfo, 1)
+	ed := &EBPFDetector{
+		LanguageDetector: inspectors.NewLanguageDetector(),
+		Cache:            NewLanguageCache(0),
+		Logger:           zap.NewNop(),
+		processes:        newProcessTracker(defaultProcessTrackerSize),
+		queue:            queue,
+		podIndexer:       newTestPodIndexer(t, pod),
+	}
+
+	ed.handleProcessEvent(runtimedetector.ProcessEvent{
+		EventType: runtimedetector.ProcessExecEvent,
+		PID:       4242,
+		ExecDetails: &runtimedetector.ProcessExecDetails{
+			ExePath: "/usr/bin/java",
+			CmdLine: "java -jar /app/app.jar",
+		},
+	})
+
+	select {
+	case info := <-queue:
+		if info.PodName != "checkout" || info.ContainerName != "app" || info.Language != "Java" {
+			t.Errorf("unexpected container info: %+v", info)
+		}
+		if info.Kind != "Pod" || info.DeploymentName != "checkout" {
+			t.Errorf("expected standalone pod workload, got %s/%s", info.Kind, info.DeploymentName)
+		}
+		if info.Runtime != "containerd" {
+			t.Errorf("expected the containerd runtime from the container status, got %q", info.Runtime)
+		}
+	default:
+		t.Fatal("expected a result to be enqueued")
+	}
+
+	if _, found := ed.Cache.GetWorkload("shop", "checkout"); !found {
+		t.Error("expected workload cache to be updated")
+	}
+}
+
+func TestEnqueueProcessResultHoldsBackLowConfidence(t *testing.T) {
+	procRoot := proctest.New(t)
+	proctest.WriteProcess(t, procRoot, proctest.Process{
+		PID:     4343,
+		Cmdline: "/usr/local/bin/server\x00",
+		Cgroup:  "0::/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod1234.slice/cri-containerd-" + testContainerID + ".scope\n",
+	})
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "cart"},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{Name: "app", Image: "alpine:3.19"},
+		}},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "app", ContainerID: "containerd://" + testContainerID},
+		}},
+	}
+	queue := make(chan ContainerInfo, 1)
+	ed := &EBPFDetector{
+		Cache:      NewLanguageCache(0),
+		Logger:     zap.NewNop(),
+		Options:    DetectionOptions{MinConfidence: inspectors.ConfidenceMedium},
+		queue:      queue,
+		podIndexer: newTestPodIndexer(t, pod),
+	}
+
+	ed.enqueueProcessResult(4343, &inspectors.DetectionResult{
+		Language:   inspectors.LanguageGo,
+		Confidence: inspectors.ConfidenceLow,
+	})
+
+	select {
+	case info := <-queue:
+		t.Fatalf("expected low-confidence result to be held back, got %+v", info)
+	default:
+	}
+
+	if _, found := ed.Cache.GetWorkload("shop", "cart"); !found {
+		t.Error("expected low-confidence result to still be cached")
+	}
+}
+
+func TestShouldEnqueueAppliesMinConfidence(t *testing.T) {
+	opts := DetectionOptions{MinConfidence: inspectors.ConfidenceMedium}
+	logger := zap.NewNop()
+
+	tests := []struct {
+		language   string
+		confidence inspectors.Confidence
+		want       bool
+	}{
+		{"Go", inspectors.ConfidenceLow, false},
+		{"Go", inspectors.ConfidenceMedium, true},
+		{"Java", inspectors.ConfidenceHigh, true},
+		{"Ruby", inspectors.ConfidenceHigh, false},
+	}
+
+	for _, tt := range tests {
+		info := ContainerInfo{Language: tt.language}
+		info.setConfidence(tt.confidence)
+		if got := opts.ShouldEnqueue(info, logger); got != tt.want {
+			t.Errorf("ShouldEnqueue(%s, %s) = %v, want %v", tt.language, tt.confidence, got, tt.want)
+		}
+	}
+}
+
+func TestHandleProcessEventCarriesVersion(t *testing.T) {
+	procRoot := proctest.New(t)
+	proctest.WriteProcess(t, procRoot, proctest.Process{
+		PID:     4444,
+		Cmdline: "python3\x00/app/main.py\x00",
+		Cgroup:  "0::/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod1234.slice/cri-containerd-" + testContainerID + ".scope\n",
+	})
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "recommender"},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{Name: "app", Image: "python:3.11-slim"},
+		}},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "app", ContainerID: "containerd://" + testContainerID},
+		}},
+	}
+	queue := make(chan ContainerInfo, 1)
+	ed := &EBPFDetector{
+		LanguageDetector: inspectors.NewLanguageDetector(),
+		Cache:            NewLanguageCache(0),
+		Logger:           zap.NewNop(),
+		processes:        newProcessTracker(defaultProcessTrackerSize),
+		queue:            queue,
+		podIndexer:       newTestPodIndexer(t, pod),
+	}
+
+	ed.handleProcessEvent(runtimedetector.ProcessEvent{
+		EventType: runtimedetector.ProcessExecEvent,
+		PID:       4444,
+		ExecDetails: &runtimedetector.ProcessExecDetails{
+			ExePath:      "/usr/local/bin/python3.11",
+			CmdLine:      "python3 /app/main.py",
+			Environments: map[string]string{"PYTHON_VERSION": "3.11.5"},
+		},
+	})
+
+	select {
+	case info := <-queue:
+		if info.Language != "Python" || info.Version != "3.11.5" {
+			t.Errorf("expected Python 3.11.5, got %s %q", info.Language, info.Version)
+		}
+
+		compressed, err := CompressBatch([]ContainerInfo{info})
+		if err != nil {
+			t.Fatalf("failed to compress batch: %v", err)
+		}
+		decoded, err := DecompressBatch(compressed)
+		if err != nil {
+			t.Fatalf("failed to round-trip batch: %v", err)
+		}
+		if decoded[0].Version != "3.11.5" {
+			t.Errorf("expected version to survive the RPC payload, got %q", decoded[0].Version)
+		}
+	default:
+		t.Fatal("expected a result to be enqueued")
+	}
+}
+
+func TestScanAllRunningPodsSkipsIgnoredNamespaces(t *testing.T) {
+	proctest.New(t)
+
+	running := corev1.PodStatus{Phase: corev1.PodRunning}
+	clientset := fake.NewSimpleClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "checkout"}, Status: running},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "coredns"}, Status: running},
+	)
+	pd := &PolylangDetector{IgnoredNamespaces: []string{"kube-system"}}
+	ed := &EBPFDetector{
+		Clientset:              clientset,
+		Cache:                  NewLanguageCache(0),
+		Logger:                 zap.NewNop(),
+		scanPool:               NewPodScanPool(1),
+		ShouldMonitorNamespace: pd.ShouldMonitorNamespace,
+	}
+
+	ed.scanAllRunningPods(context.Background())
+	// Acquiring the single worker slot waits for the last scheduled detection to finish
+	ed.scanPool.sem <- struct{}{}
+
+	if _, scanned := ed.processedPods.Load("shop/checkout"); !scanned {
+		t.Error("expected pod in monitored namespace to be scanned")
+	}
+	if _, scanned := ed.processedPods.Load("kube-system/coredns"); scanned {
+		t.Error("expected pod in ignored namespace to be skipped")
+	}
+}
+
+func TestReconcileCacheKeepsExistingOwnerKinds(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "nightly-report"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "debug"}},
+	)
+	languageCache := NewLanguageCache(0)
+	languageCache.SetWorkload("shop", "nightly-report", "CronJob", map[string]ContainerInfo{})
+	languageCache.SetWorkload("shop", "debug", "Pod", map[string]ContainerInfo{})
+	languageCache.SetWorkload("shop", "canary", "Rollout", map[string]ContainerInfo{})
+	languageCache.SetWorkload("shop", "checkout", "Deployment", map[string]ContainerInfo{})
+	ed := &EBPFDetector{
+		Clientset: clientset,
+		Cache:     languageCache,
+		Logger:    zap.NewNop(),
+	}
+
+	ed.reconcileCache(context.Background())
+
+	for _, name := range []string{"nightly-report", "debug", "canary"} {
+		if _, ok := languageCache.GetWorkload("shop", name); !ok {
+			t.Errorf("expected workload %q to stay cached", name)
+		}
+	}
+	if _, ok := languageCache.GetWorkload("shop", "checkout"); ok {
+		t.Error("expected deleted deployment to be removed from the cache")
+	}
+}
+
+func TestScanAllRunningPodsTagsLogsWithScanID(t *testing.T) {
+	proctest.New(t)
+
+	running := corev1.PodStatus{
+		Phase:             corev1.PodRunning,
+		ContainerStatuses: []corev1.ContainerStatus{{Name: "app", Ready: true}},
+	}
+	spec := corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "app:1.0"}}}
+	clientset := fake.NewSimpleClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "checkout"}, Spec: spec, Status: running},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "billing", Name: "invoices"}, Spec: spec, Status: running},
+	)
+	core, logs := observer.New(zap.DebugLevel)
+	ed := &EBPFDetector{
+		Clientset: clientset,
+		Cache:     NewLanguageCache(0),
+		Logger:    zap.New(core),
+		scanPool:  NewPodScanPool(2),
+	}
+
+	// scanCycle runs one cycle, waits for its detections, and returns the scan IDs it logged
+	scanCycle := func() map[string]int {
+		ed.processedPods.Clear()
+		ed.scanAllRunningPods(context.Background())
+		for range cap(ed.scanPool.sem) {
+			ed.scanPool.sem <- struct{}{}
+		}
+		for range cap(ed.scanPool.sem) {
+			<-ed.scanPool.sem
+		}
+
+		scanIDs := make(map[string]int)
+		for _, entry := range logs.TakeAll() {
+			scanID, _ := entry.ContextMap()["scan_id"].(string)
+			scanIDs[scanID]++
+		}
+		return scanIDs
+	}
+
+	first := scanCycle()
+	if len(first) != 1 {
+		t.Fatalf("expected every event of one cycle to share a scan ID, got %v", first)
+	}
+	for scanID, count := range first {
+		if scanID == "" || count < 5 {
+			t.Errorf("expected the listing and both pods' detections to carry the scan ID, got %d events with %q", count, scanID)
+		}
+	}
+
+	second := scanCycle()
+	for scanID := range second {
+		if _, reused := first[scanID]; reused || len(second) != 1 {
+			t.Errorf("expected the next cycle to get its own scan ID, got %v after %v", second, first)
+		}
+	}
+}
+
+func TestEnqueueProcessResultSkipsIgnoredNamespaces(t *testing.T) {
+	procRoot := proctest.New(t)
+	proctest.WriteProcess(t, procRoot, proctest.Process{
+		PID:     4545,
+		Cmdline: "java\x00-jar\x00/app/app.jar\x00",
+		Cgroup:  "0::/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod1234.slice/cri-containerd-" + testContainerID + ".scope\n",
+	})
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "metrics"},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{Name: "app", Image: "metrics:1.0"},
+		}},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "app", ContainerID: "containerd://" + testContainerID},
+		}},
+	}
+	pd := &PolylangDetector{IgnoredNamespaces: []string{"kube-system"}}
+	queue := make(chan ContainerInfo, 1)
+	ed := &EBPFDetector{
+		Cache:                  NewLanguageCache(0),
+		Logger:                 zap.NewNop(),
+		queue:                  queue,
+		podIndexer:             newTestPodIndexer(t, pod),
+		ShouldMonitorNamespace: pd.ShouldMonitorNamespace,
+	}
+
+	ed.enqueueProcessResult(4545, &inspectors.DetectionResult{
+		Language:   inspectors.LanguageJava,
+		Confidence: inspectors.ConfidenceHigh,
+	})
+
+	select {
+	case info := <-queue:
+		t.Fatalf("expected ignored namespace to be skipped, got %+v", info)
+	default:
+	}
+	if _, found := ed.Cache.GetWorkload("kube-system", "metrics"); found {
+		t.Error("expected ignored namespace not to be cached")
+	}
+}
+
+func TestPodInformerTriggersDetection(t *testing.T) {
+	proctest.New(t)
+
+	clientset := fake.NewSimpleClientset()
+	pd := &PolylangDetector{IgnoredNamespaces: []string{"kube-system"}}
+	ed := &EBPFDetector{
+		Clientset:              clientset,
+		Cache:                  NewLanguageCache(0),
+		Logger:                 zap.NewNop(),
+		informerFactory:        informers.NewSharedInformerFactory(clientset, 0),
+		scanPool:               NewPodScanPool(1),
+		podDebounce:            10 * time.Millisecond,
+		ShouldMonitorNamespace: pd.ShouldMonitorNamespace,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ed.setupInformers(ctx)
+	ed.informerFactory.Start(ctx.Done())
+	ed.informerFactory.WaitForCacheSync(ctx.Done())
+
+	pods := clientset.CoreV1().Pods("shop")
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "checkout"}}
+	if _, err := pods.Create(ctx, pod, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("failed to create pod: %v", err)
+	}
+	ignored := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "coredns"},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	if _, err := clientset.CoreV1().Pods("kube-system").Create(ctx, ignored, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("failed to create pod: %v", err)
+	}
+
+	// A pending pod is not detected until an update reports it running
+	time.Sleep(50 * time.Millisecond)
+	if _, detected := ed.processedPods.Load("shop/checkout"); detected {
+		t.Fatal("expected pending pod not to be detected")
+	}
+
+	pod.Status.Phase = corev1.PodRunning
+	if _, err := pods.UpdateStatus(ctx, pod, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("failed to update pod: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, detected := ed.processedPods.Load("shop/checkout"); detected {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected running pod to be detected from the informer event")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, detected := ed.processedPods.Load("kube-system/coredns"); detected {
+		t.Error("expected pod in ignored namespace not to be detected")
+	}
+}
+
+func TestEBPFDetectorStopWaitsForGoroutines(t *testing.T) {
+	proctest.New(t)
+	baseline := runtime.NumGoroutine()
+
+	clientset := fake.NewSimpleClientset()
+	processEvents := make(chan runtimedetector.ProcessEvent)
+	ed := &EBPFDetector{
+		Clientset:       clientset,
+		Cache:           NewLanguageCache(0),
+		Logger:          zap.NewNop(),
+		processEvents:   processEvents,
+		processes:       newProcessTracker(defaultProcessTrackerSize),
+		queue:           make(chan ContainerInfo),
+		informerFactory: informers.NewSharedInformerFactory(clientset, 0),
+		stopCh:          make(chan struct{}),
+	}
+	if err := ed.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+
+	// A pod detected while nothing reads the queue must not keep Stop waiting
+	running := corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{{Name: "app", Ready: true}}}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "checkout"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "checkout:1.0"}}},
+		Status:     running,
+	}
+	ed.Cache.Set(containerImageRef(pod, &pod.Spec.Containers[0]), nil, ContainerInfo{Language: "Java", Confidence: "high", ConfidenceScore: 90})
+	ed.scanPool.Go(context.Background(), func() { ed.detectPodLanguages(context.Background(), pod) })
+
+	stopped := make(chan struct{})
+	go func() {
+		ed.Stop()
+		ed.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Stop did not return")
+	}
+
+	select {
+	case processEvents <- runtimedetector.ProcessEvent{}:
+		t.Error("expected the process event consumer to have exited")
+	default:
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected goroutines to exit after Stop, %d left running over %d", runtime.NumGoroutine(), baseline)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestDetectPodLanguagesSendsPodResult(t *testing.T) {
+	proctest.New(t)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "orders-0"},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{Name: "orders", Image: "shop/orders:1.0"},
+			{Name: "cloudsql", Image: "gcr.io/cloud-sql-connectors/cloud-sql-proxy:2.8.0"},
+		}},
+	}
+	cache := NewLanguageCache(0)
+	for i, language := range []string{"Python", "Go"} {
+		cached := ContainerInfo{Image: pod.Spec.Containers[i].Image, Language: language}
+		cached.setConfidence(inspectors.ConfidenceHigh)
+		cache.Set(containerImageRef(pod, &pod.Spec.Containers[i]), map[string]string{}, cached)
+	}
+
+	var results []PodDetectionResult
+	ed := &EBPFDetector{
+		Clientset:     fake.NewSimpleClientset(pod),
+		Cache:         cache,
+		Logger:        zap.NewNop(),
+		queue:         make(chan ContainerInfo, 10),
+		SendPodResult: func(result PodDetectionResult) { results = append(results, result) },
+	}
+	ed.detectPodLanguages(context.Background(), pod)
+
+	if len(results) != 1 {
+		t.Fatalf("expected one pod result, got %d", len(results))
+	}
+	if result := results[0]; result.PrimaryContainer != "orders" || result.PrimaryLanguage != "Python" || len(result.Containers) != 2 {
+		t.Errorf("expected the app container to speak for the pod, got %+v", result)
+	}
+}
+
+func TestDetectPodLanguagesReportsOwnImageOnCacheHit(t *testing.T) {
+	proctest.New(t)
+
+	// Another workload cached the detection for the same image digest under a different tag
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "orders-canary-0"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "orders", Image: "registry.local/shop/orders:canary"}}},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "orders", ImageID: "registry.local/shop/orders@sha256:4f1c"},
+		}},
+	}
+	cache := NewLanguageCache(0)
+	cached := ContainerInfo{Image: "registry.local/shop/orders:1.0", Language: "Python"}
+	cached.setConfidence(inspectors.ConfidenceHigh)
+	cache.Set(containerImageRef(pod, &pod.Spec.Containers[0]), map[string]string{}, cached)
+
+	var results []PodDetectionResult
+	ed := &EBPFDetector{
+		Clientset:     fake.NewSimpleClientset(pod),
+		Cache:         cache,
+		Logger:        zap.NewNop(),
+		queue:         make(chan ContainerInfo, 10),
+		SendPodResult: func(result PodDetectionResult) { results = append(results, result) },
+	}
+	ed.detectPodLanguages(context.Background(), pod)
+
+	if len(results) != 1 || len(results[0].Containers) != 1 {
+		t.Fatalf("expected one pod result with one container, got %+v", results)
+	}
+	if image := results[0].Containers[0].Image; image != "registry.local/shop/orders:canary" {
+		t.Errorf("expected the pod's own image on a cache hit, got %q", image)
+	}
+}
+
+func TestDetectContainerLanguageKeepsContainersApart(t *testing.T) {
+	const workerContainerID = "9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e6f5a4b3c2d1e0f9a8b"
+	procRoot := proctest.New(t)
+	cgroupPrefix := "0::/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod1234.slice/cri-containerd-"
+	proctest.WriteProcess(t, procRoot, proctest.Process{
+		PID:     4301,
+		Exe:     "/usr/bin/java",
+		Cmdline: "java\x00-jar\x00/app/app.jar\x00",
+		Cgroup:  cgroupPrefix + testContainerID + ".scope\n",
+	})
+	proctest.WriteProcess(t, procRoot, proctest.Process{
+		PID:     4302,
+		Exe:     "/usr/local/bin/python3.11",
+		Cmdline: "python3\x00/app/worker.py\x00",
+		Cgroup:  cgroupPrefix + workerContainerID + ".scope\n",
+	})
+
+	checkout := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "checkout"},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{Name: "app", Image: "checkout:1.0"},
+			{Name: "worker", Image: "checkout-worker:1.0"},
+		}},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "app", ContainerID: "containerd://" + testContainerID},
+			{Name: "worker", ContainerID: "containerd://" + workerContainerID},
+		}},
+	}
+	ed := &EBPFDetector{
+		Clientset:        fake.NewSimpleClientset(checkout),
+		LanguageDetector: inspectors.NewLanguageDetector(),
+		Cache:            NewLanguageCache(0),
+		Logger:           zap.NewNop(),
+	}
+
+	want := map[string]string{"app": "Java", "worker": "Python"}
+	for i := range checkout.Spec.Containers {
+		container := &checkout.Spec.Containers[i]
+		info := ed.detectContainerLanguage(context.Background(), checkout, container)
+		if info.Language != want[container.Name] {
+			t.Errorf("expected container %s to be detected as %s from its own process, got %s", container.Name, want[container.Name], info.Language)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package detector
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// envList parses a comma-separated environment variable into trimmed, non-empty values
+func envList(key string) []string {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return nil
+	}
+
+	var values []string
+	for _, value := range strings.Split(raw, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// envBool parses a boolean environment variable, returning def when unset or invalid
+func envBool(key string, def bool) bool {
+	if parsed, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return parsed
+	}
+	return def
+}
+
+// envInt parses a positive integer environment variable, returning def when unset or invalid
+func envInt(key string, def int) int {
+	if parsed, err := strconv.Atoi(os.Getenv(key)); err == nil && parsed > 0 {
+		return parsed
+	}
+	return def
+}
+
+// envDuration parses a positive Go duration (e.g. "30s", "2m") environment variable,
+// returning def when unset or invalid
+func envDuration(key string, def time.Duration) time.Duration {
+	if parsed, err := time.ParseDuration(os.Getenv(key)); err == nil && parsed > 0 {
+		return parsed
+	}
+	return def
+}
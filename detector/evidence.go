@@ -0,0 +1,28 @@
+package detector
+
+// evidenceAccumulator collects unique evidence entries across detection passes,
+// tagged with the pass that produced them, in the order they were first seen.
+// Evidence from passes that didn't decide the language is kept so the final
+// ContainerInfo explains everything that was observed.
+type evidenceAccumulator struct {
+	seen    map[string]struct{}
+	entries []string
+}
+
+// Add records evidence produced by source, ignoring exact duplicates
+func (ea *evidenceAccumulator) Add(source, evidence string) {
+	entry := "[" + source + "] " + evidence
+	if _, exists := ea.seen[entry]; exists {
+		return
+	}
+	if ea.seen == nil {
+		ea.seen = make(map[string]struct{})
+	}
+	ea.seen[entry] = struct{}{}
+	ea.entries = append(ea.entries, entry)
+}
+
+// Entries returns the accumulated evidence in insertion order
+func (ea *evidenceAccumulator) Entries() []string {
+	return ea.entries
+}
@@ -0,0 +1,36 @@
+package detector
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEvidenceAccumulatorDeduplicates(t *testing.T) {
+	var evidence evidenceAccumulator
+	evidence.Add("proc", "gunicorn process detected as Python with high confidence")
+	evidence.Add("proc", "gunicorn process detected as Python with high confidence")
+	evidence.Add("ebpf", "gunicorn process detected as Python with high confidence")
+
+	expected := []string{
+		"[proc] gunicorn process detected as Python with high confidence",
+		"[ebpf] gunicorn process detected as Python with high confidence",
+	}
+	if got := evidence.Entries(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Entries() = %v, want %v", got, expected)
+	}
+}
+
+func TestEvidenceAccumulatorKeepsNonWinningEvidence(t *testing.T) {
+	var evidence evidenceAccumulator
+	evidence.Add("proc", "node process detected as nodejs with medium confidence")
+	evidence.Add("proc", "java process detected as Java with high confidence")
+	evidence.Add("proc", "Detected via /proc inspection with high confidence")
+
+	entries := evidence.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %v", entries)
+	}
+	if entries[0] != "[proc] node process detected as nodejs with medium confidence" {
+		t.Errorf("expected losing detection to be retained first, got %q", entries[0])
+	}
+}
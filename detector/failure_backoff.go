@@ -0,0 +1,130 @@
+package detector
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Defaults for FailureBackoff
+const (
+	defaultFailureBaseDelay   = 30 * time.Second
+	defaultFailureMaxDelay    = 30 * time.Minute
+	defaultMaxPodFailures     = 8
+	defaultFailureTrackerSize = 5000
+)
+
+// FailureBackoff tracks consecutive detection failures per pod so pods that can't be
+// inspected (distroless, permission denied, crash-looping) are retried with exponential
+// backoff instead of every scan cycle. After MaxFailures the pod is treated as undetectable
+// and skipped until its container images change. It is bounded and safe for concurrent use.
+type FailureBackoff struct {
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	MaxFailures int
+	MaxEntries  int
+
+	mu      sync.Mutex
+	entries map[string]*podFailure // namespace/name -> failure state
+	now     func() time.Time
+}
+
+// podFailure is the failure state of one pod
+type podFailure struct {
+	specHash  string
+	failures  int
+	nextRetry time.Time
+}
+
+// NewFailureBackoff creates a FailureBackoff with the default delays and limits
+func NewFailureBackoff() *FailureBackoff {
+	return &FailureBackoff{
+		BaseDelay:   defaultFailureBaseDelay,
+		MaxDelay:    defaultFailureMaxDelay,
+		MaxFailures: defaultMaxPodFailures,
+		MaxEntries:  defaultFailureTrackerSize,
+		entries:     make(map[string]*podFailure),
+		now:         time.Now,
+	}
+}
+
+// ShouldAttempt reports whether detection should be tried for the pod now. A pod whose
+// images changed since its failures were recorded starts over.
+func (fb *FailureBackoff) ShouldAttempt(pod *corev1.Pod) bool {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	key := pod.Namespace + "/" + pod.Name
+	entry, exists := fb.entries[key]
+	if !exists {
+		return true
+	}
+	if entry.specHash != podSpecHash(pod) {
+		delete(fb.entries, key)
+		return true
+	}
+	if entry.failures >= fb.MaxFailures {
+		return false
+	}
+	return !fb.now().Before(entry.nextRetry)
+}
+
+// RecordFailure records a failed detection and schedules the next retry, returning the
+// consecutive failure count and whether the pod is now considered undetectable
+func (fb *FailureBackoff) RecordFailure(pod *corev1.Pod) (failures int, undetectable bool) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	key := pod.Namespace + "/" + pod.Name
+	specHash := podSpecHash(pod)
+	entry, exists := fb.entries[key]
+	if !exists || entry.specHash != specHash {
+		if !exists && len(fb.entries) >= fb.MaxEntries {
+			fb.evictEarliest()
+		}
+		entry = &podFailure{specHash: specHash}
+		fb.entries[key] = entry
+	}
+
+	entry.failures++
+	delay := fb.MaxDelay
+	if shift := entry.failures - 1; shift < 32 {
+		delay = min(fb.BaseDelay<<shift, fb.MaxDelay)
+	}
+	entry.nextRetry = fb.now().Add(delay)
+
+	return entry.failures, entry.failures >= fb.MaxFailures
+}
+
+// RecordSuccess clears the pod's failure state
+func (fb *FailureBackoff) RecordSuccess(pod *corev1.Pod) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	delete(fb.entries, pod.Namespace+"/"+pod.Name)
+}
+
+// evictEarliest removes the entry due for retry soonest, which loses the least backoff
+// when the tracker is full. Callers must hold fb.mu.
+func (fb *FailureBackoff) evictEarliest() {
+	var earliestKey string
+	var earliest time.Time
+	for key, entry := range fb.entries {
+		if earliestKey == "" || entry.nextRetry.Before(earliest) {
+			earliestKey, earliest = key, entry.nextRetry
+		}
+	}
+	delete(fb.entries, earliestKey)
+}
+
+// podSpecHash identifies the pod's container images, so a rollout to a new image resets its failures
+func podSpecHash(pod *corev1.Pod) string {
+	h := sha256.New()
+	for _, pc := range podContainers(pod, true) {
+		fmt.Fprintf(h, "%s=%s\n", pc.Container.Name, pc.Container.Image)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
@@ -0,0 +1,78 @@
+package detector
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFailureBackoffGrowsAndGivesUp(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	fb := NewFailureBackoff()
+	fb.BaseDelay, fb.MaxDelay, fb.MaxFailures = time.Second, 8*time.Second, 5
+	fb.now = func() time.Time { return now }
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "distroless"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "distroless:1"}}},
+	}
+
+	// Each failure doubles the wait, capped at MaxDelay: 1s, 2s, 4s, 8s
+	for i, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second} {
+		if !fb.ShouldAttempt(pod) {
+			t.Fatalf("attempt %d: expected pod to be retried", i+1)
+		}
+		if _, undetectable := fb.RecordFailure(pod); undetectable {
+			t.Fatalf("attempt %d: marked undetectable too early", i+1)
+		}
+
+		now = now.Add(want - time.Millisecond)
+		if fb.ShouldAttempt(pod) {
+			t.Fatalf("attempt %d: expected retry to wait %s", i+1, want)
+		}
+		now = now.Add(time.Millisecond)
+	}
+
+	if _, undetectable := fb.RecordFailure(pod); !undetectable {
+		t.Fatal("expected pod to be undetectable after MaxFailures")
+	}
+	now = now.Add(time.Hour)
+	if fb.ShouldAttempt(pod) {
+		t.Error("expected undetectable pod to be skipped")
+	}
+
+	// A new image gives the pod a fresh start
+	pod.Spec.Containers[0].Image = "distroless:2"
+	if !fb.ShouldAttempt(pod) {
+		t.Error("expected pod to be retried after its image changed")
+	}
+}
+
+func TestFailureBackoffSuccessResets(t *testing.T) {
+	fb := NewFailureBackoff()
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "checkout"}}
+
+	fb.RecordFailure(pod)
+	if fb.ShouldAttempt(pod) {
+		t.Fatal("expected pod to back off after a failure")
+	}
+
+	fb.RecordSuccess(pod)
+	if !fb.ShouldAttempt(pod) {
+		t.Error("expected success to clear the backoff")
+	}
+}
+
+func TestFailureBackoffIsBounded(t *testing.T) {
+	fb := NewFailureBackoff()
+	fb.MaxEntries = 2
+
+	for _, name := range []string{"a", "b", "c"} {
+		fb.RecordFailure(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: name}})
+	}
+	if len(fb.entries) != 2 {
+		t.Errorf("expected at most 2 tracked pods, got %d", len(fb.entries))
+	}
+}
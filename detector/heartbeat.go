@@ -0,0 +1,49 @@
+package detector
+
+import (
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultHeartbeatInterval is how often a heartbeat is sent when KM_HEARTBEAT_INTERVAL is unset or invalid
+const DefaultHeartbeatInterval = 30 * time.Second
+
+// Heartbeat tells the config updater the detector is alive, so it can tell a detector
+// with nothing to report from one that has stopped
+type Heartbeat struct {
+	NodeName        string
+	DetectorVersion string
+	DetectorCommit  string
+	Timestamp       time.Time
+}
+
+// nodeName returns the node the detector runs on (NODE_NAME, normally set via the
+// downward API), falling back to the hostname
+func nodeName() string {
+	if name := os.Getenv("NODE_NAME"); name != "" {
+		return name
+	}
+	hostname, _ := os.Hostname()
+	return hostname
+}
+
+// SendHeartbeat sends a heartbeat to the updater. It is skipped while there is no
+// connection; reconnecting is left to SendBatch so heartbeats stay lightweight.
+func (pd *PolylangDetector) SendHeartbeat() {
+	if pd.ServerAddr == "" || pd.RpcClient == nil {
+		return
+	}
+
+	heartbeat := Heartbeat{
+		NodeName:        pd.NodeName,
+		DetectorVersion: pd.DetectorVersion,
+		DetectorCommit:  pd.DetectorCommit,
+		Timestamp:       time.Now(),
+	}
+	var reply string
+	if err := pd.callOptional("RPCHandler.Heartbeat", heartbeat, &reply); err != nil {
+		pd.Logger.Warn("Failed to send heartbeat", zap.Error(err))
+	}
+}
@@ -0,0 +1,58 @@
+package detector
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// defaultIdentityKeys are label/annotation keys that commonly carry a mesh or workload identity
+var defaultIdentityKeys = []string{
+	"spiffe.io/spiffe-id",
+	"security.istio.io/identity",
+	"linkerd.io/proxy-identity",
+	"app.kubernetes.io/name",
+}
+
+// WorkloadIdentityConfig controls capture of the pod's ServiceAccount and identity labels.
+// Disabled by default to keep RPC payloads small.
+type WorkloadIdentityConfig struct {
+	Enabled bool
+	Keys    []string
+}
+
+// NewWorkloadIdentityConfigFromEnv reads KM_CAPTURE_WORKLOAD_IDENTITY and KM_IDENTITY_KEYS
+func NewWorkloadIdentityConfigFromEnv() WorkloadIdentityConfig {
+	keys := envList("KM_IDENTITY_KEYS")
+	if len(keys) == 0 {
+		keys = defaultIdentityKeys
+	}
+
+	return WorkloadIdentityConfig{
+		Enabled: envBool("KM_CAPTURE_WORKLOAD_IDENTITY", false),
+		Keys:    keys,
+	}
+}
+
+// Apply copies the pod's ServiceAccount and configured identity labels/annotations onto info.
+// info may be a cache-hit copy of another pod's detection, so identity labels it carries
+// are replaced rather than kept when this pod has none.
+func (c WorkloadIdentityConfig) Apply(info *ContainerInfo, pod *corev1.Pod) {
+	if !c.Enabled || info == nil || pod == nil {
+		return
+	}
+
+	info.ServiceAccount = pod.Spec.ServiceAccountName
+	info.IdentityLabels = nil
+
+	identity := make(map[string]string)
+	for _, key := range c.Keys {
+		// Annotations take precedence since mesh injectors usually write identity there
+		if value, ok := pod.Annotations[key]; ok {
+			identity[key] = value
+		} else if value, ok := pod.Labels[key]; ok {
+			identity[key] = value
+		}
+	}
+	if len(identity) > 0 {
+		info.IdentityLabels = identity
+	}
+}
@@ -0,0 +1,71 @@
+package detector
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestWorkloadIdentityConfigApply(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      map[string]string{"app.kubernetes.io/name": "checkout"},
+			Annotations: map[string]string{"spiffe.io/spiffe-id": "spiffe://cluster.local/ns/shop/sa/checkout"},
+		},
+		Spec: corev1.PodSpec{ServiceAccountName: "checkout"},
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		info := &ContainerInfo{}
+		WorkloadIdentityConfig{Enabled: true, Keys: defaultIdentityKeys}.Apply(info, pod)
+
+		if info.ServiceAccount != "checkout" {
+			t.Errorf("expected service account checkout, got %q", info.ServiceAccount)
+		}
+		if info.IdentityLabels["spiffe.io/spiffe-id"] != "spiffe://cluster.local/ns/shop/sa/checkout" {
+			t.Errorf("expected SPIFFE ID to be captured, got %v", info.IdentityLabels)
+		}
+		if info.IdentityLabels["app.kubernetes.io/name"] != "checkout" {
+			t.Errorf("expected app name label to be captured, got %v", info.IdentityLabels)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		info := &ContainerInfo{}
+		WorkloadIdentityConfig{Enabled: false, Keys: defaultIdentityKeys}.Apply(info, pod)
+
+		if info.ServiceAccount != "" || info.IdentityLabels != nil {
+			t.Errorf("expected nothing captured when disabled, got %+v", info)
+		}
+	})
+}
+
+func TestWorkloadIdentityConfigApplyReplacesCachedIdentity(t *testing.T) {
+	config := WorkloadIdentityConfig{Enabled: true, Keys: defaultIdentityKeys}
+	checkout := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{"spiffe.io/spiffe-id": "spiffe://cluster.local/ns/shop/sa/checkout"},
+		},
+		Spec: corev1.PodSpec{ServiceAccountName: "checkout", Containers: []corev1.Container{{Name: "app", Image: "shop/api:1.4"}}},
+	}
+	reports := &corev1.Pod{
+		Spec: corev1.PodSpec{ServiceAccountName: "reports", Containers: []corev1.Container{{Name: "app", Image: "shop/api:1.4"}}},
+	}
+
+	// The second pod shares the image, so it starts from a copy of the first pod's cached detection
+	cached := ContainerInfo{Image: "shop/api:1.4", Language: "Java"}
+	config.Apply(&cached, checkout)
+	info := cached
+	config.Apply(&info, reports)
+
+	if info.ServiceAccount != "reports" {
+		t.Errorf("expected service account reports, got %q", info.ServiceAccount)
+	}
+	if info.IdentityLabels != nil {
+		t.Errorf("expected the checkout pod's identity to be dropped, got %v", info.IdentityLabels)
+	}
+	if cached.IdentityLabels["spiffe.io/spiffe-id"] == "" {
+		t.Error("expected the cached detection's identity to be left intact")
+	}
+}
@@ -0,0 +1,35 @@
+package inspectors
+
+import (
+	"strings"
+
+	"github.com/kloudmate/polylang-detector/detector/process"
+)
+
+// agentSignatures maps cmdline fragments of APM agents and launch wrappers to the agent name.
+// These agents often ship their own (Go or native) launcher, which would otherwise be
+// reported instead of the application it wraps.
+var agentSignatures = []struct {
+	Signature string
+	Agent     string
+}{
+	{"dynatrace", "Dynatrace"},
+	{"oneagent", "Dynatrace"},
+	{"newrelic", "New Relic"},
+	{"dd-trace", "Datadog"},
+	{"dd-java-agent", "Datadog"},
+	{"ddtrace", "Datadog"},
+	{"appdynamics", "AppDynamics"},
+}
+
+// DetectAgent returns the name of a known APM agent or wrapper referenced by the
+// process command line, or "" when none is found
+func DetectAgent(ctx *process.ProcessContext) string {
+	cmdlineLower := strings.ToLower(ctx.Cmdline)
+	for _, sig := range agentSignatures {
+		if strings.Contains(cmdlineLower, sig.Signature) {
+			return sig.Agent
+		}
+	}
+	return ""
+}
@@ -0,0 +1,54 @@
+package inspectors
+
+import (
+	"testing"
+
+	"github.com/kloudmate/polylang-detector/detector/process"
+	"github.com/kloudmate/polylang-detector/internal/proctest"
+)
+
+func TestDetectJavaUnderDatadogAgent(t *testing.T) {
+	ctx := &process.ProcessContext{
+		PID:        -1,
+		Executable: "/usr/bin/java",
+		Cmdline:    "java -javaagent:/opt/datadog/dd-java-agent.jar -jar /app/orders.jar",
+	}
+
+	result, err := NewLanguageDetector().Detect(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Language != LanguageJava {
+		t.Errorf("expected Java, got %s", result.Language)
+	}
+	if result.AgentDetected != "Datadog" {
+		t.Errorf("expected Datadog agent, got %q", result.AgentDetected)
+	}
+}
+
+func TestDetectWrappedProcessUnderDatadogWrapper(t *testing.T) {
+	root := proctest.New(t)
+
+	proctest.WriteProcess(t, root, proctest.Process{PID: 10, PPID: 1, Exe: "/opt/datadog/dd-trace-run", Cmdline: "dd-trace-run\x00--\x00/app/start\x00"})
+	proctest.WriteProcess(t, root, proctest.Process{PID: 11, PPID: 10, Exe: "/usr/bin/java", Cmdline: "java\x00-jar\x00/app/orders.jar\x00"})
+
+	wrapper, err := process.GetProcessContext(10)
+	if err != nil {
+		t.Fatalf("failed to read wrapper process: %v", err)
+	}
+
+	result, err := NewLanguageDetector().Detect(wrapper)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Language != LanguageJava || result.AgentDetected != "Datadog" {
+		t.Errorf("expected Java under Datadog, got %s under %q", result.Language, result.AgentDetected)
+	}
+}
+
+func TestDetectAgentIgnoresPlainProcess(t *testing.T) {
+	ctx := &process.ProcessContext{Executable: "/usr/bin/python3", Cmdline: "python3 app.py"}
+	if agent := DetectAgent(ctx); agent != "" {
+		t.Errorf("expected no agent, got %q", agent)
+	}
+}
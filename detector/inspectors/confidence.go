@@ -0,0 +1,62 @@
+package inspectors
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Confidence is a detection confidence score from 0 to 100
+type Confidence int
+
+// Named confidence levels; scores at or above a level map to its label
+const (
+	ConfidenceNone   Confidence = 0
+	ConfidenceLow    Confidence = 30
+	ConfidenceMedium Confidence = 60
+	ConfidenceHigh   Confidence = 90
+)
+
+// String returns the "high"/"medium"/"low" label for the score, as used in logs and output
+func (c Confidence) String() string {
+	switch {
+	case c >= ConfidenceHigh:
+		return "high"
+	case c >= ConfidenceMedium:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// ParseConfidence converts a "high"/"medium"/"low" label to its score, returning ConfidenceNone for unknown labels
+func ParseConfidence(label string) Confidence {
+	switch label {
+	case "high":
+		return ConfidenceHigh
+	case "medium":
+		return ConfidenceMedium
+	case "low":
+		return ConfidenceLow
+	default:
+		return ConfidenceNone
+	}
+}
+
+// MarshalText encodes the confidence as its label, so JSON output matches ContainerInfo
+func (c Confidence) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText decodes a label, or a numeric score for input written before labels were used
+func (c *Confidence) UnmarshalText(text []byte) error {
+	if confidence := ParseConfidence(string(text)); confidence != ConfidenceNone {
+		*c = confidence
+		return nil
+	}
+	score, err := strconv.Atoi(string(text))
+	if err != nil {
+		return fmt.Errorf("invalid confidence %q", text)
+	}
+	*c = Confidence(score)
+	return nil
+}
@@ -41,8 +41,47 @@ func (e *ErrLanguageDetectionConflict) Error() string {
 	return fmt.Sprintf("detected more than one language: [%s]", strings.Join(langs, ", "))
 }
 
-// Detect performs two-stage language detection
+// Detect performs two-stage language detection. When an APM agent or wrapper is
+// attached, the result is marked with it and, if the wrapper process itself reveals
+// no language, the language is taken from the process it launched.
 func (ld *LanguageDetector) Detect(ctx *process.ProcessContext) (*DetectionResult, error) {
+	result, err := ld.detect(ctx)
+
+	agent := DetectAgent(ctx)
+	if agent == "" || err != nil {
+		return result, err
+	}
+
+	if result.Language == LanguageUnknown {
+		if wrapped := ld.detectWrappedProcess(ctx); wrapped != nil {
+			result = wrapped
+		}
+	}
+	result.AgentDetected = agent
+	return result, nil
+}
+
+// detectWrappedProcess detects the language of the first child of a wrapper process
+func (ld *LanguageDetector) detectWrappedProcess(ctx *process.ProcessContext) *DetectionResult {
+	children, err := process.ChildPIDs(ctx.PID)
+	if err != nil {
+		return nil
+	}
+
+	for _, pid := range children {
+		child, err := process.GetProcessContext(pid)
+		if err != nil {
+			continue
+		}
+		if result, err := ld.detect(child); err == nil && result.Language != LanguageUnknown {
+			return result
+		}
+	}
+	return nil
+}
+
+// detect runs QuickScan and, if inconclusive, DeepScan across all inspectors
+func (ld *LanguageDetector) detect(ctx *process.ProcessContext) (*DetectionResult, error) {
 	// Stage 1: QuickScan
 	quickResults := make([]*DetectionResult, 0)
 	for _, inspector := range ld.inspectors {
@@ -52,7 +91,7 @@ func (ld *LanguageDetector) Detect(ctx *process.ProcessContext) (*DetectionResul
 	}
 
 	// If we have exactly one high-confidence quick result, return it
-	if len(quickResults) == 1 && quickResults[0].Confidence == "high" {
+	if len(quickResults) == 1 && quickResults[0].Confidence >= ConfidenceHigh {
 		return quickResults[0], nil
 	}
 
@@ -119,12 +158,22 @@ func (ld *LanguageDetector) Detect(ctx *process.ProcessContext) (*DetectionResul
 		return nil, &ErrLanguageDetectionConflict{Languages: languages}
 	}
 
+	// Fall back to the shebang line when the process is running a script directly
+	if result := detectFromShebang(ctx); result != nil {
+		return result, nil
+	}
+
+	// Last, look at the files the process has open (scripts, jars, node_modules)
+	if result := detectFromOpenFiles(ctx); result != nil {
+		return result, nil
+	}
+
 	// No language detected
 	return &DetectionResult{
 		Language:   LanguageUnknown,
 		Framework:  "",
 		Version:    "",
-		Confidence: "low",
+		Confidence: ConfidenceLow,
 	}, nil
 }
 
@@ -157,8 +206,8 @@ func (ld *LanguageDetector) selectBestResult(results []*DetectionResult) *Detect
 
 	best := results[0]
 	for _, result := range results[1:] {
-		// Prefer high confidence over medium/low
-		if result.Confidence == "high" && best.Confidence != "high" {
+		// Prefer the higher confidence score
+		if result.Confidence > best.Confidence {
 			best = result
 			continue
 		}
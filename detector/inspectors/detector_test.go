@@ -0,0 +1,129 @@
+package inspectors
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/kloudmate/polylang-detector/detector/process"
+	"github.com/kloudmate/polylang-detector/internal/proctest"
+)
+
+// stubInspector returns fixed QuickScan and DeepScan results
+type stubInspector struct {
+	language Language
+	quick    *DetectionResult
+	deep     *DetectionResult
+}
+
+func (s *stubInspector) QuickScan(*process.ProcessContext) *DetectionResult { return s.quick }
+func (s *stubInspector) DeepScan(*process.ProcessContext) *DetectionResult  { return s.deep }
+func (s *stubInspector) GetLanguage() Language                              { return s.language }
+
+func TestConfidenceLabels(t *testing.T) {
+	tests := []struct {
+		score    Confidence
+		expected string
+	}{
+		{ConfidenceHigh, "high"},
+		{95, "high"},
+		{ConfidenceMedium, "medium"},
+		{89, "medium"},
+		{ConfidenceLow, "low"},
+		{ConfidenceNone, "low"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.score.String(); got != tt.expected {
+			t.Errorf("Confidence(%d).String() = %q, want %q", tt.score, got, tt.expected)
+		}
+		if ParseConfidence(tt.expected).String() != tt.expected {
+			t.Errorf("ParseConfidence(%q) does not round-trip", tt.expected)
+		}
+	}
+}
+
+func TestDetectEscalation(t *testing.T) {
+	tests := []struct {
+		name     string
+		quick    Confidence
+		deep     Confidence
+		expected Confidence
+	}{
+		{name: "high quick result is returned without deep scan", quick: ConfidenceHigh, deep: ConfidenceMedium, expected: ConfidenceHigh},
+		{name: "score above high threshold is conclusive", quick: 95, deep: ConfidenceMedium, expected: 95},
+		{name: "medium quick result escalates to deep scan", quick: ConfidenceMedium, deep: ConfidenceHigh, expected: ConfidenceHigh},
+		{name: "score just below high threshold escalates", quick: 89, deep: ConfidenceLow, expected: ConfidenceLow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ld := &LanguageDetector{inspectors: []LanguageInspector{&stubInspector{
+				language: LanguageJava,
+				quick:    &DetectionResult{Language: LanguageJava, Confidence: tt.quick},
+				deep:     &DetectionResult{Language: LanguageJava, Confidence: tt.deep},
+			}}}
+
+			result, err := ld.Detect(&process.ProcessContext{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Confidence != tt.expected {
+				t.Errorf("expected confidence %d, got %d", tt.expected, result.Confidence)
+			}
+		})
+	}
+}
+
+func TestSelectBestResultPrefersHigherScore(t *testing.T) {
+	ld := NewLanguageDetector()
+	best := ld.selectBestResult([]*DetectionResult{
+		{Language: LanguagePython, Confidence: ConfidenceMedium, Framework: "Django"},
+		{Language: LanguagePython, Confidence: 75},
+		{Language: LanguagePython, Confidence: ConfidenceLow},
+	})
+
+	if best.Confidence != 75 {
+		t.Errorf("expected the highest-scoring result, got %+v", best)
+	}
+}
+
+func TestDetectReadsMapsFileOnce(t *testing.T) {
+	root := proctest.New(t)
+
+	// An unrecognized process escalates to every inspector's DeepScan
+	proctest.WriteProcess(t, root, proctest.Process{PID: 20, PPID: 1, Exe: "/app/server", Cmdline: "/app/server\x00"})
+	proctest.WriteMaps(t, root, 20, "/usr/lib/libc.so.6")
+
+	ctx, err := process.GetProcessContext(20)
+	if err != nil {
+		t.Fatalf("failed to read process: %v", err)
+	}
+	NewLanguageDetector().Detect(ctx)
+
+	// Had any inspector re-read the file it would now see libjvm
+	proctest.WriteMaps(t, root, 20, "/usr/lib/jvm/lib/server/libjvm.so")
+	mapsFile, err := ctx.MapsFile()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(mapsFile.Content, "libjvm.so") {
+		t.Error("expected the maps file read during detection to be reused")
+	}
+}
+
+func BenchmarkDetectDeepScan(b *testing.B) {
+	root := proctest.New(b)
+
+	libs := make([]string, 5000)
+	for i := range libs {
+		libs[i] = "/usr/lib/x86_64-linux-gnu/libfixture" + strconv.Itoa(i) + ".so"
+	}
+	proctest.WriteMaps(b, root, 30, libs...)
+
+	ld := NewLanguageDetector()
+	b.ResetTimer()
+	for range b.N {
+		ld.Detect(&process.ProcessContext{PID: 30, Executable: "/app/server", Cmdline: "/app/server"})
+	}
+}
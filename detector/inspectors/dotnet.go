@@ -8,10 +8,14 @@ import (
 	"github.com/kloudmate/polylang-detector/detector/process"
 )
 
-type DotNetInspector struct{}
+type DotNetInspector struct {
+	elfAnalyzer *process.ELFAnalyzer
+}
 
 func NewDotNetInspector() *DotNetInspector {
-	return &DotNetInspector{}
+	return &DotNetInspector{
+		elfAnalyzer: process.NewELFAnalyzer(),
+	}
 }
 
 func (d *DotNetInspector) GetLanguage() Language {
@@ -30,7 +34,7 @@ func (d *DotNetInspector) QuickScan(ctx *process.ProcessContext) *DetectionResul
 			Language:   LanguageDotNet,
 			Framework:  framework,
 			Version:    version,
-			Confidence: "high",
+			Confidence: ConfidenceHigh,
 		}
 	}
 
@@ -42,7 +46,7 @@ func (d *DotNetInspector) QuickScan(ctx *process.ProcessContext) *DetectionResul
 				Language:   LanguageDotNet,
 				Framework:  d.detectFramework(ctx),
 				Version:    d.extractVersion(ctx),
-				Confidence: "medium",
+				Confidence: ConfidenceMedium,
 			}
 		}
 	}
@@ -52,18 +56,24 @@ func (d *DotNetInspector) QuickScan(ctx *process.ProcessContext) *DetectionResul
 
 func (d *DotNetInspector) DeepScan(ctx *process.ProcessContext) *DetectionResult {
 	// Check memory maps for .NET Core libraries
-	mapsFile, err := process.ReadMapsFile(ctx.PID)
-	if err != nil {
-		return nil
-	}
-
 	dotnetLibs := []string{"libcoreclr.so", "System.Private.CoreLib.dll"}
-	if process.ContainsBinary(mapsFile, dotnetLibs) {
+	if mapsFile, err := ctx.MapsFile(); err == nil && process.ContainsBinary(mapsFile, dotnetLibs) {
 		return &DetectionResult{
 			Language:   LanguageDotNet,
 			Framework:  d.detectFramework(ctx),
 			Version:    d.extractVersion(ctx),
-			Confidence: "high",
+			Confidence: ConfidenceHigh,
+		}
+	}
+
+	// Native AOT binaries embed the runtime instead of loading libcoreclr, so they
+	// would otherwise be mistaken for native C/C++ executables
+	if isAOT, _ := d.elfAnalyzer.HasDotNetNativeAOTMarkers(process.ExecutableFile(ctx)); isAOT {
+		return &DetectionResult{
+			Language:   LanguageDotNet,
+			Framework:  "Native AOT",
+			Version:    d.extractVersion(ctx),
+			Confidence: ConfidenceHigh,
 		}
 	}
 
@@ -0,0 +1,36 @@
+package inspectors
+
+import (
+	"debug/elf"
+	"testing"
+
+	"github.com/kloudmate/polylang-detector/detector/process"
+	"github.com/kloudmate/polylang-detector/internal/elftest"
+)
+
+func TestDotNetInspectorDetectsNativeAOTBinary(t *testing.T) {
+	exe := elftest.Write(t, "Orders.Api", elftest.Options{
+		Sections: []elftest.Section{{Name: ".text", Type: elf.SHT_PROGBITS, Data: []byte{0x90, 0xc3}}},
+		Symbols:  []string{"main", "S_P_CoreLib_System_String__Concat", "RhpNewFast"},
+	})
+
+	ctx := &process.ProcessContext{PID: -1, Executable: exe, Cmdline: exe}
+	result, err := NewLanguageDetector().Detect(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Language != LanguageDotNet || result.Framework != "Native AOT" {
+		t.Errorf("expected .NET (Native AOT), got %s (%s)", result.Language, result.Framework)
+	}
+}
+
+func TestDotNetInspectorIgnoresPlainNativeBinary(t *testing.T) {
+	exe := elftest.Write(t, "nginx", elftest.Options{
+		Sections: []elftest.Section{{Name: ".text", Type: elf.SHT_PROGBITS, Data: []byte{0x90, 0xc3}}},
+		Symbols:  []string{"main", "ngx_http_process_request"},
+	})
+
+	if result := NewDotNetInspector().DeepScan(&process.ProcessContext{PID: -1, Executable: exe}); result != nil {
+		t.Errorf("expected no detection for a plain native binary, got %+v", result)
+	}
+}
@@ -23,14 +23,14 @@ func (g *GoInspector) GetLanguage() Language {
 
 func (g *GoInspector) QuickScan(ctx *process.ProcessContext) *DetectionResult {
 	// Use debug/buildinfo to check if it's a Go binary
-	if isGo, version, _ := g.elfAnalyzer.IsGoBinary(ctx.Executable); isGo {
-		// Filter false positives (e.g., Dynatrace wrappers)
-		if !strings.Contains(strings.ToLower(ctx.Cmdline), "dynatrace") {
+	if isGo, version, deps, _ := g.elfAnalyzer.IsGoBinary(ctx.Executable); isGo {
+		// Filter false positives from Go-based APM wrappers (e.g., Dynatrace)
+		if DetectAgent(ctx) == "" {
 			return &DetectionResult{
 				Language:   LanguageGo,
-				Framework:  "",
+				Framework:  g.detectFramework(deps),
 				Version:    g.cleanVersion(version),
-				Confidence: "high",
+				Confidence: ConfidenceHigh,
 			}
 		}
 	}
@@ -43,7 +43,7 @@ func (g *GoInspector) QuickScan(ctx *process.ProcessContext) *DetectionResult {
 				Language:   LanguageGo,
 				Framework:  "",
 				Version:    g.extractVersion(ctx),
-				Confidence: "medium",
+				Confidence: ConfidenceMedium,
 			}
 		}
 	}
@@ -52,10 +52,69 @@ func (g *GoInspector) QuickScan(ctx *process.ProcessContext) *DetectionResult {
 }
 
 func (g *GoInspector) DeepScan(ctx *process.ProcessContext) *DetectionResult {
-	// Deep scan not needed for Go - buildinfo check in QuickScan is sufficient
+	exe := process.ExecutableFile(ctx)
+
+	// Binaries built by Bazel (rules_go) may lack debug/buildinfo, so QuickScan misses them.
+	// They still carry the go:buildid note, pclntab and runtime symbols.
+	if hasGo, _ := g.elfAnalyzer.HasGoRuntimeMarkers(exe); hasGo {
+		if DetectAgent(ctx) == "" {
+			return &DetectionResult{
+				Language:   LanguageGo,
+				Framework:  "",
+				Version:    g.extractVersion(ctx),
+				Confidence: ConfidenceHigh,
+			}
+		}
+	}
+
+	// Fully stripped binaries (e.g. sstrip, or packers that drop section headers) still
+	// carry runtime function names in their pclntab, which is less conclusive
+	if hasGo, _ := g.elfAnalyzer.HasGoRuntimeStrings(exe); hasGo {
+		if DetectAgent(ctx) == "" {
+			return &DetectionResult{
+				Language:   LanguageGo,
+				Framework:  "",
+				Version:    g.extractVersion(ctx),
+				Confidence: ConfidenceMedium,
+			}
+		}
+	}
+
 	return nil
 }
 
+// goFrameworks maps web framework module paths (without a major version suffix) to the
+// framework name, in precedence order for binaries linking more than one
+var goFrameworks = []struct {
+	Module    string
+	Framework string
+}{
+	{"github.com/gin-gonic/gin", "Gin"},
+	{"github.com/labstack/echo", "Echo"},
+	{"github.com/gofiber/fiber", "Fiber"},
+	{"github.com/go-chi/chi", "Chi"},
+	{"github.com/gorilla/mux", "Gorilla Mux"},
+	{"github.com/beego/beego", "Beego"},
+}
+
+// goModuleMajorRegex matches the major version suffix of a module path (e.g. "/v4")
+var goModuleMajorRegex = regexp.MustCompile(`/v\d+$`)
+
+// detectFramework returns the web framework among a binary's dependency module paths, or ""
+func (g *GoInspector) detectFramework(deps []string) string {
+	modules := make(map[string]bool, len(deps))
+	for _, dep := range deps {
+		modules[goModuleMajorRegex.ReplaceAllString(dep, "")] = true
+	}
+
+	for _, candidate := range goFrameworks {
+		if modules[candidate.Module] {
+			return candidate.Framework
+		}
+	}
+	return ""
+}
+
 func (g *GoInspector) cleanVersion(version string) string {
 	// Extract version from "go1.21.3" -> "1.21.3"
 	versionRegex := regexp.MustCompile(`go(\d+\.\d+\.?\d*)`)
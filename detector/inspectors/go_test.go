@@ -0,0 +1,115 @@
+package inspectors
+
+import (
+	"debug/elf"
+	"runtime/debug"
+	"testing"
+
+	"github.com/kloudmate/polylang-detector/detector/process"
+	"github.com/kloudmate/polylang-detector/internal/elftest"
+)
+
+func TestGoInspectorDetectsBazelBinaryWithoutBuildinfo(t *testing.T) {
+	// rules_go binaries carry the buildid note and runtime symbols but no .go.buildinfo
+	exe := elftest.Write(t, "bazel-server", elftest.Options{
+		Sections: []elftest.Section{
+			{Name: ".note.go.buildid", Type: elf.SHT_NOTE, Data: []byte("Go\x00\x00bazel-build-id")},
+		},
+		Symbols: []string{"runtime.goexit", "main.main"},
+	})
+
+	inspector := NewGoInspector()
+	ctx := &process.ProcessContext{PID: -1, Executable: exe, Cmdline: exe}
+
+	if result := inspector.QuickScan(ctx); result != nil {
+		t.Fatalf("expected buildinfo-based QuickScan to miss the Bazel binary, got %+v", result)
+	}
+
+	result := inspector.DeepScan(ctx)
+	if result == nil || result.Language != LanguageGo {
+		t.Fatalf("expected DeepScan to detect Go, got %+v", result)
+	}
+}
+
+func TestGoInspectorDeepScanIgnoresNonGoBinary(t *testing.T) {
+	exe := elftest.Write(t, "c-server", elftest.Options{
+		Sections: []elftest.Section{{Name: ".rodata", Type: elf.SHT_PROGBITS, Data: []byte("hello")}},
+		Symbols:  []string{"main", "printf"},
+	})
+
+	if result := NewGoInspector().DeepScan(&process.ProcessContext{PID: -1, Executable: exe}); result != nil {
+		t.Errorf("expected no detection for non-Go binary, got %+v", result)
+	}
+}
+
+func TestGoInspectorDetectsStrippedBinaryFromPclntab(t *testing.T) {
+	// A stripped binary has no Go sections or symbols left, only the pclntab contents
+	exe := elftest.Write(t, "stripped-server", elftest.Options{
+		Sections: []elftest.Section{
+			{Name: ".gopclntab", Type: elf.SHT_PROGBITS, Data: []byte("\x00runtime.mstart\x00runtime.morestack\x00runtime.gopanic\x00runtime.goexit\x00main.main\x00")},
+		},
+		StripSectionHeaders: true,
+	})
+
+	ctx := &process.ProcessContext{PID: -1, Executable: exe, Cmdline: exe}
+	if result := NewGoInspector().QuickScan(ctx); result != nil {
+		t.Fatalf("expected QuickScan to miss the stripped binary, got %+v", result)
+	}
+
+	result := NewGoInspector().DeepScan(ctx)
+	if result == nil || result.Language != LanguageGo {
+		t.Fatalf("expected DeepScan to detect Go, got %+v", result)
+	}
+	if result.Confidence != ConfidenceMedium {
+		t.Errorf("expected medium confidence from the pclntab fallback, got %s", result.Confidence)
+	}
+}
+
+func TestGoInspectorDeepScanIgnoresBinaryMentioningGoRuntime(t *testing.T) {
+	// e.g. a debugger or profiler that knows the names of some Go runtime functions
+	exe := elftest.Write(t, "debugger", elftest.Options{
+		Sections: []elftest.Section{
+			{Name: ".rodata", Type: elf.SHT_PROGBITS, Data: []byte("\x00runtime.goexit\x00runtime.gopanic\x00")},
+		},
+		Symbols: []string{"main"},
+	})
+
+	if result := NewGoInspector().DeepScan(&process.ProcessContext{PID: -1, Executable: exe}); result != nil {
+		t.Errorf("expected no detection for a binary only mentioning Go runtime functions, got %+v", result)
+	}
+}
+
+func TestGoInspectorDetectsFrameworkFromBuildinfoDeps(t *testing.T) {
+	tests := []struct {
+		name      string
+		deps      []string
+		framework string
+	}{
+		{"gin-server", []string{"github.com/bytedance/sonic", "github.com/gin-gonic/gin"}, "Gin"},
+		{"echo-server", []string{"github.com/labstack/echo/v4", "github.com/labstack/gommon"}, "Echo"},
+		{"fiber-server", []string{"github.com/gofiber/fiber/v2", "github.com/valyala/fasthttp"}, "Fiber"},
+		{"plain-server", []string{"go.uber.org/zap"}, ""},
+	}
+
+	for _, tt := range tests {
+		info := &debug.BuildInfo{
+			GoVersion: "go1.22.4",
+			Path:      "example.com/" + tt.name,
+			Main:      debug.Module{Path: "example.com/" + tt.name, Version: "(devel)"},
+		}
+		for _, dep := range tt.deps {
+			info.Deps = append(info.Deps, &debug.Module{Path: dep, Version: "v1.0.0"})
+		}
+		exe := elftest.Write(t, tt.name, elftest.Options{
+			Sections: []elftest.Section{elftest.GoBuildInfo(info)},
+		})
+
+		result := NewGoInspector().QuickScan(&process.ProcessContext{PID: -1, Executable: exe, Cmdline: exe})
+		if result == nil || result.Language != LanguageGo {
+			t.Fatalf("%s: expected Go, got %+v", tt.name, result)
+		}
+		if result.Framework != tt.framework || result.Version != "1.22.4" {
+			t.Errorf("%s: expected %q on Go 1.22.4, got %q on Go %s", tt.name, tt.framework, result.Framework, result.Version)
+		}
+	}
+}
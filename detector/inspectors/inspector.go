@@ -1,6 +1,8 @@
 package inspectors
 
 import (
+	"sync"
+
 	"github.com/kloudmate/polylang-detector/detector/process"
 )
 
@@ -16,15 +18,37 @@ const (
 	LanguagePHP     Language = "PHP"
 	LanguageRuby    Language = "Ruby"
 	LanguageRust    Language = "Rust"
+	LanguageWasm    Language = "WASM"
 	LanguageUnknown Language = "Unknown"
 )
 
 // DetectionResult contains the result of language detection
 type DetectionResult struct {
-	Language   Language
-	Framework  string
-	Version    string
-	Confidence string // "high", "medium", "low"
+	Language   Language   `json:"language"`
+	Framework  string     `json:"framework,omitempty"`
+	Version    string     `json:"version,omitempty"`
+	Confidence Confidence `json:"confidence"`
+	// FrameworkVersion is the version of Framework when it can be determined (e.g. "3.2.1")
+	FrameworkVersion string `json:"framework_version,omitempty"`
+	// AppServer is the application server hosting the framework (e.g. "Uvicorn", "Gunicorn")
+	AppServer string `json:"app_server,omitempty"`
+	// RuntimeMode is how a Java workload runs: "jvm", or "native" for a GraalVM native-image build
+	RuntimeMode string `json:"runtime_mode,omitempty"`
+	// ProcessRole is "master" or "worker" for a process of a pre-fork server (e.g. Gunicorn)
+	ProcessRole string `json:"process_role,omitempty"`
+	// WorkerCount is the number of workers a pre-fork server was configured with, when known
+	WorkerCount int `json:"worker_count,omitempty"`
+	// WebappContexts lists the context paths of the webapps deployed to a Tomcat or Jetty
+	// server (e.g. "/", "/shop")
+	WebappContexts []string `json:"webapp_contexts,omitempty"`
+	// GuestLanguage is the language a WebAssembly module was compiled from (WASM only)
+	GuestLanguage Language `json:"guest_language,omitempty"`
+	// AgentDetected names an APM agent or wrapper attached to the process (e.g. "Datadog")
+	AgentDetected string `json:"agent_detected,omitempty"`
+	// Agents lists the agent jars attached with -javaagent (Java only)
+	Agents []string `json:"agents,omitempty"`
+	// AlreadyInstrumented is set when an OpenTelemetry agent is already attached
+	AlreadyInstrumented bool `json:"already_instrumented,omitempty"`
 }
 
 // LanguageInspector defines the interface for language detection
@@ -39,14 +63,37 @@ type LanguageInspector interface {
 	GetLanguage() Language
 }
 
+var (
+	customInspectorsMu sync.RWMutex
+	customInspectors   []LanguageInspector
+)
+
+// RegisterInspector adds a custom inspector, e.g. for an in-house runtime, to every
+// LanguageDetector created afterwards. Register inspectors during initialization,
+// before any detector is constructed; existing detectors are not affected.
+// Custom inspectors run after the built-ins in registration order, and take part in
+// QuickScan, DeepScan, and conflict resolution exactly like the built-in ones.
+func RegisterInspector(inspector LanguageInspector) {
+	customInspectorsMu.Lock()
+	defer customInspectorsMu.Unlock()
+	customInspectors = append(customInspectors, inspector)
+}
+
 // AllInspectors returns all available language inspectors
-// Only monitoring: .NET, Java, Node.js, Python, and Go
+// Built-in monitoring: .NET, Java, Node.js, Python, Go, PHP, Rust, and WebAssembly runtimes, followed by registered custom inspectors
 func AllInspectors() []LanguageInspector {
-	return []LanguageInspector{
+	all := []LanguageInspector{
 		NewJavaInspector(),
 		NewPythonInspector(),
 		NewNodeJSInspector(),
 		NewGoInspector(),
 		NewDotNetInspector(),
+		NewPHPInspector(),
+		NewRustInspector(),
+		NewWasmInspector(),
 	}
+
+	customInspectorsMu.RLock()
+	defer customInspectorsMu.RUnlock()
+	return append(all, customInspectors...)
 }
@@ -0,0 +1,87 @@
+package inspectors
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/kloudmate/polylang-detector/detector/process"
+)
+
+const languageElixir Language = "Elixir"
+
+// beamInspector detects a synthetic language from the BEAM VM executable
+type beamInspector struct{}
+
+func (beamInspector) GetLanguage() Language { return languageElixir }
+
+func (beamInspector) QuickScan(ctx *process.ProcessContext) *DetectionResult {
+	if strings.Contains(ctx.Executable, "beam.smp") {
+		return &DetectionResult{Language: languageElixir, Confidence: ConfidenceHigh}
+	}
+	return nil
+}
+
+func (beamInspector) DeepScan(*process.ProcessContext) *DetectionResult { return nil }
+
+// registerTestInspector registers inspector and removes it when the test finishes
+func registerTestInspector(t *testing.T, inspector LanguageInspector) {
+	t.Helper()
+
+	customInspectorsMu.RLock()
+	previous := customInspectors
+	customInspectorsMu.RUnlock()
+
+	RegisterInspector(inspector)
+	t.Cleanup(func() {
+		customInspectorsMu.Lock()
+		customInspectors = previous
+		customInspectorsMu.Unlock()
+	})
+}
+
+func TestRegisterInspectorDetectsCustomLanguage(t *testing.T) {
+	registerTestInspector(t, beamInspector{})
+
+	ctx := &process.ProcessContext{PID: -1, Executable: "/usr/lib/erlang/erts-14/bin/beam.smp"}
+	result, err := NewLanguageDetector().Detect(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Language != languageElixir {
+		t.Errorf("expected %s, got %s", languageElixir, result.Language)
+	}
+}
+
+func TestRegisteredInspectorTakesPartInConflicts(t *testing.T) {
+	registerTestInspector(t, beamInspector{})
+
+	// A process matching both a built-in and the custom inspector is a conflict
+	ctx := &process.ProcessContext{PID: -1, Executable: "/opt/beam.smp/bin/java", Cmdline: "java -jar app.jar"}
+	if _, err := NewLanguageDetector().Detect(ctx); err == nil {
+		t.Fatal("expected a conflict between Java and the custom inspector")
+	} else if _, ok := err.(*ErrLanguageDetectionConflict); !ok {
+		t.Errorf("expected ErrLanguageDetectionConflict, got %v", err)
+	}
+}
+
+func TestDetectionResultJSONFieldNames(t *testing.T) {
+	result := DetectionResult{Language: LanguagePython, Framework: "FastAPI", Confidence: ConfidenceHigh, AppServer: "Uvicorn"}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	want := `{"language":"Python","framework":"FastAPI","confidence":"high","app_server":"Uvicorn"}`
+	if string(data) != want {
+		t.Errorf("expected %s, got %s", want, data)
+	}
+
+	var decoded DetectionResult
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if decoded.Confidence != ConfidenceHigh {
+		t.Errorf("expected high confidence after a round trip, got %v", decoded.Confidence)
+	}
+}
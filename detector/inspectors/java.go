@@ -1,17 +1,27 @@
 package inspectors
 
 import (
+	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"unicode"
 
 	"github.com/kloudmate/polylang-detector/detector/process"
 )
 
-type JavaInspector struct{}
+// springBootLibRegex matches the core Spring Boot library nested in a fat jar
+var springBootLibRegex = regexp.MustCompile(`^spring-boot-(\d+\.\d+\.\d+[\w.-]*)\.jar$`)
+
+type JavaInspector struct {
+	elfAnalyzer *process.ELFAnalyzer
+}
 
 func NewJavaInspector() *JavaInspector {
-	return &JavaInspector{}
+	return &JavaInspector{
+		elfAnalyzer: process.NewELFAnalyzer(),
+	}
 }
 
 func (j *JavaInspector) GetLanguage() Language {
@@ -24,26 +34,14 @@ func (j *JavaInspector) QuickScan(ctx *process.ProcessContext) *DetectionResult
 
 	// Check if process name is "java"
 	if exeName == "java" {
-		framework := j.detectFramework(ctx)
-		version := j.extractVersion(ctx)
-		return &DetectionResult{
-			Language:   LanguageJava,
-			Framework:  framework,
-			Version:    version,
-			Confidence: "high",
-		}
+		return j.jvmResult(ctx, ConfidenceHigh)
 	}
 
 	// Check for common Java patterns in command line
 	javaPatterns := []string{"openjdk", "java -jar", "javac", "jre", "jdk"}
 	for _, pattern := range javaPatterns {
 		if strings.Contains(cmdlineLower, pattern) {
-			return &DetectionResult{
-				Language:   LanguageJava,
-				Framework:  j.detectFramework(ctx),
-				Version:    j.extractVersion(ctx),
-				Confidence: "medium",
-			}
+			return j.jvmResult(ctx, ConfidenceMedium)
 		}
 	}
 
@@ -51,26 +49,244 @@ func (j *JavaInspector) QuickScan(ctx *process.ProcessContext) *DetectionResult
 }
 
 func (j *JavaInspector) DeepScan(ctx *process.ProcessContext) *DetectionResult {
-	// Read memory maps
-	mapsFile, err := process.ReadMapsFile(ctx.PID)
-	if err != nil {
-		return nil
+	// Check memory maps for JVM libraries
+	jvmLibraries := []string{"libjvm.so", "libjava.so"}
+	if mapsFile, err := ctx.MapsFile(); err == nil && process.ContainsBinary(mapsFile, jvmLibraries) {
+		return j.jvmResult(ctx, ConfidenceHigh)
 	}
 
-	// Check for JVM libraries
-	jvmLibraries := []string{"libjvm.so", "libjava.so"}
-	if process.ContainsBinary(mapsFile, jvmLibraries) {
+	// GraalVM native-image binaries are native executables without a JVM, recognized
+	// only by scanning the binary, so this runs after the cheap maps check
+	if isNative, _ := j.elfAnalyzer.HasGraalVMNativeImageMarkers(process.ExecutableFile(ctx)); isNative {
+		framework := j.nativeImageFramework(ctx)
+		if framework == "" {
+			framework = "GraalVM native-image"
+		}
 		return &DetectionResult{
-			Language:   LanguageJava,
-			Framework:  j.detectFramework(ctx),
-			Version:    j.extractVersion(ctx),
-			Confidence: "high",
+			Language:    LanguageJava,
+			Framework:   framework,
+			Version:     j.extractVersion(ctx),
+			Confidence:  ConfidenceHigh,
+			RuntimeMode: "native",
 		}
 	}
 
 	return nil
 }
 
+// jvmResult builds a detection result for a JVM process. A Spring Boot executable jar
+// identifies the framework and its version even when the command line doesn't mention it.
+func (j *JavaInspector) jvmResult(ctx *process.ProcessContext, confidence Confidence) *DetectionResult {
+	result := &DetectionResult{
+		Language:    LanguageJava,
+		Framework:   j.detectFramework(ctx),
+		Version:     j.extractVersion(ctx),
+		Confidence:  confidence,
+		RuntimeMode: "jvm",
+	}
+	if bootVersion := j.springBootVersion(ctx); bootVersion != "" {
+		result.Framework = "Spring Boot"
+		result.FrameworkVersion = bootVersion
+	}
+	if result.Framework == "Tomcat" || result.Framework == "Jetty" {
+		result.WebappContexts = webappContexts(ctx, result.Framework)
+	}
+	result.Agents = javaAgents(ctx)
+	for _, agent := range result.Agents {
+		if isOtelJavaAgent(ctx, agent) {
+			result.AlreadyInstrumented = true
+		}
+	}
+	return result
+}
+
+// javaAgentOptionVars are the environment variables the JVM and launcher read extra
+// options from, in the order they are applied before the command line
+var javaAgentOptionVars = []string{"JAVA_TOOL_OPTIONS", "JDK_JAVA_OPTIONS"}
+
+// javaAgents returns the jar paths passed with -javaagent:<jar>[=options] through
+// JAVA_TOOL_OPTIONS, JDK_JAVA_OPTIONS or the command line
+func javaAgents(ctx *process.ProcessContext) []string {
+	var args []string
+	for _, name := range javaAgentOptionVars {
+		args = append(args, strings.Fields(ctx.Environ[name])...)
+	}
+	args = append(args, strings.Fields(ctx.Cmdline)...)
+
+	var agents []string
+	for _, arg := range args {
+		jar, found := strings.CutPrefix(arg, "-javaagent:")
+		if !found {
+			continue
+		}
+		jar, _, _ = strings.Cut(jar, "=")
+		if jar != "" && !slices.Contains(agents, jar) {
+			agents = append(agents, jar)
+		}
+	}
+	return agents
+}
+
+// otelAgentPremainPrefix is the package of the OpenTelemetry Java agent's Premain-Class,
+// which its distributions keep
+const otelAgentPremainPrefix = "io.opentelemetry.javaagent."
+
+// isOtelJavaAgent reports whether an agent jar is the OpenTelemetry Java agent (or a
+// distribution of it), meaning the JVM is already instrumented. The jar's path is matched
+// by whole words ("otel", "opentelemetry"), so the operator's
+// /otel-auto-instrumentation-java/javaagent.jar matches and hotel-booking.jar does not;
+// otherwise the jar's manifest is read.
+func isOtelJavaAgent(ctx *process.ProcessContext, jar string) bool {
+	words := strings.FieldsFunc(strings.ToLower(jar), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if slices.Contains(words, "otel") || slices.Contains(words, "opentelemetry") {
+		return true
+	}
+
+	agent, err := process.ReadJar(process.ContainerFilePath(ctx, jar))
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(agent.Manifest["Premain-Class"], otelAgentPremainPrefix)
+}
+
+// springBootVersion returns the Spring Boot version of the jar the process was started
+// with, from the Spring-Boot-Version manifest attribute or the nested spring-boot library
+func (j *JavaInspector) springBootVersion(ctx *process.ProcessContext) string {
+	jarPath := process.ExecutableJar(ctx)
+	if jarPath == "" {
+		return ""
+	}
+	jar, err := process.ReadJar(jarPath)
+	if err != nil {
+		return ""
+	}
+
+	if version := jar.Manifest["Spring-Boot-Version"]; version != "" {
+		return version
+	}
+	for _, lib := range jar.Libs {
+		if matches := springBootLibRegex.FindStringSubmatch(lib); matches != nil {
+			return matches[1]
+		}
+	}
+	return ""
+}
+
+// servletServerBases lists, per servlet container, the environment variables and system
+// properties naming its base directory, most specific first
+var servletServerBases = map[string]struct {
+	Env        []string
+	Properties []string
+}{
+	"Tomcat": {[]string{"CATALINA_BASE", "CATALINA_HOME"}, []string{"catalina.base", "catalina.home"}},
+	"Jetty":  {[]string{"JETTY_BASE", "JETTY_HOME"}, []string{"jetty.base", "jetty.home"}},
+}
+
+// webappContexts returns the context paths of the webapps deployed to a Tomcat or Jetty
+// server, from the .war files and exploded directories in its webapps directory
+func webappContexts(ctx *process.ProcessContext, server string) []string {
+	base := servletServerBase(ctx, server)
+	if base == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(process.ContainerFilePath(ctx, filepath.Join(base, "webapps")))
+	if err != nil {
+		return nil
+	}
+
+	var contexts []string
+	for _, entry := range entries {
+		name := entry.Name()
+		switch {
+		case strings.HasPrefix(name, "."):
+			continue
+		case entry.IsDir():
+			// Jetty keeps per-webapp overrides in <name>.d directories
+			if strings.HasSuffix(name, ".d") {
+				continue
+			}
+		case strings.HasSuffix(name, ".war"):
+			name = strings.TrimSuffix(name, ".war")
+		default:
+			continue
+		}
+		if context := webappContextPath(name); !slices.Contains(contexts, context) {
+			contexts = append(contexts, context)
+		}
+	}
+	slices.Sort(contexts)
+	return contexts
+}
+
+// servletServerBase returns the base directory of a servlet container from its -D system
+// properties on the command line, or else its environment
+func servletServerBase(ctx *process.ProcessContext, server string) string {
+	bases := servletServerBases[server]
+	for _, property := range bases.Properties {
+		for _, arg := range strings.Fields(ctx.Cmdline) {
+			if value, found := strings.CutPrefix(arg, "-D"+property+"="); found && value != "" {
+				return value
+			}
+		}
+	}
+	for _, key := range bases.Env {
+		if value := ctx.Environ[key]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// webappContextPath maps a webapp's base name to the context path it is served on, following
+// Tomcat's naming: ROOT (or Jetty's root) is "/", "#" separates path segments, and a
+// "##version" suffix marks a parallel deployment of the same context
+func webappContextPath(name string) string {
+	name, _, _ = strings.Cut(name, "##")
+	if name == "ROOT" || name == "root" {
+		return "/"
+	}
+	return "/" + strings.ReplaceAll(name, "#", "/")
+}
+
+// nativeImageFrameworks maps frameworks with native-image support to the package names
+// their build embeds in the image heap
+var nativeImageFrameworks = []struct {
+	Framework string
+	Markers   []string
+}{
+	{"Quarkus", []string{"io.quarkus"}},
+	{"Micronaut", []string{"io.micronaut"}},
+	{"Spring Boot", []string{"org.springframework.boot"}},
+}
+
+// nativeImageFramework identifies the framework a GraalVM native image was built from,
+// by its command line (e.g. -Dquarkus.http.port) or the package names in its image heap,
+// which are searched for in a single pass
+func (j *JavaInspector) nativeImageFramework(ctx *process.ProcessContext) string {
+	if framework := j.detectFramework(ctx); framework != "" {
+		return framework
+	}
+
+	var markers []string
+	var frameworks []string
+	for _, candidate := range nativeImageFrameworks {
+		for _, marker := range candidate.Markers {
+			markers = append(markers, marker)
+			frameworks = append(frameworks, candidate.Framework)
+		}
+	}
+
+	found, _ := process.ScanMarkers(process.ExecutableFile(ctx), process.GraalImageHeapSections, markers, process.MaxMarkerScanBytes)
+	for i, ok := range found {
+		if ok {
+			return frameworks[i]
+		}
+	}
+	return ""
+}
+
 func (j *JavaInspector) detectFramework(ctx *process.ProcessContext) string {
 	cmdlineLower := strings.ToLower(ctx.Cmdline)
 
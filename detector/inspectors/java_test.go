@@ -0,0 +1,311 @@
+package inspectors
+
+import (
+	"archive/zip"
+	"debug/elf"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/kloudmate/polylang-detector/detector/process"
+	"github.com/kloudmate/polylang-detector/internal/elftest"
+	"github.com/kloudmate/polylang-detector/internal/proctest"
+)
+
+func TestJavaInspectorDetectsGraalVMNativeImage(t *testing.T) {
+	exe := elftest.Write(t, "orders-service", elftest.Options{
+		Sections: []elftest.Section{
+			{Name: ".rodata", Type: elf.SHT_PROGBITS, Data: []byte("\x00com.oracle.svm.core.JavaMainWrapper\x00")},
+		},
+		Symbols: []string{"main"},
+	})
+
+	ctx := &process.ProcessContext{PID: -1, Executable: exe, Cmdline: exe}
+	result, err := NewLanguageDetector().Detect(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Language != LanguageJava || result.Framework != "GraalVM native-image" {
+		t.Errorf("expected Java (GraalVM native-image), got %s (%s)", result.Language, result.Framework)
+	}
+	if result.Confidence != ConfidenceHigh {
+		t.Errorf("expected high confidence, got %s", result.Confidence)
+	}
+}
+
+func TestJavaInspectorReportsNativeAndJVMRuntimeMode(t *testing.T) {
+	nativeImage := func(name, heap string) string {
+		return elftest.Write(t, name, elftest.Options{
+			Sections: []elftest.Section{
+				{Name: ".svm_heap", Type: elf.SHT_PROGBITS, Data: []byte("\x00" + heap + "\x00")},
+			},
+			Symbols: []string{"main"},
+		})
+	}
+
+	tests := []struct {
+		name      string
+		ctx       *process.ProcessContext
+		framework string
+		mode      string
+	}{
+		{
+			name:      "quarkus native",
+			ctx:       &process.ProcessContext{PID: -1, Executable: nativeImage("orders-runner", "io.quarkus.runtime.ApplicationLifecycleManager")},
+			framework: "Quarkus",
+			mode:      "native",
+		},
+		{
+			name:      "micronaut native",
+			ctx:       &process.ProcessContext{PID: -1, Executable: nativeImage("inventory", "io.micronaut.runtime.Micronaut")},
+			framework: "Micronaut",
+			mode:      "native",
+		},
+		{
+			name:      "quarkus jvm",
+			ctx:       &process.ProcessContext{PID: -1, Executable: "/usr/bin/java", Cmdline: "java -Dquarkus.http.host=0.0.0.0 -jar /deployments/quarkus-run.jar"},
+			framework: "Quarkus",
+			mode:      "jvm",
+		},
+		{
+			name:      "micronaut jvm",
+			ctx:       &process.ProcessContext{PID: -1, Executable: "/usr/bin/java", Cmdline: "java -Dmicronaut.environments=prod -jar /app/inventory-all.jar"},
+			framework: "Micronaut",
+			mode:      "jvm",
+		},
+	}
+
+	for _, tt := range tests {
+		if tt.ctx.Cmdline == "" {
+			tt.ctx.Cmdline = tt.ctx.Executable
+		}
+		result, err := NewLanguageDetector().Detect(tt.ctx)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if result.Language != LanguageJava || result.Framework != tt.framework || result.RuntimeMode != tt.mode {
+			t.Errorf("%s: expected Java/%s/%s, got %s/%s/%s", tt.name, tt.framework, tt.mode, result.Language, result.Framework, result.RuntimeMode)
+		}
+	}
+}
+
+func TestJavaInspectorDetectsSpringBootVersionFromJar(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries map[string]string
+	}{
+		{
+			name:    "manifest attribute",
+			entries: map[string]string{"META-INF/MANIFEST.MF": "Manifest-Version: 1.0\r\nMain-Class: org.springframework.boot.loader.launch.JarLauncher\r\nSpring-Boot-Version: 3.2.1\r\n\r\n"},
+		},
+		{
+			name: "nested library",
+			entries: map[string]string{
+				"META-INF/MANIFEST.MF":               "Manifest-Version: 1.0\n",
+				"BOOT-INF/lib/spring-core-6.1.2.jar": "",
+				"BOOT-INF/lib/spring-boot-3.2.1.jar": "",
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := proctest.New(t)
+
+			pid := 40 + i
+			proctest.WriteProcess(t, root, proctest.Process{PID: pid, PPID: 1, Exe: "/usr/bin/java", Cmdline: "java\x00-jar\x00/app/app.jar\x00"})
+			writeJar(t, filepath.Join(root, strconv.Itoa(pid), "root", "app", "app.jar"), tt.entries)
+
+			ctx, err := process.GetProcessContext(pid)
+			if err != nil {
+				t.Fatalf("failed to read process: %v", err)
+			}
+			result := NewJavaInspector().QuickScan(ctx)
+			if result == nil || result.Framework != "Spring Boot" || result.FrameworkVersion != "3.2.1" {
+				t.Errorf("expected Spring Boot 3.2.1, got %+v", result)
+			}
+		})
+	}
+}
+
+// writeJar creates a zip archive at path containing the given entries
+func writeJar(t *testing.T, path string, entries map[string]string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("failed to create jar dir: %v", err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed to create jar: %v", err)
+	}
+	defer file.Close()
+
+	zw := zip.NewWriter(file)
+	for name, content := range entries {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("failed to add %s: %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("failed to finish jar: %v", err)
+	}
+}
+
+func TestJavaInspectorReportsJavaAgents(t *testing.T) {
+	tests := []struct {
+		name         string
+		cmdline      string
+		environ      map[string]string
+		agents       []string
+		instrumented bool
+	}{
+		{
+			name:    "single profiler agent",
+			cmdline: "java -javaagent:/opt/pyroscope/pyroscope.jar -jar /app/app.jar",
+			agents:  []string{"/opt/pyroscope/pyroscope.jar"},
+		},
+		{
+			name:         "multiple agents including OpenTelemetry",
+			cmdline:      "java -javaagent:/otel/opentelemetry-javaagent.jar -javaagent:/dd/dd-java-agent.jar=service=orders -jar /app/app.jar",
+			agents:       []string{"/otel/opentelemetry-javaagent.jar", "/dd/dd-java-agent.jar"},
+			instrumented: true,
+		},
+		{
+			name:         "operator-injected agent in JAVA_TOOL_OPTIONS",
+			cmdline:      "java -jar /app/app.jar",
+			environ:      map[string]string{"JAVA_TOOL_OPTIONS": " -javaagent:/otel-auto-instrumentation-java/javaagent.jar"},
+			agents:       []string{"/otel-auto-instrumentation-java/javaagent.jar"},
+			instrumented: true,
+		},
+		{
+			name:    "agent in JDK_JAVA_OPTIONS and on the command line",
+			cmdline: "java -javaagent:/opt/hotel-metrics.jar -jar /app/app.jar",
+			environ: map[string]string{"JDK_JAVA_OPTIONS": "-javaagent:/opt/hotel-metrics.jar"},
+			agents:  []string{"/opt/hotel-metrics.jar"},
+		},
+		{
+			name:    "no agents",
+			cmdline: "java -jar /app/app.jar",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &process.ProcessContext{PID: -1, Executable: "/usr/bin/java", Cmdline: tt.cmdline, Environ: tt.environ}
+			result := NewJavaInspector().QuickScan(ctx)
+			if result == nil {
+				t.Fatal("expected a Java result")
+			}
+			if !slices.Equal(result.Agents, tt.agents) {
+				t.Errorf("expected agents %v, got %v", tt.agents, result.Agents)
+			}
+			if result.AlreadyInstrumented != tt.instrumented {
+				t.Errorf("expected already instrumented %v, got %v", tt.instrumented, result.AlreadyInstrumented)
+			}
+		})
+	}
+}
+
+func TestJavaInspectorRecognizesRenamedOtelAgentByManifest(t *testing.T) {
+	root := proctest.New(t)
+
+	pid := 60
+	proctest.WriteProcess(t, root, proctest.Process{PID: pid, PPID: 1, Exe: "/usr/bin/java", Cmdline: "java\x00-javaagent:/agents/agent.jar\x00-jar\x00/app/app.jar\x00"})
+	writeJar(t, filepath.Join(root, strconv.Itoa(pid), "root", "agents", "agent.jar"), map[string]string{
+		"META-INF/MANIFEST.MF": "Manifest-Version: 1.0\r\nPremain-Class: io.opentelemetry.javaagent.OpenTelemetryAgent\r\n\r\n",
+	})
+
+	ctx, err := process.GetProcessContext(pid)
+	if err != nil {
+		t.Fatalf("failed to read process: %v", err)
+	}
+	result := NewJavaInspector().QuickScan(ctx)
+	if result == nil || !result.AlreadyInstrumented {
+		t.Errorf("expected the renamed OpenTelemetry agent to mark the JVM instrumented, got %+v", result)
+	}
+}
+
+func TestJavaInspectorListsServletWebappContexts(t *testing.T) {
+	root := proctest.New(t)
+
+	// writeWebapps creates a fake webapps listing in the container root of pid
+	writeWebapps := func(pid int, dir string, files, dirs []string) {
+		webapps := filepath.Join(root, strconv.Itoa(pid), "root", dir)
+		for _, name := range dirs {
+			if err := os.MkdirAll(filepath.Join(webapps, name), 0o755); err != nil {
+				t.Fatalf("failed to create %s: %v", name, err)
+			}
+		}
+		for _, name := range files {
+			if err := os.MkdirAll(webapps, 0o755); err != nil {
+				t.Fatalf("failed to create webapps: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(webapps, name), nil, 0o644); err != nil {
+				t.Fatalf("failed to write %s: %v", name, err)
+			}
+		}
+	}
+
+	tests := []struct {
+		name      string
+		pid       int
+		cmdline   string
+		environ   map[string]string
+		framework string
+		contexts  []string
+	}{
+		{
+			name:      "tomcat base from system property",
+			pid:       60,
+			cmdline:   "/opt/java/openjdk/bin/java -Dcatalina.base=/usr/local/tomcat -Dcatalina.home=/usr/local/tomcat org.apache.catalina.startup.Bootstrap start",
+			framework: "Tomcat",
+			contexts:  []string{"/", "/api/v2", "/shop"},
+		},
+		{
+			name:      "tomcat base from CATALINA_BASE",
+			pid:       61,
+			cmdline:   "java org.apache.catalina.startup.Bootstrap start",
+			environ:   map[string]string{"CATALINA_BASE": "/usr/local/tomcat"},
+			framework: "Tomcat",
+			contexts:  []string{"/", "/api/v2", "/shop"},
+		},
+		{
+			name:      "jetty base",
+			pid:       62,
+			cmdline:   "java -jar /usr/local/jetty/start.jar",
+			environ:   map[string]string{"JETTY_BASE": "/var/lib/jetty"},
+			framework: "Jetty",
+			contexts:  []string{"/", "/orders"},
+		},
+		{
+			name:      "tomcat without a known base",
+			pid:       63,
+			cmdline:   "java org.apache.catalina.startup.Bootstrap start",
+			framework: "Tomcat",
+		},
+	}
+
+	for _, tt := range tests {
+		// ROOT and shop are deployed both as a war and exploded; api#v2##002 is a versioned nested context
+		writeWebapps(tt.pid, "usr/local/tomcat/webapps", []string{"ROOT.war", "shop.war", "api#v2##002.war", "README.txt"}, []string{"ROOT", "shop", ".hidden"})
+		writeWebapps(tt.pid, "var/lib/jetty/webapps", []string{"root.war", "orders.war", "orders.xml"}, []string{"orders.d"})
+
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &process.ProcessContext{PID: tt.pid, Executable: "/opt/java/openjdk/bin/java", Cmdline: tt.cmdline, Environ: tt.environ}
+			result := NewJavaInspector().QuickScan(ctx)
+			if result == nil || result.Framework != tt.framework {
+				t.Fatalf("expected %s, got %+v", tt.framework, result)
+			}
+			if !slices.Equal(result.WebappContexts, tt.contexts) {
+				t.Errorf("expected contexts %v, got %v", tt.contexts, result.WebappContexts)
+			}
+		})
+	}
+}
@@ -8,10 +8,14 @@ import (
 	"github.com/kloudmate/polylang-detector/detector/process"
 )
 
-type NodeJSInspector struct{}
+type NodeJSInspector struct {
+	elfAnalyzer *process.ELFAnalyzer
+}
 
 func NewNodeJSInspector() *NodeJSInspector {
-	return &NodeJSInspector{}
+	return &NodeJSInspector{
+		elfAnalyzer: process.NewELFAnalyzer(),
+	}
 }
 
 func (n *NodeJSInspector) GetLanguage() Language {
@@ -26,14 +30,7 @@ func (n *NodeJSInspector) QuickScan(ctx *process.ProcessContext) *DetectionResul
 	nodeProcesses := []string{"node", "npm", "npx", "yarn", "pnpm"}
 	for _, proc := range nodeProcesses {
 		if exeName == proc || strings.Contains(cmdlineLower, "/"+proc+" ") {
-			framework := n.detectFramework(ctx)
-			version := n.extractVersion(ctx)
-			return &DetectionResult{
-				Language:   LanguageNodeJS,
-				Framework:  framework,
-				Version:    version,
-				Confidence: "high",
-			}
+			return n.nodeResult(ctx, ConfidenceHigh)
 		}
 	}
 
@@ -41,12 +38,7 @@ func (n *NodeJSInspector) QuickScan(ctx *process.ProcessContext) *DetectionResul
 	nodePatterns := []string{"node_modules", "npm start", "yarn start", "pnpm start"}
 	for _, pattern := range nodePatterns {
 		if strings.Contains(cmdlineLower, pattern) {
-			return &DetectionResult{
-				Language:   LanguageNodeJS,
-				Framework:  n.detectFramework(ctx),
-				Version:    n.extractVersion(ctx),
-				Confidence: "medium",
-			}
+			return n.nodeResult(ctx, ConfidenceMedium)
 		}
 	}
 
@@ -55,24 +47,75 @@ func (n *NodeJSInspector) QuickScan(ctx *process.ProcessContext) *DetectionResul
 
 func (n *NodeJSInspector) DeepScan(ctx *process.ProcessContext) *DetectionResult {
 	// Check memory maps for Node.js libraries
-	mapsFile, err := process.ReadMapsFile(ctx.PID)
-	if err != nil {
-		return nil
+	nodeLibs := []string{"libnode.so", "libnode.so.", "node"}
+	if mapsFile, err := ctx.MapsFile(); err == nil && process.ContainsBinary(mapsFile, nodeLibs) {
+		return n.nodeResult(ctx, ConfidenceHigh)
 	}
 
-	nodeLibs := []string{"libnode.so", "libnode.so.", "node"}
-	if process.ContainsBinary(mapsFile, nodeLibs) {
+	// Single-executable applications are the node binary renamed with the app blob injected
+	if isSEA, _ := n.elfAnalyzer.HasNodeSEAMarkers(process.ExecutableFile(ctx)); isSEA {
 		return &DetectionResult{
 			Language:   LanguageNodeJS,
-			Framework:  n.detectFramework(ctx),
+			Framework:  "single-executable",
 			Version:    n.extractVersion(ctx),
-			Confidence: "high",
+			Confidence: ConfidenceHigh,
 		}
 	}
 
 	return nil
 }
 
+// pm2ManagerRegex matches the PM2 daemon's process title ("PM2 v5.3.0: God Daemon (/root/.pm2)")
+// and the pm2 commands that run the daemon in the foreground in containers
+var pm2ManagerRegex = regexp.MustCompile(`^PM2 v[\d.]+: God Daemon|(?:^|[\s/])pm2(?:-runtime|-docker|-dev)?(?:\s|$)`)
+
+// nodeResult builds a detection result for a Node.js process, including the process
+// manager and cluster role it runs under
+func (n *NodeJSInspector) nodeResult(ctx *process.ProcessContext, confidence Confidence) *DetectionResult {
+	result := &DetectionResult{
+		Language:   LanguageNodeJS,
+		Framework:  n.detectFramework(ctx),
+		Version:    n.extractVersion(ctx),
+		Confidence: confidence,
+	}
+	result.AppServer, result.ProcessRole = nodeProcessManager(ctx)
+	return result
+}
+
+// nodeProcessManager returns the process manager ("PM2") a Node.js process runs under, if
+// any, and its role: "manager" for the PM2 daemon, which runs no application code itself,
+// or "master"/"worker" for a cluster module primary and its workers
+func nodeProcessManager(ctx *process.ProcessContext) (manager, role string) {
+	if pm2ManagerRegex.MatchString(ctx.Cmdline) {
+		return "PM2", "manager"
+	}
+	// PM2 sets pm_id in the environment of every app process it starts
+	if _, managed := ctx.Environ["pm_id"]; managed {
+		manager = "PM2"
+	}
+	return manager, clusterRole(ctx)
+}
+
+// clusterRole returns "worker" for a process forked by the cluster module (which marks its
+// workers with NODE_UNIQUE_ID), "master" for the primary that forked them, or "".
+// Only the process's own children file and their environments are read, since QuickScan
+// runs for every Node.js process.
+func clusterRole(ctx *process.ProcessContext) string {
+	if ctx.Environ["NODE_UNIQUE_ID"] != "" {
+		return "worker"
+	}
+	children, err := process.MainThreadChildPIDs(ctx.PID)
+	if err != nil {
+		return ""
+	}
+	for _, pid := range children {
+		if environ, err := process.ReadEnviron(pid); err == nil && environ["NODE_UNIQUE_ID"] != "" {
+			return "master"
+		}
+	}
+	return ""
+}
+
 func (n *NodeJSInspector) detectFramework(ctx *process.ProcessContext) string {
 	cmdlineLower := strings.ToLower(ctx.Cmdline)
 
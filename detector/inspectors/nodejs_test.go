@@ -0,0 +1,90 @@
+package inspectors
+
+import (
+	"debug/elf"
+	"testing"
+
+	"github.com/kloudmate/polylang-detector/detector/process"
+	"github.com/kloudmate/polylang-detector/internal/elftest"
+	"github.com/kloudmate/polylang-detector/internal/proctest"
+)
+
+func TestNodeJSInspectorDetectsSingleExecutableApp(t *testing.T) {
+	exe := elftest.Write(t, "inventory-api", elftest.Options{
+		Sections: []elftest.Section{
+			{Name: ".rodata", Type: elf.SHT_PROGBITS, Data: []byte("\x00NODE_SEA_FUSE_fce680ab2cc467b6e072b8b5df1996b2:1\x00")},
+		},
+		Symbols: []string{"main"},
+	})
+
+	ctx := &process.ProcessContext{PID: -1, Executable: exe, Cmdline: exe + " --port 3000"}
+	result, err := NewLanguageDetector().Detect(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Language != LanguageNodeJS || result.Framework != "single-executable" {
+		t.Errorf("expected nodejs (single-executable), got %s (%s)", result.Language, result.Framework)
+	}
+	if result.Confidence != ConfidenceHigh {
+		t.Errorf("expected high confidence, got %s", result.Confidence)
+	}
+}
+
+func TestNodeJSInspectorIgnoresUnflippedFuse(t *testing.T) {
+	exe := elftest.Write(t, "server", elftest.Options{
+		Sections: []elftest.Section{
+			{Name: ".rodata", Type: elf.SHT_PROGBITS, Data: []byte("\x00NODE_SEA_FUSE_fce680ab2cc467b6e072b8b5df1996b2:0\x00")},
+		},
+		Symbols: []string{"main"},
+	})
+
+	if result := NewNodeJSInspector().DeepScan(&process.ProcessContext{PID: -1, Executable: exe}); result != nil {
+		t.Errorf("expected no SEA detection without the flipped fuse, got %+v", result)
+	}
+}
+
+func TestNodeJSInspectorRecognizesProcessManagers(t *testing.T) {
+	root := proctest.New(t)
+
+	tests := []struct {
+		name      string
+		pid, ppid int
+		cmdline   string
+		environ   []string
+		framework string
+		manager   string
+		role      string
+	}{
+		{name: "pm2 god daemon", pid: 70, ppid: 1, cmdline: "PM2 v5.3.0: God Daemon (/root/.pm2)", manager: "PM2", role: "manager"},
+		{name: "pm2-runtime", pid: 71, ppid: 1, cmdline: "node /usr/local/bin/pm2-runtime start ecosystem.config.js", manager: "PM2", role: "manager"},
+		{name: "pm2 managed app", pid: 72, ppid: 70, cmdline: "node /app/node_modules/.bin/next start", environ: []string{"pm_id=0", "NODE_APP_INSTANCE=0"}, framework: "Next.js", manager: "PM2"},
+		{name: "pm2 cluster worker", pid: 73, ppid: 70, cmdline: "node /app/dist/main.js", environ: []string{"pm_id=1", "NODE_UNIQUE_ID=1"}, manager: "PM2", role: "worker"},
+		{name: "cluster primary", pid: 80, ppid: 1, cmdline: "node /app/express-server.js", framework: "Express", role: "master"},
+		{name: "cluster worker", pid: 81, ppid: 80, cmdline: "node /app/express-server.js", environ: []string{"NODE_UNIQUE_ID=1"}, framework: "Express", role: "worker"},
+		{name: "standalone", pid: 90, ppid: 1, cmdline: "node /app/index.js"},
+	}
+
+	for _, tt := range tests {
+		proctest.WriteProcess(t, root, proctest.Process{PID: tt.pid, PPID: tt.ppid, Exe: "/usr/local/bin/node", Cmdline: tt.cmdline, Environ: tt.environ})
+	}
+	// The cluster primary lists its worker in its main thread's children file
+	proctest.WriteChildren(t, root, 80, 81)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := process.GetProcessContext(tt.pid)
+			if err != nil {
+				t.Fatalf("failed to read process: %v", err)
+			}
+			ctx.Executable = "/usr/local/bin/node"
+			result := NewNodeJSInspector().QuickScan(ctx)
+			if result == nil {
+				t.Fatal("expected a Node.js result")
+			}
+			if result.Framework != tt.framework || result.AppServer != tt.manager || result.ProcessRole != tt.role {
+				t.Errorf("expected %q under %q as %q, got %q under %q as %q",
+					tt.framework, tt.manager, tt.role, result.Framework, result.AppServer, result.ProcessRole)
+			}
+		})
+	}
+}
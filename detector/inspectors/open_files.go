@@ -0,0 +1,74 @@
+package inspectors
+
+import (
+	"strings"
+
+	"github.com/kloudmate/polylang-detector/detector/process"
+)
+
+// openFileLanguages maps suffixes and path fragments of open files to the language they indicate
+var openFileLanguages = []struct {
+	language  Language
+	suffixes  []string
+	fragments []string
+}{
+	{LanguageJava, []string{".jar", ".war", ".class"}, nil},
+	{LanguagePython, []string{".py", ".pyc"}, []string{"/site-packages/", "/dist-packages/"}},
+	{LanguageNodeJS, []string{".js", ".mjs", ".cjs"}, []string{"/node_modules/"}},
+	{LanguageRuby, []string{".rb"}, []string{"/gems/"}},
+	{LanguagePHP, []string{".php"}, nil},
+	{LanguageDotNet, []string{".dll"}, nil},
+	{LanguageWasm, []string{".wasm"}, nil},
+}
+
+// openFileLanguage returns the language indicated by an open file's path, or LanguageUnknown
+func openFileLanguage(path string) Language {
+	for _, entry := range openFileLanguages {
+		for _, suffix := range entry.suffixes {
+			if strings.HasSuffix(path, suffix) {
+				return entry.language
+			}
+		}
+		for _, fragment := range entry.fragments {
+			if strings.Contains(path, fragment) {
+				return entry.language
+			}
+		}
+	}
+	return LanguageUnknown
+}
+
+// detectFromOpenFiles detects the language of a process from the files it has open (e.g.
+// /app/main.py or node_modules), for generic launchers whose name and maps are ambiguous.
+// The language with the most open files wins at medium confidence; a tie is inconclusive.
+func detectFromOpenFiles(ctx *process.ProcessContext) *DetectionResult {
+	files, err := process.OpenFiles(ctx.PID)
+	if err != nil {
+		return nil
+	}
+
+	counts := make(map[Language]int)
+	for _, file := range files {
+		if language := openFileLanguage(file); language != LanguageUnknown {
+			counts[language]++
+		}
+	}
+
+	best, bestCount, tied := LanguageUnknown, 0, false
+	for language, count := range counts {
+		switch {
+		case count > bestCount:
+			best, bestCount, tied = language, count, false
+		case count == bestCount:
+			tied = true
+		}
+	}
+	if best == LanguageUnknown || tied {
+		return nil
+	}
+
+	return &DetectionResult{
+		Language:   best,
+		Confidence: ConfidenceMedium,
+	}
+}
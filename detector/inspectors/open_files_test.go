@@ -0,0 +1,57 @@
+package inspectors
+
+import (
+	"testing"
+
+	"github.com/kloudmate/polylang-detector/detector/process"
+	"github.com/kloudmate/polylang-detector/internal/proctest"
+)
+
+func TestOpenFileLanguage(t *testing.T) {
+	tests := []struct {
+		path string
+		want Language
+	}{
+		{"/app/main.py", LanguagePython},
+		{"/usr/local/lib/python3.12/site-packages/flask/__init__.cpython-312.pyc", LanguagePython},
+		{"/app/node_modules/express/package.json", LanguageNodeJS},
+		{"/srv/server.mjs", LanguageNodeJS},
+		{"/opt/app/orders.jar", LanguageJava},
+		{"/usr/local/bundle/gems/rack-3.0.8/lib/rack.rb", LanguageRuby},
+		{"/var/www/index.php", LanguagePHP},
+		{"/app/Orders.Api.dll", LanguageDotNet},
+		{"/var/log/app.log", LanguageUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := openFileLanguage(tt.path); got != tt.want {
+			t.Errorf("openFileLanguage(%q) = %s, want %s", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestDetectFallsBackToOpenFiles(t *testing.T) {
+	root := proctest.New(t)
+
+	// A generic launcher whose name and maps say nothing about the language
+	proctest.WriteProcess(t, root, proctest.Process{PID: 70, PPID: 1, Exe: "/usr/local/bin/launcher", Cmdline: "launcher\x00--config\x00/etc/app.yaml\x00"})
+	proctest.WriteFds(t, root, 70, "/dev/null", "pipe:[4021]", "socket:[4022]", "/app/main.py", "/app/settings.py", "/etc/app.yaml")
+
+	ctx := &process.ProcessContext{PID: 70, PPID: 1, Executable: "/usr/local/bin/launcher", Cmdline: "launcher --config /etc/app.yaml"}
+	result, err := NewLanguageDetector().Detect(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Language != LanguagePython || result.Confidence != ConfidenceMedium {
+		t.Errorf("expected Python at medium confidence from open files, got %s (%s)", result.Language, result.Confidence)
+	}
+
+	// Equally many Java and Node.js files are inconclusive
+	proctest.WriteProcess(t, root, proctest.Process{PID: 71, PPID: 1, Exe: "/usr/local/bin/launcher", Cmdline: "launcher\x00"})
+	proctest.WriteFds(t, root, 71, "/opt/app/orders.jar", "/srv/index.js")
+
+	ctx = &process.ProcessContext{PID: 71, PPID: 1, Executable: "/usr/local/bin/launcher", Cmdline: "launcher"}
+	if result, err := NewLanguageDetector().Detect(ctx); err != nil || result.Language != LanguageUnknown {
+		t.Errorf("expected no language for a tie, got %+v (%v)", result, err)
+	}
+}
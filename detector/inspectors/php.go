@@ -22,31 +22,64 @@ func (p *PHPInspector) GetLanguage() Language {
 	return LanguagePHP
 }
 
+// phpExecutableRegex matches PHP CLI and FPM executables, including versioned names (php8.2, php-fpm82)
+var phpExecutableRegex = regexp.MustCompile(`^php(-fpm)?[\d.]*$`)
+
+// PHP-FPM rewrites its process titles, e.g. "php-fpm: master process (/usr/local/etc/php-fpm.conf)"
+// for the master and "php-fpm: pool www" for workers
+const (
+	phpFPMMasterTitle = "php-fpm: master process"
+	phpFPMWorkerTitle = "php-fpm: pool "
+)
+
 func (p *PHPInspector) QuickScan(ctx *process.ProcessContext) *DetectionResult {
 	exeName := filepath.Base(ctx.Executable)
 	cmdlineLower := strings.ToLower(ctx.Cmdline)
 
 	// Check for PHP executable
-	phpProcesses := []string{"php", "php-fpm"}
+	phpProcesses := []string{"php", "php-fpm", "artisan"}
+	isPHP := phpExecutableRegex.MatchString(exeName)
 	for _, proc := range phpProcesses {
-		if exeName == proc || strings.Contains(cmdlineLower, proc) {
-			framework := p.detectFramework(ctx)
-			version := p.extractVersion(ctx)
-			return &DetectionResult{
-				Language:   LanguagePHP,
-				Framework:  framework,
-				Version:    version,
-				Confidence: "high",
-			}
+		if strings.Contains(cmdlineLower, proc) {
+			isPHP = true
+			break
 		}
 	}
+	if !isPHP {
+		return nil
+	}
 
-	return nil
+	// FPM workers carry only the pool name; framework and version signals live on the master
+	source := ctx
+	if strings.HasPrefix(cmdlineLower, phpFPMWorkerTitle) {
+		if master := p.fpmMaster(ctx); master != nil {
+			source = master
+		}
+	}
+
+	return &DetectionResult{
+		Language:   LanguagePHP,
+		Framework:  p.detectFramework(source),
+		Version:    p.extractVersion(source),
+		Confidence: ConfidenceHigh,
+	}
+}
+
+// fpmMaster returns the PHP-FPM master process that spawned a worker, or nil
+func (p *PHPInspector) fpmMaster(worker *process.ProcessContext) *process.ProcessContext {
+	if worker.PPID <= 0 {
+		return nil
+	}
+	master, err := process.GetProcessContext(worker.PPID)
+	if err != nil || !strings.HasPrefix(strings.ToLower(master.Cmdline), phpFPMMasterTitle) {
+		return nil
+	}
+	return master
 }
 
 func (p *PHPInspector) DeepScan(ctx *process.ProcessContext) *DetectionResult {
 	// Check memory maps for PHP libraries
-	mapsFile, err := process.ReadMapsFile(ctx.PID)
+	mapsFile, err := ctx.MapsFile()
 	if err != nil {
 		return nil
 	}
@@ -63,7 +96,7 @@ func (p *PHPInspector) DeepScan(ctx *process.ProcessContext) *DetectionResult {
 			Language:   LanguagePHP,
 			Framework:  p.detectFramework(ctx),
 			Version:    version,
-			Confidence: "high",
+			Confidence: ConfidenceHigh,
 		}
 	}
 
@@ -86,6 +119,11 @@ func (p *PHPInspector) detectFramework(ctx *process.ProcessContext) string {
 		}
 	}
 
+	// Report the FPM process manager when no application framework is visible
+	if strings.HasPrefix(cmdlineLower, "php-fpm:") {
+		return "PHP-FPM"
+	}
+
 	return ""
 }
 
@@ -0,0 +1,80 @@
+package inspectors
+
+import (
+	"testing"
+
+	"github.com/kloudmate/polylang-detector/detector/process"
+	"github.com/kloudmate/polylang-detector/internal/proctest"
+)
+
+func TestPHPInspectorFPMMaster(t *testing.T) {
+	ctx := &process.ProcessContext{
+		Executable: "/usr/local/sbin/php-fpm",
+		Cmdline:    "php-fpm: master process (/usr/local/etc/php-fpm.conf)",
+		Environ:    map[string]string{"PHP_VERSION": "8.2.12"},
+	}
+
+	result := NewPHPInspector().QuickScan(ctx)
+	if result == nil || result.Language != LanguagePHP || result.Confidence != ConfidenceHigh {
+		t.Fatalf("expected high-confidence PHP, got %+v", result)
+	}
+	if result.Framework != "PHP-FPM" || result.Version != "8.2.12" {
+		t.Errorf("expected PHP-FPM 8.2.12, got %s %s", result.Framework, result.Version)
+	}
+}
+
+func TestPHPInspectorFPMWorkerUsesMaster(t *testing.T) {
+	root := proctest.New(t)
+
+	proctest.WriteProcess(t, root, proctest.Process{
+		PID:     1,
+		Exe:     "/usr/local/sbin/php-fpm8.2",
+		Cmdline: "php-fpm: master process (/usr/local/etc/php-fpm.conf)",
+		Environ: []string{"PHP_VERSION=8.2.12", "APP_ENV=production"},
+	})
+	proctest.WriteProcess(t, root, proctest.Process{PID: 8, PPID: 1, Exe: "/usr/local/sbin/php-fpm8.2", Cmdline: "php-fpm: pool www"})
+
+	worker, err := process.GetProcessContext(8)
+	if err != nil {
+		t.Fatalf("failed to read worker process: %v", err)
+	}
+
+	result := NewPHPInspector().QuickScan(worker)
+	if result == nil || result.Language != LanguagePHP || result.Confidence != ConfidenceHigh {
+		t.Fatalf("expected high-confidence PHP for the worker, got %+v", result)
+	}
+	if result.Version != "8.2.12" {
+		t.Errorf("expected version from the master process, got %q", result.Version)
+	}
+}
+
+func TestPHPInspectorArtisanQueueWorker(t *testing.T) {
+	ctx := &process.ProcessContext{
+		Executable: "/usr/bin/php8.3",
+		Cmdline:    "/usr/bin/php8.3 /var/www/html/artisan queue:work --sleep=3 --tries=3",
+	}
+
+	result := NewPHPInspector().QuickScan(ctx)
+	if result == nil || result.Language != LanguagePHP {
+		t.Fatalf("expected PHP, got %+v", result)
+	}
+	if result.Framework != "Laravel" {
+		t.Errorf("expected Laravel, got %q", result.Framework)
+	}
+}
+
+func TestDetectPHPFPMMaster(t *testing.T) {
+	ctx := &process.ProcessContext{
+		Executable: "/usr/local/sbin/php-fpm",
+		Cmdline:    "php-fpm: master process (/usr/local/etc/php-fpm.conf)",
+		Environ:    map[string]string{"PHP_VERSION": "8.2.12"},
+	}
+
+	result, err := NewLanguageDetector().Detect(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Language != LanguagePHP || result.Framework != "PHP-FPM" || result.Version != "8.2.12" {
+		t.Errorf("expected PHP-FPM 8.2.12, got %+v", result)
+	}
+}
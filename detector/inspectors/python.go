@@ -1,8 +1,12 @@
 package inspectors
 
 import (
+	"io"
+	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/kloudmate/polylang-detector/detector/process"
@@ -28,27 +32,15 @@ func (p *PythonInspector) QuickScan(ctx *process.ProcessContext) *DetectionResul
 	// Check for Python executable patterns
 	pythonRegex := regexp.MustCompile(`^(python|python3|python\d+|python3\.\d+)$`)
 	if pythonRegex.MatchString(exeName) {
-		framework := p.detectFramework(ctx)
-		version := p.extractVersion(ctx)
-		return &DetectionResult{
-			Language:   LanguagePython,
-			Framework:  framework,
-			Version:    version,
-			Confidence: "high",
-		}
+		return p.pythonResult(ctx, p.extractVersion(ctx), ConfidenceHigh)
 	}
 
 	// Check command line for Python patterns
 	cmdlineLower := strings.ToLower(ctx.Cmdline)
-	pythonPatterns := []string{"python", "gunicorn", "uvicorn", "pip ", "poetry run", "pipenv run"}
+	pythonPatterns := []string{"python", "gunicorn", "uvicorn", "uwsgi", "pip ", "poetry run", "pipenv run"}
 	for _, pattern := range pythonPatterns {
 		if strings.Contains(cmdlineLower, pattern) {
-			return &DetectionResult{
-				Language:   LanguagePython,
-				Framework:  p.detectFramework(ctx),
-				Version:    p.extractVersion(ctx),
-				Confidence: "medium",
-			}
+			return p.pythonResult(ctx, p.extractVersion(ctx), ConfidenceMedium)
 		}
 	}
 
@@ -56,43 +48,93 @@ func (p *PythonInspector) QuickScan(ctx *process.ProcessContext) *DetectionResul
 }
 
 func (p *PythonInspector) DeepScan(ctx *process.ProcessContext) *DetectionResult {
+	exe := process.ExecutableFile(ctx)
+
 	// Check for Python library dependencies via ELF
-	if hasPython, version, _ := p.elfAnalyzer.HasPythonSymbols(ctx.Executable); hasPython {
-		return &DetectionResult{
-			Language:   LanguagePython,
-			Framework:  p.detectFramework(ctx),
-			Version:    version,
-			Confidence: "high",
-		}
+	if hasPython, version, _ := p.elfAnalyzer.HasPythonSymbols(exe); hasPython {
+		return p.pythonResult(ctx, version, ConfidenceHigh)
 	}
 
 	// Check memory maps for Python libraries
-	mapsFile, err := process.ReadMapsFile(ctx.PID)
-	if err != nil {
-		return nil
+	mapsFile, err := ctx.MapsFile()
+	if err == nil {
+		pythonLibs := []string{"libpython3", "libpython2", "python3.", "python2."}
+		if process.ContainsBinary(mapsFile, pythonLibs) {
+			// A PyInstaller bootloader loads libpython from its _MEIxxxxxx unpack directory
+			if process.ContainsBinary(mapsFile, []string{"/_MEI"}) {
+				return p.pyInstallerResult(ctx)
+			}
+			return p.pythonResult(ctx, p.extractVersion(ctx), ConfidenceHigh)
+		}
 	}
 
-	pythonLibs := []string{"libpython3", "libpython2", "python3.", "python2."}
-	if process.ContainsBinary(mapsFile, pythonLibs) {
-		return &DetectionResult{
-			Language:   LanguagePython,
-			Framework:  p.detectFramework(ctx),
-			Version:    p.extractVersion(ctx),
-			Confidence: "high",
-		}
+	// PyInstaller bundles are a native bootloader that unpacks the interpreter at runtime
+	if isBundle, _ := p.elfAnalyzer.HasPyInstallerMarkers(exe); isBundle {
+		return p.pyInstallerResult(ctx)
 	}
 
 	return nil
 }
 
+// pyInstallerResult builds a detection result for a PyInstaller bundle
+func (p *PythonInspector) pyInstallerResult(ctx *process.ProcessContext) *DetectionResult {
+	return &DetectionResult{
+		Language:   LanguagePython,
+		Framework:  "PyInstaller",
+		Version:    p.extractVersion(ctx),
+		Confidence: ConfidenceHigh,
+	}
+}
+
+// pythonResult builds a detection result for a Python process, including the app server
+// it runs under and, for pre-fork servers, its role and worker count
+func (p *PythonInspector) pythonResult(ctx *process.ProcessContext, version string, confidence Confidence) *DetectionResult {
+	result := &DetectionResult{
+		Language:   LanguagePython,
+		Framework:  p.detectFramework(ctx),
+		Version:    version,
+		Confidence: confidence,
+		AppServer:  p.detectServer(ctx),
+	}
+	if _, preFork := preForkWorkerFlags[result.AppServer]; preFork {
+		result.ProcessRole = p.serverRole(ctx, result.AppServer)
+		result.WorkerCount = p.workerCount(ctx, result.AppServer)
+	}
+	return result
+}
+
+// pythonWorkerFrameworks are task-queue workers, checked before the web frameworks because
+// worker cmdlines often name the web app too (e.g. "celery -A django_project worker")
+var pythonWorkerFrameworks = []struct {
+	Framework string
+	Patterns  []string
+}{
+	{"Celery", []string{"celery "}},
+	{"RQ", []string{"rq worker", "rqworker"}},
+	{"Dramatiq", []string{"dramatiq"}},
+}
+
 func (p *PythonInspector) detectFramework(ctx *process.ProcessContext) string {
 	cmdlineLower := strings.ToLower(ctx.Cmdline)
 
+	for _, worker := range pythonWorkerFrameworks {
+		for _, pattern := range worker.Patterns {
+			if strings.Contains(cmdlineLower, pattern) {
+				return worker.Framework
+			}
+		}
+	}
+
+	// The app module served by an ASGI/WSGI server names the framework better than the server does
+	if framework := p.appModuleFramework(ctx); framework != "" {
+		return framework
+	}
+
 	frameworks := map[string][]string{
-		"Django":   {"django", "manage.py", "django.core", "django-admin", "wsgi.py"},
-		"FastAPI":  {"fastapi", "uvicorn", "starlette", "asgi"},
-		"Flask":    {"flask", "werkzeug", "flask run"},
-		"Gunicorn": {"gunicorn", "gunicorn.app"},
+		"Django":    {"django", "manage.py", "django.core", "django-admin", "wsgi.py"},
+		"FastAPI":   {"fastapi"},
+		"Starlette": {"starlette"},
+		"Flask":     {"flask", "werkzeug", "flask run"},
 	}
 
 	for framework, patterns := range frameworks {
@@ -106,6 +148,163 @@ func (p *PythonInspector) detectFramework(ctx *process.ProcessContext) string {
 	return ""
 }
 
+// pythonAppServers maps ASGI/WSGI server commands to the server name
+var pythonAppServers = map[string]string{
+	"uvicorn":   "Uvicorn",
+	"hypercorn": "Hypercorn",
+	"daphne":    "Daphne",
+	"gunicorn":  "Gunicorn",
+	"uwsgi":     "uWSGI",
+}
+
+// serverTitleRegex matches the process titles pre-fork servers set once running, e.g.
+// "gunicorn: worker [app:app]" or "uWSGI master"
+var serverTitleRegex = regexp.MustCompile(`(?i)^(gunicorn:|uwsgi) (master|worker)\b(?:.*\[(.*)\])?`)
+
+// preForkWorkerFlags maps pre-fork servers, which run a master and forked workers, to the
+// flags setting their worker count
+var preForkWorkerFlags = map[string][]string{
+	"Gunicorn": {"-w", "--workers"},
+	"uWSGI":    {"-p", "--processes", "--workers"},
+}
+
+// appModuleRegex matches a "module.path:attribute" app argument such as "myapp.main:app"
+// or "app:create_app()"
+var appModuleRegex = regexp.MustCompile(`^([A-Za-z_][\w.]*):[A-Za-z_][\w.]*(\(.*\))?$`)
+
+// frameworkImports lists framework imports in precedence order; FastAPI apps import
+// Starlette too, so the more specific framework comes first
+var frameworkImports = []struct {
+	Framework string
+	Regex     *regexp.Regexp
+}{
+	{"FastAPI", regexp.MustCompile(`(?m)^\s*(from|import)\s+fastapi\b`)},
+	{"Quart", regexp.MustCompile(`(?m)^\s*(from|import)\s+quart\b`)},
+	{"Litestar", regexp.MustCompile(`(?m)^\s*(from|import)\s+litestar\b`)},
+	{"Django", regexp.MustCompile(`(?m)^\s*(from|import)\s+django\b`)},
+	{"Flask", regexp.MustCompile(`(?m)^\s*(from|import)\s+flask\b`)},
+	{"Starlette", regexp.MustCompile(`(?m)^\s*(from|import)\s+starlette\b`)},
+}
+
+// maxAppModuleSize bounds how much of an app module's source is read
+const maxAppModuleSize = 64 << 10
+
+// serverArgs returns the server name and the arguments following it on the command line,
+// for both "uvicorn ..." and "python -m uvicorn ..." invocations
+func (p *PythonInspector) serverArgs(ctx *process.ProcessContext) (string, []string) {
+	if matches := serverTitleRegex.FindStringSubmatch(ctx.Cmdline); matches != nil {
+		server := pythonAppServers[strings.ToLower(strings.TrimSuffix(matches[1], ":"))]
+		return server, strings.Fields(matches[3])
+	}
+
+	args := strings.Fields(ctx.Cmdline)
+	for i, arg := range args {
+		if server, ok := pythonAppServers[filepath.Base(arg)]; ok {
+			return server, args[i+1:]
+		}
+	}
+	return "", nil
+}
+
+// detectServer returns the ASGI/WSGI server running the process, or ""
+func (p *PythonInspector) detectServer(ctx *process.ProcessContext) string {
+	server, _ := p.serverArgs(ctx)
+	return server
+}
+
+// serverRole returns "master" or "worker" for a pre-fork server process, from its process
+// title or, when the title wasn't changed, whether its parent runs the same server
+func (p *PythonInspector) serverRole(ctx *process.ProcessContext, server string) string {
+	if matches := serverTitleRegex.FindStringSubmatch(ctx.Cmdline); matches != nil {
+		return strings.ToLower(matches[2])
+	}
+	if ctx.PPID > 0 {
+		if parent, err := process.GetProcessContext(ctx.PPID); err == nil && p.detectServer(parent) == server {
+			return "worker"
+		}
+	}
+	return "master"
+}
+
+// workerCount returns the worker count a pre-fork server was started with, from its
+// command line, GUNICORN_CMD_ARGS or WEB_CONCURRENCY, or 0 when unknown
+func (p *PythonInspector) workerCount(ctx *process.ProcessContext, server string) int {
+	_, args := p.serverArgs(ctx)
+	if server == "Gunicorn" {
+		args = append(args, strings.Fields(ctx.Environ["GUNICORN_CMD_ARGS"])...)
+	}
+
+	flags := preForkWorkerFlags[server]
+	for i, arg := range args {
+		flag, value, hasValue := strings.Cut(arg, "=")
+		if !slices.Contains(flags, flag) {
+			continue
+		}
+		if !hasValue && i+1 < len(args) {
+			value = args[i+1]
+		}
+		if count, err := strconv.Atoi(value); err == nil && count > 0 {
+			return count
+		}
+	}
+
+	if server == "Gunicorn" {
+		if count, err := strconv.Atoi(ctx.Environ["WEB_CONCURRENCY"]); err == nil && count > 0 {
+			return count
+		}
+	}
+	return 0
+}
+
+// appModuleFramework identifies the framework of the app module an ASGI/WSGI server was
+// started with (e.g. "uvicorn myapp.main:app") from the module's imports. Django projects
+// serve their conventional asgi/wsgi module, which identifies them even if unreadable.
+func (p *PythonInspector) appModuleFramework(ctx *process.ProcessContext) string {
+	_, args := p.serverArgs(ctx)
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			continue
+		}
+		matches := appModuleRegex.FindStringSubmatch(arg)
+		if matches == nil {
+			continue
+		}
+
+		module := matches[1]
+		modulePath := strings.ReplaceAll(module, ".", "/")
+		for _, candidate := range []string{modulePath + ".py", modulePath + "/__init__.py"} {
+			if framework := frameworkFromSource(process.ContainerFilePath(ctx, candidate)); framework != "" {
+				return framework
+			}
+		}
+		if strings.HasSuffix(module, ".asgi") || strings.HasSuffix(module, ".wsgi") {
+			return "Django"
+		}
+		return ""
+	}
+	return ""
+}
+
+// frameworkFromSource returns the highest-precedence framework imported by a Python source file
+func frameworkFromSource(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	source, err := io.ReadAll(io.LimitReader(file, maxAppModuleSize))
+	if err != nil {
+		return ""
+	}
+	for _, candidate := range frameworkImports {
+		if candidate.Regex.Match(source) {
+			return candidate.Framework
+		}
+	}
+	return ""
+}
+
 func (p *PythonInspector) extractVersion(ctx *process.ProcessContext) string {
 	versionKeys := []string{"PYTHON_VERSION", "PY_VERSION"}
 
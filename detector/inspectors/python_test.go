@@ -0,0 +1,149 @@
+package inspectors
+
+import (
+	"debug/elf"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kloudmate/polylang-detector/detector/process"
+	"github.com/kloudmate/polylang-detector/internal/elftest"
+	"github.com/kloudmate/polylang-detector/internal/proctest"
+)
+
+func TestPythonInspectorDetectsPyInstallerBundle(t *testing.T) {
+	exe := elftest.Write(t, "report-worker", elftest.Options{
+		Sections: []elftest.Section{{Name: ".text", Type: elf.SHT_PROGBITS, Data: []byte{0x90, 0xc3}}},
+		Symbols:  []string{"main"},
+	})
+
+	// PyInstaller appends the archive, ending with its cookie, after the bootloader
+	file, err := os.OpenFile(exe, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("failed to open fixture: %v", err)
+	}
+	if _, err := file.Write([]byte("PYZ-00.pyz\x00MEI\x0c\x0b\x0a\x0b\x0e\x00\x00\x10\x00python311.so\x00")); err != nil {
+		t.Fatalf("failed to append archive: %v", err)
+	}
+	file.Close()
+
+	ctx := &process.ProcessContext{PID: -1, Executable: exe, Cmdline: exe}
+	result, err := NewLanguageDetector().Detect(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Language != LanguagePython || result.Framework != "PyInstaller" {
+		t.Errorf("expected Python (PyInstaller), got %s (%s)", result.Language, result.Framework)
+	}
+	if result.Confidence != ConfidenceHigh {
+		t.Errorf("expected high confidence, got %s", result.Confidence)
+	}
+}
+
+func TestPythonInspectorDetectsWorkerFrameworks(t *testing.T) {
+	tests := []struct {
+		cmdline   string
+		framework string
+	}{
+		{"/usr/local/bin/python /usr/local/bin/celery -A app worker --loglevel=info", "Celery"},
+		{"python3 /usr/local/bin/celery -A django_project worker", "Celery"},
+		{"/usr/bin/python3 /usr/local/bin/rq worker default", "RQ"},
+		{"python manage.py rqworker high default", "RQ"},
+		{"python3 /usr/local/bin/dramatiq tasks --processes 2", "Dramatiq"},
+	}
+
+	for _, tt := range tests {
+		ctx := &process.ProcessContext{PID: -1, Executable: "/usr/bin/python3", Cmdline: tt.cmdline}
+		result := NewPythonInspector().QuickScan(ctx)
+		if result == nil || result.Framework != tt.framework {
+			t.Errorf("%q: expected framework %s, got %+v", tt.cmdline, tt.framework, result)
+		}
+	}
+}
+
+func TestPythonInspectorSeparatesAppServerFromFramework(t *testing.T) {
+	root := proctest.New(t)
+
+	tests := []struct {
+		pid       int
+		cmdline   string
+		module    string
+		source    string
+		framework string
+		server    string
+	}{
+		{30, "/usr/local/bin/python /usr/local/bin/uvicorn --host 0.0.0.0 myapp.main:app", "myapp/main.py", "from starlette.applications import Starlette\n", "Starlette", "Uvicorn"},
+		{31, "python3 -m uvicorn api:app --port 8000", "api.py", "from fastapi import FastAPI\nfrom starlette.middleware.cors import CORSMiddleware\n", "FastAPI", "Uvicorn"},
+		{32, "python3 /usr/local/bin/hypercorn service:create_app()", "service/__init__.py", "import quart\n", "Quart", "Hypercorn"},
+		{33, "/usr/local/bin/python /usr/local/bin/daphne -b 0.0.0.0 myproject.asgi:application", "", "", "Django", "Daphne"},
+		{34, "python3 -m gunicorn app:app", "", "", "", "Gunicorn"},
+	}
+
+	for _, tt := range tests {
+		proctest.WriteProcess(t, root, proctest.Process{PID: tt.pid, PPID: 1, Exe: "/usr/local/bin/python", Cwd: "/app", Cmdline: tt.cmdline})
+		if tt.module != "" {
+			proctest.WriteContainerFile(t, root, tt.pid, "/app/"+tt.module, []byte(tt.source))
+		}
+
+		ctx := &process.ProcessContext{PID: tt.pid, Executable: "/usr/local/bin/python", Cmdline: tt.cmdline}
+		result := NewPythonInspector().QuickScan(ctx)
+		if result == nil {
+			t.Fatalf("%q: expected a Python result", tt.cmdline)
+		}
+		if result.Framework != tt.framework || result.AppServer != tt.server {
+			t.Errorf("%q: expected %q on %q, got %q on %q", tt.cmdline, tt.framework, tt.server, result.Framework, result.AppServer)
+		}
+	}
+}
+
+func TestPythonInspectorReportsPreForkServerRole(t *testing.T) {
+	root := proctest.New(t)
+
+	// Without setproctitle, gunicorn's workers keep the master's command line
+	untitled := "/usr/local/bin/python /usr/local/bin/gunicorn --workers 4 --bind 0.0.0.0:8000 app:app"
+	proctest.WriteProcess(t, root, proctest.Process{PID: 60, PPID: 1, Exe: "/usr/local/bin/python", Cmdline: strings.ReplaceAll(untitled, " ", "\x00")})
+	proctest.WriteProcess(t, root, proctest.Process{PID: 61, PPID: 60, Exe: "/usr/local/bin/python", Cmdline: strings.ReplaceAll(untitled, " ", "\x00")})
+
+	tests := []struct {
+		name    string
+		ctx     *process.ProcessContext
+		server  string
+		role    string
+		workers int
+	}{
+		{"gunicorn master title", &process.ProcessContext{PID: -1, Executable: "/usr/local/bin/python", Cmdline: "gunicorn: master [app:app]", Environ: map[string]string{"GUNICORN_CMD_ARGS": "--bind=0.0.0.0 --workers=3"}}, "Gunicorn", "master", 3},
+		{"gunicorn worker title", &process.ProcessContext{PID: -1, Executable: "/usr/local/bin/python", Cmdline: "gunicorn: worker [app:app]", Environ: map[string]string{"WEB_CONCURRENCY": "2"}}, "Gunicorn", "worker", 2},
+		{"untitled master", &process.ProcessContext{PID: 60, PPID: 1, Executable: "/usr/local/bin/python", Cmdline: untitled}, "Gunicorn", "master", 4},
+		{"untitled worker", &process.ProcessContext{PID: 61, PPID: 60, Executable: "/usr/local/bin/python", Cmdline: untitled}, "Gunicorn", "worker", 4},
+		{"uwsgi worker title", &process.ProcessContext{PID: -1, Executable: "/usr/local/bin/uwsgi", Cmdline: "uWSGI worker 2"}, "uWSGI", "worker", 0},
+		{"uwsgi master", &process.ProcessContext{PID: -1, Executable: "/usr/local/bin/uwsgi", Cmdline: "uwsgi --http :8000 --processes 8 --module app:app"}, "uWSGI", "master", 8},
+		{"uvicorn", &process.ProcessContext{PID: -1, Executable: "/usr/local/bin/python", Cmdline: "uvicorn --workers 2 app:app"}, "Uvicorn", "", 0},
+	}
+
+	for _, tt := range tests {
+		result := NewPythonInspector().QuickScan(tt.ctx)
+		if result == nil {
+			t.Fatalf("%s: expected a Python result", tt.name)
+		}
+		if result.AppServer != tt.server || result.ProcessRole != tt.role || result.WorkerCount != tt.workers {
+			t.Errorf("%s: expected %s %q with %d workers, got %s %q with %d workers",
+				tt.name, tt.server, tt.role, tt.workers, result.AppServer, result.ProcessRole, result.WorkerCount)
+		}
+	}
+}
+
+func TestPythonInspectorRecognizesUnpackedPyInstallerFromMaps(t *testing.T) {
+	root := proctest.New(t)
+
+	proctest.WriteProcess(t, root, proctest.Process{PID: 40, PPID: 1, Exe: "/app/report-worker", Cmdline: "/app/report-worker\x00"})
+	proctest.WriteMaps(t, root, 40, "/tmp/_MEIq3xT9a/libpython3.11.so.1.0")
+
+	ctx, err := process.GetProcessContext(40)
+	if err != nil {
+		t.Fatalf("failed to read process: %v", err)
+	}
+	result := NewPythonInspector().DeepScan(ctx)
+	if result == nil || result.Framework != "PyInstaller" {
+		t.Errorf("expected Python (PyInstaller), got %+v", result)
+	}
+}
@@ -32,7 +32,7 @@ func (r *RubyInspector) QuickScan(ctx *process.ProcessContext) *DetectionResult
 				Language:   LanguageRuby,
 				Framework:  framework,
 				Version:    version,
-				Confidence: "high",
+				Confidence: ConfidenceHigh,
 			}
 		}
 	}
@@ -42,7 +42,7 @@ func (r *RubyInspector) QuickScan(ctx *process.ProcessContext) *DetectionResult
 
 func (r *RubyInspector) DeepScan(ctx *process.ProcessContext) *DetectionResult {
 	// Check memory maps for Ruby libraries
-	mapsFile, err := process.ReadMapsFile(ctx.PID)
+	mapsFile, err := ctx.MapsFile()
 	if err != nil {
 		return nil
 	}
@@ -53,7 +53,7 @@ func (r *RubyInspector) DeepScan(ctx *process.ProcessContext) *DetectionResult {
 			Language:   LanguageRuby,
 			Framework:  r.detectFramework(ctx),
 			Version:    r.extractVersion(ctx),
-			Confidence: "high",
+			Confidence: ConfidenceHigh,
 		}
 	}
 
@@ -25,14 +25,55 @@ func (r *RustInspector) QuickScan(ctx *process.ProcessContext) *DetectionResult
 
 func (r *RustInspector) DeepScan(ctx *process.ProcessContext) *DetectionResult {
 	// Check for Rust symbols in ELF binary
-	if hasRust, _ := r.elfAnalyzer.HasRustSymbols(ctx.Executable); hasRust {
+	exe := process.ExecutableFile(ctx)
+	if hasRust, _ := r.elfAnalyzer.HasRustSymbols(exe); hasRust {
 		return &DetectionResult{
 			Language:   LanguageRust,
-			Framework:  "",
+			Framework:  r.detectFramework(exe),
 			Version:    "", // TODO: Extract Rust version
-			Confidence: "high",
+			Confidence: ConfidenceHigh,
 		}
 	}
 
 	return nil
 }
+
+// rustWebFrameworks maps web frameworks to the fragments they leave in a binary: the
+// length-prefixed crate name of their mangled symbols and, in stripped binaries, the
+// crate path of panic locations (e.g. ".cargo/registry/src/.../axum-0.7.5/src/serve.rs")
+var rustWebFrameworks = []struct {
+	Framework string
+	Markers   []string
+}{
+	{"Actix Web", []string{"9actix_web", "/actix-web-"}},
+	{"Axum", []string{"4axum", "/axum-"}},
+	{"Rocket", []string{"6rocket", "/rocket-"}},
+}
+
+// rustFrameworkSections hold the crate names: .strtab the mangled symbols of unstripped
+// binaries, .rodata the panic locations
+var rustFrameworkSections = []string{".strtab", ".rodata"}
+
+// maxRustFrameworkScanBytes bounds how much of a Rust binary is scanned for framework markers
+const maxRustFrameworkScanBytes = 32 << 20
+
+// detectFramework identifies the web framework a Rust binary was built with, or "".
+// The markers of every framework are searched for in a single pass over the binary.
+func (r *RustInspector) detectFramework(exe string) string {
+	var markers []string
+	var frameworks []string
+	for _, candidate := range rustWebFrameworks {
+		for _, marker := range candidate.Markers {
+			markers = append(markers, marker)
+			frameworks = append(frameworks, candidate.Framework)
+		}
+	}
+
+	found, _ := process.ScanMarkers(exe, rustFrameworkSections, markers, maxRustFrameworkScanBytes)
+	for i, ok := range found {
+		if ok {
+			return frameworks[i]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,57 @@
+package inspectors
+
+import (
+	"debug/elf"
+	"testing"
+
+	"github.com/kloudmate/polylang-detector/detector/process"
+	"github.com/kloudmate/polylang-detector/internal/elftest"
+)
+
+func TestRustInspectorDetectsWebFramework(t *testing.T) {
+	tests := []struct {
+		name      string
+		symbols   []string
+		rodata    string
+		framework string
+	}{
+		{
+			name:      "axum symbols",
+			symbols:   []string{"__rust_alloc", "_ZN4axum5serve5serve17h5f2c1e4b9a7d3c21E", "_ZN5tokio7runtime7Runtime8block_on17h0a1b2c3d4e5f6071E"},
+			framework: "Axum",
+		},
+		{
+			name:      "stripped actix binary",
+			symbols:   []string{"__rust_alloc"},
+			rodata:    "/usr/local/cargo/registry/src/index.crates.io-6f17d22bba15001f/actix-web-4.5.1/src/server.rs",
+			framework: "Actix Web",
+		},
+		{
+			name:      "rocket symbols",
+			symbols:   []string{"__rust_alloc", "_ZN6rocket6launch17h9c8b7a6f5e4d3c21E"},
+			framework: "Rocket",
+		},
+		{
+			name:    "tokio without a web framework",
+			symbols: []string{"__rust_alloc", "_ZN5tokio7runtime7Runtime8block_on17h0a1b2c3d4e5f6071E"},
+			rodata:  "maximum retries exceeded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exe := elftest.Write(t, "server", elftest.Options{
+				Sections: []elftest.Section{{Name: ".rodata", Type: elf.SHT_PROGBITS, Data: []byte("\x00" + tt.rodata + "\x00")}},
+				Symbols:  tt.symbols,
+			})
+
+			result := NewRustInspector().DeepScan(&process.ProcessContext{PID: -1, Executable: exe})
+			if result == nil || result.Language != LanguageRust {
+				t.Fatalf("expected a Rust result, got %+v", result)
+			}
+			if result.Framework != tt.framework {
+				t.Errorf("expected framework %q, got %q", tt.framework, result.Framework)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package inspectors
+
+import (
+	"regexp"
+
+	"github.com/kloudmate/polylang-detector/detector/process"
+)
+
+// shebangLanguages maps interpreter names found in a script's shebang line to a language
+var shebangLanguages = []struct {
+	pattern  *regexp.Regexp
+	language Language
+}{
+	{regexp.MustCompile(`^python[\d.]*$`), LanguagePython},
+	{regexp.MustCompile(`^ruby[\d.]*$`), LanguageRuby},
+	{regexp.MustCompile(`^(node|nodejs)$`), LanguageNodeJS},
+	{regexp.MustCompile(`^php[\d.]*$`), LanguagePHP},
+	{regexp.MustCompile(`^(java|jshell)$`), LanguageJava},
+	{regexp.MustCompile(`^dotnet$`), LanguageDotNet},
+}
+
+// shebangLanguage returns the language run by the named interpreter, or LanguageUnknown
+func shebangLanguage(interpreter string) Language {
+	for _, entry := range shebangLanguages {
+		if entry.pattern.MatchString(interpreter) {
+			return entry.language
+		}
+	}
+	return LanguageUnknown
+}
+
+// detectFromShebang detects the language of a process running a script directly by
+// reading the script's shebang line. The result is medium confidence since the script
+// may only be a launcher for something else.
+func detectFromShebang(ctx *process.ProcessContext) *DetectionResult {
+	language := shebangLanguage(process.ScriptInterpreter(ctx))
+	if language == LanguageUnknown {
+		return nil
+	}
+
+	return &DetectionResult{
+		Language:   language,
+		Confidence: ConfidenceMedium,
+	}
+}
@@ -0,0 +1,27 @@
+package inspectors
+
+import "testing"
+
+func TestShebangLanguage(t *testing.T) {
+	tests := []struct {
+		interpreter string
+		want        Language
+	}{
+		{"python3", LanguagePython},
+		{"python3.11", LanguagePython},
+		{"python", LanguagePython},
+		{"ruby", LanguageRuby},
+		{"node", LanguageNodeJS},
+		{"nodejs", LanguageNodeJS},
+		{"php8.2", LanguagePHP},
+		{"bash", LanguageUnknown},
+		{"sh", LanguageUnknown},
+		{"", LanguageUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := shebangLanguage(tt.interpreter); got != tt.want {
+			t.Errorf("shebangLanguage(%q) = %s, want %s", tt.interpreter, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package inspectors
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/kloudmate/polylang-detector/detector/process"
+)
+
+// wasmRuntimes maps the executable names of WebAssembly runtimes to their display names
+var wasmRuntimes = []struct {
+	exe  string
+	name string
+}{
+	{"wasmtime", "Wasmtime"},
+	{"wasmedge", "WasmEdge"},
+	{"wasmer", "Wasmer"},
+	{"spin", "Spin"},
+}
+
+// runwasiShimPrefix starts the executable names of containerd shims, whose runwasi
+// variants (e.g. containerd-shim-spin-v2) run WebAssembly workloads without a container
+const runwasiShimPrefix = "containerd-shim-"
+
+type WasmInspector struct{}
+
+func NewWasmInspector() *WasmInspector {
+	return &WasmInspector{}
+}
+
+func (w *WasmInspector) GetLanguage() Language {
+	return LanguageWasm
+}
+
+// QuickScan recognizes a WebAssembly runtime by its executable name and reports it as the
+// AppServer, with the language the module it runs was compiled from when that is recorded
+func (w *WasmInspector) QuickScan(ctx *process.ProcessContext) *DetectionResult {
+	runtime := wasmRuntime(filepath.Base(ctx.Executable))
+	if runtime == "" {
+		return nil
+	}
+
+	return &DetectionResult{
+		Language:      LanguageWasm,
+		AppServer:     runtime,
+		GuestLanguage: wasmGuestLanguage(ctx),
+		Confidence:    ConfidenceHigh,
+	}
+}
+
+func (w *WasmInspector) DeepScan(ctx *process.ProcessContext) *DetectionResult {
+	// Runtimes are recognized by name; a .wasm module opened by another process is
+	// picked up by the open-files fallback
+	return nil
+}
+
+// wasmRuntime returns the display name of the WebAssembly runtime an executable belongs
+// to, or "" if it is not one
+func wasmRuntime(exeName string) string {
+	shim, isShim := strings.CutPrefix(exeName, runwasiShimPrefix)
+	for _, runtime := range wasmRuntimes {
+		if exeName == runtime.exe || (isShim && strings.HasPrefix(shim, runtime.exe+"-")) {
+			return runtime.name
+		}
+	}
+	if isShim && strings.Contains(shim, "wasm") {
+		return "runwasi"
+	}
+	return ""
+}
+
+// wasmGuestLanguage returns the language the running module was compiled from, read from
+// its producers section, or "" when the module or the language can't be determined
+func wasmGuestLanguage(ctx *process.ProcessContext) Language {
+	module := process.WasmModule(ctx)
+	if module == "" {
+		return ""
+	}
+	producers, err := process.ReadWasmProducers(module)
+	if err != nil {
+		return ""
+	}
+
+	// "language" names the source language; toolchains that omit it still list their
+	// compiler under "processed-by"
+	for _, field := range []string{"language", "processed-by"} {
+		for _, name := range producers[field] {
+			switch name := strings.ToLower(name); {
+			case strings.HasPrefix(name, "rust"):
+				return LanguageRust
+			case name == "go" || name == "tinygo":
+				return LanguageGo
+			case name == "c#" || name == "dotnet":
+				return LanguageDotNet
+			}
+		}
+	}
+	return ""
+}
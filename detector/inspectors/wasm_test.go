@@ -0,0 +1,116 @@
+package inspectors
+
+import (
+	"testing"
+
+	"github.com/kloudmate/polylang-detector/detector/process"
+	"github.com/kloudmate/polylang-detector/internal/proctest"
+)
+
+// wasmModuleWithProducers encodes a minimal WebAssembly module holding a type section and
+// a producers custom section with the given fields
+func wasmModuleWithProducers(fields map[string]string) []byte {
+	name := func(s string) []byte { return append([]byte{byte(len(s))}, s...) }
+
+	producers := append(name("producers"), byte(len(fields)))
+	for field, value := range fields {
+		producers = append(producers, name(field)...)
+		producers = append(producers, 1)
+		producers = append(producers, name(value)...)
+		producers = append(producers, name("1.0.0")...)
+	}
+
+	module := []byte("\x00asm\x01\x00\x00\x00")
+	module = append(module, 1, 4, 1, 0x60, 0, 0) // type section: one func type () -> ()
+	module = append(module, 0, byte(len(producers)))
+	return append(module, producers...)
+}
+
+func TestWasmInspectorDetectsRuntimes(t *testing.T) {
+	root := proctest.New(t)
+
+	tests := []struct {
+		name          string
+		pid           int
+		exe, cmdline  string
+		files         map[string][]byte
+		runtime       string
+		guestLanguage Language
+	}{
+		{
+			name:    "spin up",
+			pid:     4101,
+			exe:     "/usr/local/bin/spin",
+			cmdline: "spin up --listen 0.0.0.0:80",
+			files: map[string][]byte{
+				"/app/spin.toml": []byte("spin_manifest_version = 2\n\n[component.api]\nsource = \"target/wasm32-wasip1/release/api.wasm\"\n"),
+				"/app/target/wasm32-wasip1/release/api.wasm": wasmModuleWithProducers(map[string]string{"language": "Rust", "processed-by": "rustc"}),
+			},
+			runtime:       "Spin",
+			guestLanguage: LanguageRust,
+		},
+		{
+			name:    "wasmtime app.wasm",
+			pid:     4102,
+			exe:     "/usr/bin/wasmtime",
+			cmdline: "wasmtime run --dir . app.wasm",
+			files: map[string][]byte{
+				"/app/app.wasm": wasmModuleWithProducers(map[string]string{"processed-by": "TinyGo"}),
+			},
+			runtime:       "Wasmtime",
+			guestLanguage: LanguageGo,
+		},
+		{
+			name:    "module without producers",
+			pid:     4103,
+			exe:     "/usr/bin/wasmedge",
+			cmdline: "wasmedge /app/handler.wasm",
+			files: map[string][]byte{
+				"/app/handler.wasm": []byte("\x00asm\x01\x00\x00\x00"),
+			},
+			runtime: "WasmEdge",
+		},
+		{
+			name:    "runwasi shim",
+			pid:     4104,
+			exe:     "/usr/local/bin/containerd-shim-spin-v2",
+			cmdline: "containerd-shim-spin-v2 -namespace k8s.io -id 3f2a",
+			runtime: "Spin",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proctest.WriteProcess(t, root, proctest.Process{PID: tt.pid, PPID: 1, Exe: tt.exe, Cwd: "/app", Cmdline: tt.cmdline})
+			for path, content := range tt.files {
+				proctest.WriteContainerFile(t, root, tt.pid, path, content)
+			}
+
+			ctx, err := process.GetProcessContext(tt.pid)
+			if err != nil {
+				t.Fatalf("failed to read process context: %v", err)
+			}
+			result, err := NewLanguageDetector().Detect(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Language != LanguageWasm || result.AppServer != tt.runtime {
+				t.Errorf("expected WASM on %s, got %s on %q", tt.runtime, result.Language, result.AppServer)
+			}
+			if result.GuestLanguage != tt.guestLanguage {
+				t.Errorf("expected guest language %q, got %q", tt.guestLanguage, result.GuestLanguage)
+			}
+		})
+	}
+}
+
+func TestWasmRuntimeIgnoresOtherShims(t *testing.T) {
+	for _, exe := range []string{"containerd-shim-runc-v2", "containerd-shim-kata-v2", "spinner"} {
+		if runtime := wasmRuntime(exe); runtime != "" {
+			t.Errorf("expected %s not to be a wasm runtime, got %s", exe, runtime)
+		}
+	}
+	if runtime := wasmRuntime("containerd-shim-wasmtime-v1"); runtime != "Wasmtime" {
+		t.Errorf("expected the wasmtime shim to run Wasmtime, got %q", runtime)
+	}
+}
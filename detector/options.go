@@ -0,0 +1,252 @@
+package detector
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kloudmate/polylang-detector/detector/inspectors"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DetectionOptions holds env-driven settings shared by the proc and eBPF detectors
+type DetectionOptions struct {
+	WorkloadIdentity   WorkloadIdentityConfig
+	ScanInitContainers bool
+	// ContainerConcurrency bounds how many containers of one pod are inspected in parallel,
+	// set by KM_EXEC_CONCURRENCY (KM_CONTAINER_CONCURRENCY is accepted as an alias)
+	ContainerConcurrency int
+	// ScanWorkers bounds how many pods are detected concurrently per scan cycle
+	ScanWorkers int
+	// RescanInterval and ReconcileInterval set how often the eBPF detector rescans all running
+	// pods (KM_RESCAN_INTERVAL) and reconciles its cache with the cluster (KM_RECONCILE_INTERVAL).
+	// The periodic fallback scan uses them for its rescans and processed-pod resyncs.
+	RescanInterval    time.Duration
+	ReconcileInterval time.Duration
+	// SkipContainerNames lists sidecar containers excluded from detection
+	SkipContainerNames []string
+	// IgnoredContainers matches helper containers (log shippers, config reloaders) excluded
+	// from detection, set by KM_IGNORED_CONTAINER_NAMES
+	IgnoredContainers ContainerNameMatcher
+	// MinConfidence is the lowest confidence a result needs to be sent to the config updater
+	MinConfidence inspectors.Confidence
+	// supportedLanguages maps languages enqueued for auto-instrumentation to their OTel name;
+	// nil means OtelSupportedLanguages
+	supportedLanguages map[string]string
+}
+
+// NewDetectionOptionsFromEnv builds detection options from KM_* environment variables
+func NewDetectionOptionsFromEnv() DetectionOptions {
+	return DetectionOptions{
+		WorkloadIdentity:     NewWorkloadIdentityConfigFromEnv(),
+		ScanInitContainers:   envBool("KM_SCAN_INIT_CONTAINERS", true),
+		ContainerConcurrency: envInt("KM_EXEC_CONCURRENCY", envInt("KM_CONTAINER_CONCURRENCY", 4)),
+		ScanWorkers:          envInt("KM_SCAN_WORKERS", defaultScanWorkers),
+		RescanInterval:       envDuration("KM_RESCAN_INTERVAL", podRescanInterval),
+		ReconcileInterval:    envDuration("KM_RECONCILE_INTERVAL", defaultReconcileInterval),
+		SkipContainerNames:   skipContainerNamesFromEnv(),
+		IgnoredContainers:    NewContainerNameMatcher(envList("KM_IGNORED_CONTAINER_NAMES")),
+		MinConfidence:        minConfidenceFromEnv(),
+		supportedLanguages:   supportedLanguagesFromEnv(),
+	}
+}
+
+// supportedLanguagesFromEnv reads KM_SUPPORTED_LANGUAGES, a comma-separated list of
+// languages (optionally "Language:otel-name", e.g. "Ruby,PHP:php") added to
+// OtelSupportedLanguages, or replacing it when KM_SUPPORTED_LANGUAGES_REPLACE is true.
+// It returns nil when unset.
+func supportedLanguagesFromEnv() map[string]string {
+	entries := envList("KM_SUPPORTED_LANGUAGES")
+	if len(entries) == 0 {
+		return nil
+	}
+
+	languages := make(map[string]string)
+	if !envBool("KM_SUPPORTED_LANGUAGES_REPLACE", false) {
+		maps.Copy(languages, OtelSupportedLanguages)
+	}
+
+	for _, entry := range entries {
+		language, otelName, found := strings.Cut(entry, ":")
+		language = canonicalLanguage(strings.TrimSpace(language))
+		if otelName = strings.TrimSpace(otelName); !found || otelName == "" {
+			otelName = strings.ToLower(language)
+		}
+		languages[language] = otelName
+	}
+	return languages
+}
+
+// knownLanguages are the language names reported by the inspectors
+var knownLanguages = []inspectors.Language{
+	inspectors.LanguageJava, inspectors.LanguagePython, inspectors.LanguageNodeJS, inspectors.LanguageGo,
+	inspectors.LanguageDotNet, inspectors.LanguagePHP, inspectors.LanguageRuby, inspectors.LanguageRust,
+	inspectors.LanguageWasm,
+}
+
+// canonicalLanguage matches a configured language name case-insensitively against the
+// names the inspectors report, so "ruby" and "Ruby" are equivalent
+func canonicalLanguage(name string) string {
+	for _, language := range knownLanguages {
+		if strings.EqualFold(name, string(language)) {
+			return string(language)
+		}
+	}
+	return name
+}
+
+// minConfidenceFromEnv reads KM_MIN_CONFIDENCE as a label (low/medium/high) or a numeric
+// score, returning ConfidenceNone (no threshold) when unset or invalid
+func minConfidenceFromEnv() inspectors.Confidence {
+	raw := strings.ToLower(strings.TrimSpace(os.Getenv("KM_MIN_CONFIDENCE")))
+	if score, err := strconv.Atoi(raw); err == nil && score > 0 {
+		return inspectors.Confidence(score)
+	}
+	return inspectors.ParseConfidence(raw)
+}
+
+// skipContainerNamesFromEnv reads KM_SKIP_CONTAINER_NAMES, falling back to the default sidecar list
+func skipContainerNamesFromEnv() []string {
+	if names := envList("KM_SKIP_CONTAINER_NAMES"); len(names) > 0 {
+		return names
+	}
+	return defaultSkipContainerNames
+}
+
+// ContainerNameMatcher matches container names exactly or, for "re:" entries, against a
+// regular expression that must match the whole name
+type ContainerNameMatcher struct {
+	names    []string
+	patterns []*regexp.Regexp
+	errs     []error // entries with an invalid regular expression
+}
+
+// NewContainerNameMatcher builds a matcher from entries such as "fluent-bit" or
+// "re:.*-reloader". Entries with an invalid regular expression are left out and reported
+// by Errors.
+func NewContainerNameMatcher(entries []string) ContainerNameMatcher {
+	var matcher ContainerNameMatcher
+	for _, entry := range entries {
+		pattern, isRegex := strings.CutPrefix(entry, "re:")
+		if !isRegex {
+			matcher.names = append(matcher.names, entry)
+			continue
+		}
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			matcher.errs = append(matcher.errs, fmt.Errorf("invalid container name pattern %q: %w", entry, err))
+			continue
+		}
+		matcher.patterns = append(matcher.patterns, re)
+	}
+	return matcher
+}
+
+// Errors returns why entries passed to NewContainerNameMatcher were left out
+func (m ContainerNameMatcher) Errors() []error {
+	return m.errs
+}
+
+// Matches reports whether name is one of the matcher's names or matches one of its patterns
+func (m ContainerNameMatcher) Matches(name string) bool {
+	if slices.Contains(m.names, name) {
+		return true
+	}
+	for _, re := range m.patterns {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// SkipsContainer reports whether a container is on the sidecar skip-list or ignored
+func (o DetectionOptions) SkipsContainer(name string) bool {
+	return slices.Contains(o.SkipContainerNames, name) || o.IgnoredContainers.Matches(name)
+}
+
+// ShouldDetectPod reports whether a pod is worth inspecting: it must be running, not
+// terminating, and have all of its app containers ready. Completed Job pods, failed pods,
+// and pods still starting up are skipped; the latter are picked up once they become ready.
+// Skip-listed and ignored containers are never inspected, so their readiness doesn't count.
+func (o DetectionOptions) ShouldDetectPod(pod *corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+		return false
+	}
+
+	ready := make(map[string]bool, len(pod.Status.ContainerStatuses))
+	for _, status := range pod.Status.ContainerStatuses {
+		ready[status.Name] = status.Ready
+	}
+	for _, container := range pod.Spec.Containers {
+		if !ready[container.Name] && !o.SkipsContainer(container.Name) {
+			return false
+		}
+	}
+	return true
+}
+
+// containersToScan returns the pod's containers to inspect, honoring ScanInitContainers
+// and dropping skip-listed sidecars, ignored containers and containers that have
+// terminated, such as completed init containers, which have no processes left to inspect.
+// Native sidecars are init containers that keep running, so they are still scanned.
+func (o DetectionOptions) containersToScan(pod *corev1.Pod) []podContainer {
+	var containers []podContainer
+	for _, pc := range podContainers(pod, o.ScanInitContainers) {
+		if !o.SkipsContainer(pc.Container.Name) && !containerTerminated(pod, pc.Container.Name) {
+			containers = append(containers, pc)
+		}
+	}
+	return containers
+}
+
+// SupportedLanguages returns the languages enqueued for auto-instrumentation, mapped to
+// their OTel language name. The returned map must not be modified.
+func (o DetectionOptions) SupportedLanguages() map[string]string {
+	if o.supportedLanguages == nil {
+		return OtelSupportedLanguages
+	}
+	return o.supportedLanguages
+}
+
+// ShouldEnqueue reports whether a result is sent to the config updater: its language must
+// support auto-instrumentation and its confidence must meet MinConfidence. Results held
+// back by the threshold are logged so they remain visible; unsupported languages and musl
+// workloads are reported by PolylangDetector.ShouldEnqueue.
+func (o DetectionOptions) ShouldEnqueue(info ContainerInfo, logger *zap.Logger) bool {
+	if _, ok := o.SupportedLanguages()[info.Language]; !ok {
+		return false
+	}
+
+	if score := info.confidenceScore(); score < o.MinConfidence {
+		logger.Info("Result below minimum confidence, not enqueueing",
+			zap.String("namespace", info.Namespace),
+			zap.String("pod", info.PodName),
+			zap.String("container", info.ContainerName),
+			zap.String("language", info.Language),
+			zap.Stringer("confidence", score),
+			zap.Stringer("min_confidence", o.MinConfidence),
+		)
+		return false
+	}
+
+	return true
+}
+
+// FilterEnqueueable returns the containers ShouldEnqueue would send, without logging, so
+// the periodic cache sync applies the same language and confidence gate as the queue
+func (o DetectionOptions) FilterEnqueueable(containers []ContainerInfo) []ContainerInfo {
+	var enqueueable []ContainerInfo
+	for _, info := range containers {
+		if _, ok := o.SupportedLanguages()[info.Language]; ok && info.confidenceScore() >= o.MinConfidence {
+			enqueueable = append(enqueueable, info)
+		}
+	}
+	return enqueueable
+}
@@ -0,0 +1,111 @@
+package detector
+
+import (
+	"testing"
+
+	"github.com/kloudmate/polylang-detector/detector/inspectors"
+	"github.com/kloudmate/polylang-detector/detector/process"
+	"github.com/kloudmate/polylang-detector/internal/elftest"
+	"github.com/kloudmate/polylang-detector/pkg/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSupportedLanguagesFromEnvAugmentsDefaults(t *testing.T) {
+	t.Setenv("KM_SUPPORTED_LANGUAGES", "ruby, PHP:php-fpm")
+
+	opts := NewDetectionOptionsFromEnv()
+	languages := opts.SupportedLanguages()
+
+	if languages["Ruby"] != "ruby" || languages["PHP"] != "php-fpm" {
+		t.Errorf("expected Ruby and PHP to be added, got %v", languages)
+	}
+	if _, ok := languages["Java"]; !ok {
+		t.Error("expected default languages to be kept")
+	}
+
+	info := ContainerInfo{Language: "Ruby"}
+	info.setConfidence(inspectors.ConfidenceHigh)
+	if !opts.ShouldEnqueue(info, zap.NewNop()) {
+		t.Error("expected Ruby result to be enqueued")
+	}
+}
+
+func TestSupportedLanguagesFromEnvReplacesDefaults(t *testing.T) {
+	t.Setenv("KM_SUPPORTED_LANGUAGES", "Python")
+	t.Setenv("KM_SUPPORTED_LANGUAGES_REPLACE", "true")
+
+	opts := NewDetectionOptionsFromEnv()
+	if len(opts.SupportedLanguages()) != 1 {
+		t.Fatalf("expected only Python, got %v", opts.SupportedLanguages())
+	}
+
+	info := ContainerInfo{Language: "Java"}
+	info.setConfidence(inspectors.ConfidenceHigh)
+	if opts.ShouldEnqueue(info, zap.NewNop()) {
+		t.Error("expected Java result to be held back once replaced")
+	}
+}
+
+func TestShouldEnqueueWarnsAboutMuslWorkloads(t *testing.T) {
+	tests := []struct {
+		name        string
+		interpreter string
+		wantWarning bool
+	}{
+		{"musl", "/lib/ld-musl-x86_64.so.1", true},
+		{"glibc", "/lib64/ld-linux-x86-64.so.2", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exe := elftest.Write(t, "python3", elftest.Options{Interpreter: tt.interpreter, Symbols: []string{"main"}})
+			info := ContainerInfo{Language: "Python"}
+			info.setConfidence(inspectors.ConfidenceHigh)
+			info.setBinaryInfo(&process.ProcessContext{PID: -1, Executable: exe})
+
+			if info.NeedsMuslInstrumentation != tt.wantWarning {
+				t.Errorf("expected NeedsMuslInstrumentation %v for %s, got %v", tt.wantWarning, info.LibcType, info.NeedsMuslInstrumentation)
+			}
+
+			core, logs := observer.New(zapcore.WarnLevel)
+			pd := newTestDetector()
+			pd.DomainLogger = &logger.DomainLogger{Logger: zap.New(core)}
+			pd.Options = NewDetectionOptionsFromEnv()
+			for range 3 {
+				if !pd.ShouldEnqueue(info, zap.NewNop()) {
+					t.Fatal("expected the Python result to be enqueued")
+				}
+			}
+			if warned := logs.FilterField(zap.String("event", "detection.musl_libc")).Len() == 1; warned != tt.wantWarning {
+				t.Errorf("expected a single musl warning %v, got %d", tt.wantWarning, logs.Len())
+			}
+		})
+	}
+}
+
+func TestMuslInfrastructureIsNotFlagged(t *testing.T) {
+	exe := elftest.Write(t, "redis-server", elftest.Options{Interpreter: "/lib/ld-musl-x86_64.so.1", Symbols: []string{"main"}})
+	info := ContainerInfo{Language: "Redis"}
+	info.setBinaryInfo(&process.ProcessContext{PID: -1, Executable: exe})
+
+	if info.LibcType != "musl" {
+		t.Fatalf("expected musl libc, got %q", info.LibcType)
+	}
+	if info.NeedsMuslInstrumentation {
+		t.Error("expected infrastructure not to need musl instrumentation")
+	}
+}
+
+func TestContainerConcurrencyFromEnv(t *testing.T) {
+	t.Setenv("KM_CONTAINER_CONCURRENCY", "2")
+	if got := NewDetectionOptionsFromEnv().ContainerConcurrency; got != 2 {
+		t.Errorf("expected the KM_CONTAINER_CONCURRENCY alias to apply, got %d", got)
+	}
+
+	t.Setenv("KM_EXEC_CONCURRENCY", "8")
+	if got := NewDetectionOptionsFromEnv().ContainerConcurrency; got != 8 {
+		t.Errorf("expected KM_EXEC_CONCURRENCY to take precedence, got %d", got)
+	}
+}
@@ -0,0 +1,82 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// maxOwnerDepth bounds the owner walk so a malformed reference cycle can't loop forever
+const maxOwnerDepth = 10
+
+// ownerGetters fetch the controller kinds whose own owners can be followed
+var ownerGetters = map[string]func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (metav1.Object, error){
+	"ReplicaSet": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (metav1.Object, error) {
+		return clientset.AppsV1().ReplicaSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	},
+	"Deployment": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (metav1.Object, error) {
+		return clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	},
+	"StatefulSet": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (metav1.Object, error) {
+		return clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	},
+	"DaemonSet": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (metav1.Object, error) {
+		return clientset.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	},
+	"Job": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (metav1.Object, error) {
+		return clientset.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	},
+	"CronJob": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (metav1.Object, error) {
+		return clientset.BatchV1().CronJobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	},
+	"ReplicationController": func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (metav1.Object, error) {
+		return clientset.CoreV1().ReplicationControllers(namespace).Get(ctx, name, metav1.GetOptions{})
+	},
+}
+
+// workloadExists reports whether a workload as named by ResolveTopOwner still exists.
+// Kinds it can't fetch (e.g. custom resources) are assumed to exist, so they aren't dropped
+// from the cache on every reconcile.
+func workloadExists(ctx context.Context, clientset kubernetes.Interface, namespace, name, kind string) bool {
+	if kind == "Pod" {
+		_, err := clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+		return err == nil
+	}
+
+	getWorkload, ok := ownerGetters[kind]
+	if !ok {
+		return true
+	}
+	_, err := getWorkload(ctx, clientset, namespace, name)
+	return err == nil
+}
+
+// ResolveTopOwner walks the pod's controller owner references (e.g. Pod -> ReplicaSet -> Deployment,
+// Pod -> Job -> CronJob) until it reaches an object without a controller, and returns that
+// object's name and kind. A standalone pod resolves to itself with kind "Pod".
+// Owners of kinds it can't fetch (e.g. custom resources) are treated as the top of the chain.
+// If fetching an owner fails, the deepest owner resolved so far is returned with the error.
+func ResolveTopOwner(clientset kubernetes.Interface, namespace string, pod *corev1.Pod) (string, string, error) {
+	name, kind := pod.Name, "Pod"
+	ownerRef := metav1.GetControllerOf(pod)
+
+	for depth := 0; ownerRef != nil && depth < maxOwnerDepth; depth++ {
+		name, kind = ownerRef.Name, ownerRef.Kind
+
+		getOwner, ok := ownerGetters[kind]
+		if !ok {
+			break
+		}
+
+		owner, err := getOwner(context.TODO(), clientset, namespace, name)
+		if err != nil {
+			return name, kind, fmt.Errorf("failed to get %s %s: %w", kind, name, err)
+		}
+		ownerRef = metav1.GetControllerOf(owner)
+	}
+
+	return name, kind, nil
+}
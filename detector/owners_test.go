@@ -0,0 +1,76 @@
+package detector
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestResolveTopOwner(t *testing.T) {
+	tests := []struct {
+		name         string
+		objects      []runtime.Object
+		pod          *corev1.Pod
+		expectedName string
+		expectedKind string
+		expectErr    bool
+	}{
+		{
+			name:         "standalone pod",
+			pod:          &corev1.Pod{ObjectMeta: ownedBy("debug", "default", "", "")},
+			expectedName: "debug",
+			expectedKind: "Pod",
+		},
+		{
+			name: "deployment via replicaset",
+			objects: []runtime.Object{
+				&appsv1.ReplicaSet{ObjectMeta: ownedBy("api-7d9f", "default", "Deployment", "api")},
+				&appsv1.Deployment{ObjectMeta: ownedBy("api", "default", "", "")},
+			},
+			pod:          &corev1.Pod{ObjectMeta: ownedBy("api-7d9f-abcde", "default", "ReplicaSet", "api-7d9f")},
+			expectedName: "api",
+			expectedKind: "Deployment",
+		},
+		{
+			name: "cronjob via job",
+			objects: []runtime.Object{
+				&batchv1.Job{ObjectMeta: ownedBy("report-28461230", "default", "CronJob", "report")},
+				&batchv1.CronJob{ObjectMeta: ownedBy("report", "default", "", "")},
+			},
+			pod:          &corev1.Pod{ObjectMeta: ownedBy("report-28461230-abcde", "default", "Job", "report-28461230")},
+			expectedName: "report",
+			expectedKind: "CronJob",
+		},
+		{
+			name:         "custom controller stops the walk",
+			pod:          &corev1.Pod{ObjectMeta: ownedBy("worker-0", "default", "Rollout", "worker")},
+			expectedName: "worker",
+			expectedKind: "Rollout",
+		},
+		{
+			name:         "missing owner returns deepest known owner",
+			pod:          &corev1.Pod{ObjectMeta: ownedBy("api-7d9f-abcde", "default", "ReplicaSet", "api-7d9f")},
+			expectedName: "api-7d9f",
+			expectedKind: "ReplicaSet",
+			expectErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(tt.objects...)
+
+			name, kind, err := ResolveTopOwner(clientset, tt.pod.Namespace, tt.pod)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("ResolveTopOwner() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if name != tt.expectedName || kind != tt.expectedKind {
+				t.Errorf("ResolveTopOwner() = %s/%s, want %s/%s", name, kind, tt.expectedName, tt.expectedKind)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package detector
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// defaultReconcileInterval is how often the eBPF detector reconciles its cache with the cluster
+const defaultReconcileInterval = 5 * time.Minute
+
+// cycleJitter is the largest fraction of the interval added to each wait between cycles, so
+// the rescan and reconcile loops (and detectors on different nodes) drift apart over time
+const cycleJitter = 0.1
+
+// periodicCycle runs a scan or reconcile cycle on an interval. Cycles run in the background,
+// and a tick arriving while the previous cycle is still running is skipped, so slow cycles on
+// large clusters don't pile up.
+type periodicCycle struct {
+	name     string
+	interval time.Duration
+	run      func(ctx context.Context)
+	logger   *zap.Logger
+	running  atomic.Bool
+	inFlight sync.WaitGroup
+}
+
+// trigger starts a cycle in the background, or logs and returns false if one is still running
+func (c *periodicCycle) trigger(ctx context.Context) bool {
+	if !c.running.CompareAndSwap(false, true) {
+		c.logger.Warn("Skipping cycle, previous cycle is still running",
+			zap.String("cycle", c.name),
+			zap.Duration("interval", c.interval),
+		)
+		return false
+	}
+
+	c.inFlight.Add(1)
+	go func() {
+		defer c.inFlight.Done()
+		defer c.running.Store(false)
+		c.run(ctx)
+	}()
+	return true
+}
+
+// loop triggers a cycle every interval, plus jitter, until ctx is done, then waits for the
+// cycle in flight to finish
+func (c *periodicCycle) loop(ctx context.Context) {
+	timer := time.NewTimer(jittered(c.interval))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			c.inFlight.Wait()
+			return
+		case <-timer.C:
+			c.trigger(ctx)
+			timer.Reset(jittered(c.interval))
+		}
+	}
+}
+
+// RunPeriodic runs fn right away and then every interval, plus jitter, until ctx is done,
+// skipping ticks while the previous run is still going. It returns once the run in flight
+// has finished.
+func RunPeriodic(ctx context.Context, name string, interval time.Duration, fn func(ctx context.Context), logger *zap.Logger) {
+	cycle := &periodicCycle{
+		name:     name,
+		interval: interval,
+		run:      fn,
+		logger:   logger,
+	}
+	cycle.trigger(ctx)
+	cycle.loop(ctx)
+}
+
+// jittered returns interval plus a random extra of up to cycleJitter of it
+func jittered(interval time.Duration) time.Duration {
+	if maxJitter := int64(float64(interval) * cycleJitter); maxJitter > 0 {
+		return interval + time.Duration(rand.Int64N(maxJitter))
+	}
+	return interval
+}
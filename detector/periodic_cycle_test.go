@@ -0,0 +1,118 @@
+package detector
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestPeriodicCycleSkipsWhileRunning(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+	release := make(chan struct{})
+	finished := make(chan struct{}, 2)
+	cycle := &periodicCycle{
+		name:     "pod rescan",
+		interval: time.Minute,
+		run: func(context.Context) {
+			<-release
+			finished <- struct{}{}
+		},
+		logger: zap.New(core),
+	}
+
+	if !cycle.trigger(context.Background()) {
+		t.Fatal("expected the first cycle to start")
+	}
+	if cycle.trigger(context.Background()) {
+		t.Fatal("expected a cycle not to start while the previous one is running")
+	}
+	skipped := logs.FilterMessage("Skipping cycle, previous cycle is still running").All()
+	if len(skipped) != 1 || skipped[0].ContextMap()["cycle"] != "pod rescan" {
+		t.Errorf("expected the skipped cycle to be logged, got %+v", logs.All())
+	}
+
+	close(release)
+	<-finished
+	deadline := time.Now().Add(5 * time.Second)
+	for cycle.running.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("cycle still marked running after it finished")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if !cycle.trigger(context.Background()) {
+		t.Error("expected a cycle to start once the previous one finished")
+	}
+	<-finished
+}
+
+func TestPeriodicCycleLoopNeverOverlaps(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+	var active, maxActive, runs atomic.Int32
+	cycle := &periodicCycle{
+		name:     "cache reconciliation",
+		interval: time.Millisecond,
+		run: func(context.Context) {
+			n := active.Add(1)
+			for {
+				if current := maxActive.Load(); n <= current || maxActive.CompareAndSwap(current, n) {
+					break
+				}
+			}
+			// Each cycle outlasts many ticks
+			time.Sleep(20 * time.Millisecond)
+			active.Add(-1)
+			runs.Add(1)
+		},
+		logger: zap.New(core),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	cycle.loop(ctx)
+
+	if maxActive.Load() != 1 {
+		t.Errorf("expected cycles never to overlap, saw %d running at once", maxActive.Load())
+	}
+	if runs.Load() == 0 || logs.Len() == 0 {
+		t.Errorf("expected cycles to run and ticks during them to be skipped, got %d runs and %d skips", runs.Load(), logs.Len())
+	}
+}
+
+func TestRunPeriodicRunsImmediately(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var runs atomic.Int32
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		RunPeriodic(ctx, "fallback pod rescan", time.Hour, func(context.Context) {
+			runs.Add(1)
+			cancel()
+		}, zap.NewNop())
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected RunPeriodic to return once its context was cancelled")
+	}
+	if runs.Load() != 1 {
+		t.Errorf("expected one run before the first interval elapsed, got %d", runs.Load())
+	}
+}
+
+func TestJitteredStaysWithinBounds(t *testing.T) {
+	interval := time.Minute
+	for range 100 {
+		if wait := jittered(interval); wait < interval || wait >= interval+6*time.Second {
+			t.Fatalf("expected a wait within 10%% above %s, got %s", interval, wait)
+		}
+	}
+	if wait := jittered(5 * time.Nanosecond); wait != 5*time.Nanosecond {
+		t.Errorf("expected tiny intervals to be left unjittered, got %s", wait)
+	}
+}
@@ -0,0 +1,138 @@
+package detector
+
+import (
+	"path"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// PodDetectionResult is a pod's per-container detections together with the language
+// reported for the pod as a whole, so a sidecar's language isn't taken for the pod's
+type PodDetectionResult struct {
+	Namespace string `json:"namespace"`
+	PodName   string `json:"pod_name"`
+	// PrimaryContainer is the container whose language is the pod's, "" when no
+	// container has a known language
+	PrimaryContainer string          `json:"primary_container,omitempty"`
+	PrimaryLanguage  string          `json:"primary_language"`
+	Containers       []ContainerInfo `json:"containers"`
+}
+
+// NewPodDetectionResult aggregates the container detections of a pod, in spec order.
+// Sidecars are recognized with the configured skip-list and ignored containers as well as
+// the known sidecar images.
+func NewPodDetectionResult(namespace, podName string, containers []ContainerInfo, options DetectionOptions) PodDetectionResult {
+	result := PodDetectionResult{
+		Namespace:       namespace,
+		PodName:         podName,
+		PrimaryLanguage: "Unknown",
+		Containers:      containers,
+	}
+	if primary, found := options.primaryContainer(containers); found {
+		result.PrimaryContainer = primary.ContainerName
+		result.PrimaryLanguage = primary.Language
+	}
+	return result
+}
+
+// SendPodDetectionResult hands a pod's result to the updater goroutine, which pushes it so
+// the updater gets the pod's language alongside the per-container batches. Pods none of
+// whose containers would be enqueued are not sent, and results are dropped when PodResults
+// is full rather than stalling detection.
+func (pd *PolylangDetector) SendPodDetectionResult(result PodDetectionResult) {
+	if pd.PodResults == nil || len(pd.Options.FilterEnqueueable(result.Containers)) == 0 {
+		return
+	}
+
+	select {
+	case pd.PodResults <- result:
+	default:
+		pd.Logger.Warn("Pod result queue full, dropping pod detection result",
+			zap.String("namespace", result.Namespace),
+			zap.String("pod", result.PodName),
+		)
+	}
+}
+
+// PushPodDetectionResult sends a pod's result to the updater. It must only be called from
+// the goroutine that owns RpcClient; like workload summaries, results are skipped while
+// disconnected.
+func (pd *PolylangDetector) PushPodDetectionResult(result PodDetectionResult) {
+	if pd.ServerAddr == "" || pd.RpcClient == nil {
+		return
+	}
+
+	var reply string
+	if err := pd.callOptional("RPCHandler.PushPodDetectionResults", []PodDetectionResult{result}, &reply); err != nil {
+		pd.Logger.Warn("Failed to send pod detection result",
+			zap.String("namespace", result.Namespace),
+			zap.String("pod", result.PodName),
+			zap.Error(err),
+		)
+	}
+}
+
+// primaryContainer picks the container that speaks for its pod: among containers with a
+// known language, the highest confidence one, then the most specific (framework, then
+// version detected), then the first. Sidecars are only considered when the pod has no
+// other container with a known language, as in a pod that is all proxies and exporters.
+func (o DetectionOptions) primaryContainer(containers []ContainerInfo) (ContainerInfo, bool) {
+	var best, bestSidecar ContainerInfo
+	found, foundSidecar := false, false
+	for _, info := range containers {
+		if info.Language == "" || info.Language == "Unknown" {
+			continue
+		}
+		if o.isSidecarContainer(info) {
+			if !foundSidecar || moreSpecific(info, bestSidecar) {
+				bestSidecar, foundSidecar = info, true
+			}
+			continue
+		}
+		if !found || moreSpecific(info, best) {
+			best, found = info, true
+		}
+	}
+	if !found {
+		return bestSidecar, foundSidecar
+	}
+	return best, found
+}
+
+// moreSpecific reports whether a detection should be preferred over the current best
+func moreSpecific(info, best ContainerInfo) bool {
+	if info.confidenceScore() != best.confidenceScore() {
+		return info.confidenceScore() > best.confidenceScore()
+	}
+	if (info.Framework != "") != (best.Framework != "") {
+		return info.Framework != ""
+	}
+	return info.Version != "" && best.Version == ""
+}
+
+// isSidecarContainer reports whether a container supports the pod's application rather than
+// being it: init and ephemeral containers, skip-listed or ignored containers, and known
+// sidecar or metrics exporter images
+func (o DetectionOptions) isSidecarContainer(info ContainerInfo) bool {
+	if info.ContainerClass != "" && info.ContainerClass != ContainerClassApp {
+		return true
+	}
+	if o.SkipsContainer(info.ContainerName) {
+		return true
+	}
+
+	image := imageName(info.Image)
+	return slices.Contains(sidecarImages, image) ||
+		strings.HasSuffix(image, "-exporter") || strings.HasSuffix(image, "_exporter")
+}
+
+// imageName returns the last path element of an image reference, without tag or digest
+// (e.g. "cloud-sql-proxy" for "gcr.io/cloud-sql-connectors/cloud-sql-proxy:2.8.0")
+func imageName(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	name := path.Base(image)
+	name, _, _ = strings.Cut(name, ":")
+	return name
+}
@@ -0,0 +1,71 @@
+package detector
+
+import (
+	"testing"
+
+	"github.com/kloudmate/polylang-detector/detector/inspectors"
+)
+
+// sidecarOptions recognizes the default sidecar container names
+var sidecarOptions = DetectionOptions{SkipContainerNames: defaultSkipContainerNames}
+
+func TestPodDetectionResultSkipsDatastoreSidecar(t *testing.T) {
+	// The proxy sidecar comes first and is detected with high confidence too
+	proxy := cachedContainer("cloud-sql-proxy", "Go", "")
+	proxy.Image = "gcr.io/cloud-sql-connectors/cloud-sql-proxy:2.8.0"
+	proxy.ContainerClass = ContainerClassApp
+	app := cachedContainer("orders", "Python", "FastAPI")
+	app.Image = "registry.example.com/shop/orders@sha256:4f1c"
+	app.ContainerClass = ContainerClassApp
+
+	result := NewPodDetectionResult("shop", "orders-7d9f", []ContainerInfo{proxy, app}, sidecarOptions)
+	if result.PrimaryContainer != "orders" || result.PrimaryLanguage != "Python" {
+		t.Errorf("expected the app container to be primary, got %s (%s)", result.PrimaryContainer, result.PrimaryLanguage)
+	}
+	if len(result.Containers) != 2 {
+		t.Errorf("expected both container detections to be kept, got %d", len(result.Containers))
+	}
+}
+
+func TestPodDetectionResultPrefersMostSpecificAppContainer(t *testing.T) {
+	worker := cachedContainer("worker", "Java", "")
+	worker.setConfidence(inspectors.ConfidenceMedium)
+	api := cachedContainer("api", "Java", "")
+	web := cachedContainer("web", "nodejs", "Express")
+	debug := cachedContainer("debugger", "Go", "Delve")
+	debug.ContainerClass = ContainerClassEphemeral
+
+	result := NewPodDetectionResult("shop", "storefront-0", []ContainerInfo{worker, api, web, debug}, sidecarOptions)
+	if result.PrimaryContainer != "web" || result.PrimaryLanguage != "nodejs" {
+		t.Errorf("expected the high-confidence container with a framework to be primary, got %s (%s)", result.PrimaryContainer, result.PrimaryLanguage)
+	}
+}
+
+func TestPodDetectionResultWithOnlySidecars(t *testing.T) {
+	exporter := cachedContainer("metrics", "Go", "")
+	exporter.Image = "quay.io/prometheuscommunity/postgres-exporter:v0.15.0"
+	exporter.setConfidence(inspectors.ConfidenceMedium)
+	mesh := cachedContainer("istio-proxy", "Go", "")
+	unknown := cachedContainer("istio-init", "Unknown", "")
+
+	result := NewPodDetectionResult("shop", "db-0", []ContainerInfo{exporter, mesh, unknown}, sidecarOptions)
+	if result.PrimaryContainer != "istio-proxy" || result.PrimaryLanguage != "Go" {
+		t.Errorf("expected the best sidecar to be primary when there is nothing else, got %s (%s)", result.PrimaryContainer, result.PrimaryLanguage)
+	}
+}
+
+func TestPodDetectionResultUsesConfiguredSidecars(t *testing.T) {
+	agent := cachedContainer("mesh-agent", "Rust", "")
+	shipper := cachedContainer("log-shipper", "Go", "")
+	app := cachedContainer("api", "Ruby", "")
+	app.setConfidence(inspectors.ConfidenceMedium)
+	options := DetectionOptions{
+		SkipContainerNames: []string{"mesh-agent"},
+		IgnoredContainers:  NewContainerNameMatcher([]string{"re:log-.*"}),
+	}
+
+	result := NewPodDetectionResult("shop", "api-0", []ContainerInfo{agent, shipper, app}, options)
+	if result.PrimaryContainer != "api" || result.PrimaryLanguage != "Ruby" {
+		t.Errorf("expected the configured sidecars to be passed over, got %s (%s)", result.PrimaryContainer, result.PrimaryLanguage)
+	}
+}
@@ -5,10 +5,15 @@ import (
 	"fmt"
 	"net/rpc"
 	"os"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/kloudmate/polylang-detector/detector/inspectors"
+	"github.com/kloudmate/polylang-detector/detector/process"
+	kmlogger "github.com/kloudmate/polylang-detector/pkg/logger"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -18,20 +23,89 @@ import (
 
 // ContainerInfo holds the detected information for a single container.
 type ContainerInfo struct {
-	PodName         string
-	Namespace       string
-	ContainerName   string
-	Image           string
-	Kind            string
-	EnvVars         map[string]string
-	ProcessCommands []string
-	DetectedAt      time.Time
-	Language        string
-	Framework       string
-	Enabled         bool
-	Confidence      string
-	DeploymentName  string
-	Evidence        []string
+	PodName         string            `json:"pod_name"`
+	Namespace       string            `json:"namespace"`
+	ContainerName   string            `json:"container_name"`
+	Image           string            `json:"image"`
+	Kind            string            `json:"kind"`
+	EnvVars         map[string]string `json:"env_vars,omitempty"`
+	ProcessCommands []string          `json:"process_commands,omitempty"`
+	DetectedAt      time.Time         `json:"detected_at"`
+	Language        string            `json:"language"`
+	Framework       string            `json:"framework,omitempty"`
+	Version         string            `json:"version,omitempty"`
+	AppServer       string            `json:"app_server,omitempty"`
+	RuntimeMode     string            `json:"runtime_mode,omitempty"`
+	ProcessRole     string            `json:"process_role,omitempty"`
+	WorkerCount     int               `json:"worker_count,omitempty"`
+	WebappContexts  []string          `json:"webapp_contexts,omitempty"`
+	GuestLanguage   string            `json:"guest_language,omitempty"` // source language of a WASM module
+	// FrameworkVersion is the detected framework's version (e.g. the Spring Boot release)
+	FrameworkVersion string            `json:"framework_version,omitempty"`
+	Enabled          bool              `json:"enabled"`
+	Confidence       string            `json:"confidence"`
+	ConfidenceScore  int               `json:"confidence_score"`
+	DeploymentName   string            `json:"deployment_name"`
+	Evidence         []string          `json:"evidence,omitempty"`
+	ServiceAccount   string            `json:"service_account,omitempty"`
+	IdentityLabels   map[string]string `json:"identity_labels,omitempty"`
+	ContainerClass   string            `json:"container_class,omitempty"`
+	Runtime          string            `json:"runtime,omitempty"` // container runtime, e.g. containerd, docker, cri-o
+	AgentDetected    string            `json:"agent_detected,omitempty"`
+	Agents           []string          `json:"agents,omitempty"`
+	// AlreadyInstrumented marks a container that already runs an OpenTelemetry agent
+	AlreadyInstrumented bool   `json:"already_instrumented,omitempty"`
+	Ports               []int  `json:"ports,omitempty"`
+	Architecture        string `json:"architecture,omitempty"`
+	LibcType            string `json:"libc_type,omitempty"`
+	// NeedsMuslInstrumentation marks a musl-linked workload that may need a musl-compatible agent build
+	NeedsMuslInstrumentation bool `json:"needs_musl_instrumentation,omitempty"`
+	// LanguageChanged marks a container whose language or framework differs from its last detection
+	LanguageChanged  bool   `json:"language_changed,omitempty"`
+	PreviousLanguage string `json:"previous_language,omitempty"`
+}
+
+// Redacted returns a copy of the detection without its environment variables, which hold
+// literal pod-spec values (often credentials), for serving outside the updater RPC path
+func (ci ContainerInfo) Redacted() ContainerInfo {
+	ci.EnvVars = nil
+	return ci
+}
+
+// setConfidence records a detection confidence as both its label and numeric score
+func (ci *ContainerInfo) setConfidence(confidence inspectors.Confidence) {
+	ci.Confidence = confidence.String()
+	ci.ConfidenceScore = int(confidence)
+}
+
+// setBinaryInfo records the CPU architecture and libc of the process's executable, which
+// determine the instrumentation artifacts that can be injected
+func (ci *ContainerInfo) setBinaryInfo(procCtx *process.ProcessContext) {
+	exe := process.ExecutableFile(procCtx)
+	analyzer := process.NewELFAnalyzer()
+	ci.Architecture, _ = analyzer.GetArchitecture(exe)
+	ci.LibcType, _ = analyzer.GetLibcType(exe)
+	// Many agents ship glibc-only native artifacts, so musl (e.g. Alpine) workloads may need a musl build
+	ci.NeedsMuslInstrumentation = ci.LibcType == "musl" && ci.Language != "" && ci.Language != "Unknown" &&
+		!infrastructureLanguages[strings.ToLower(ci.Language)]
+}
+
+// confidenceScore returns the numeric confidence, deriving it from the label for
+// entries that predate the score
+func (ci *ContainerInfo) confidenceScore() inspectors.Confidence {
+	if ci.ConfidenceScore > 0 {
+		return inspectors.Confidence(ci.ConfidenceScore)
+	}
+	return inspectors.ParseConfidence(ci.Confidence)
+}
+
+// latestEvidence returns the evidence recorded last for a detection, which names the
+// detection path that produced it, or ""
+func latestEvidence(info ContainerInfo) string {
+	if len(info.Evidence) == 0 {
+		return ""
+	}
+	return info.Evidence[len(info.Evidence)-1]
 }
 
 // PolylangDetector contains the Kubernetes client to interact with the cluster.
@@ -57,11 +131,54 @@ type PolylangDetector struct {
 	IgnoredNamespaces   []string
 	MonitoredNamespaces []string
 	Queue               chan ContainerInfo
+	PodResults          chan PodDetectionResult // pod results pushed by the updater goroutine
 	QueueSize           int
 	BatchMutex          sync.Mutex
 	Cache               *LanguageCache
+	Options             DetectionOptions
+	ScanPool            *PodScanPool
+	FailureBackoff      *FailureBackoff // delays retries of pods whose detection keeps failing
+	RetryMaxInterval    time.Duration   // caps the RPC reconnect backoff
+	Sinks               []ResultSink
+	BatchSinks          []BatchSink
+	CompressBatches     bool          // gzip batches sent to the updater (KM_RPC_COMPRESS)
+	FlushInterval       time.Duration // how often a partial batch is flushed (KM_FLUSH_INTERVAL)
+	CacheSyncInterval   time.Duration // how often all cached workloads are resent (KM_CACHE_SYNC_INTERVAL)
+	StartupDelay        time.Duration // wait before the first cache sync (KM_STARTUP_DELAY)
+	HeartbeatInterval   time.Duration // how often a heartbeat is sent to the updater (KM_HEARTBEAT_INTERVAL)
+	NodeName            string        // reported in heartbeats
+	DetectorVersion     string        // build version reported in heartbeats
+	DetectorCommit      string        // build commit reported in heartbeats
+	SendRetries         int           // push attempts per batch before it is requeued (KM_RPC_SEND_RETRIES)
+	SendRetryBackoff    time.Duration // initial delay between push attempts (KM_RPC_SEND_BACKOFF)
+	MaxRequeues         int           // times a failed batch is requeued before being dropped (KM_RPC_MAX_REQUEUES)
+	DryRun              bool          // log results instead of sending them to the updater (KM_DRY_RUN)
+
+	sentMu     sync.Mutex
+	sentHashes map[string]string // syncKey -> syncHash of the last successfully sent detection
+
+	endpointMu     sync.Mutex
+	activeEndpoint int // index into the comma-separated ServerAddr of the last healthy updater
+
+	dialMu sync.Mutex // serializes dialEndpoints
+
+	compressUnsupported atomic.Bool // the connected updater lacks PushCompressedDetectionResults
+	unsupportedMethods  sync.Map    // optional RPC methods the connected updater lacks, see callOptional
+
+	requeueMu sync.Mutex
+	requeued  []requeuedBatch // batches that failed to send, retried by the next SendBatch
+
+	reportedMu sync.Mutex
+	reported   map[string]bool // unsupported languages and musl images already logged
 }
 
+// Defaults for the RPC client intervals, used when the corresponding env var is unset or invalid
+const (
+	DefaultFlushInterval     = 10 * time.Second
+	DefaultCacheSyncInterval = 15 * time.Second
+	DefaultStartupDelay      = 10 * time.Second
+)
+
 // NewPolylangDetector creates a new language detector
 func NewPolylangDetector(config *rest.Config, client *kubernetes.Clientset, domainLogger interface {
 	LanguageDetectionStarted(namespace, podName, containerName string)
@@ -100,7 +217,12 @@ func NewPolylangDetector(config *rest.Config, client *kubernetes.Clientset, doma
 		}
 	}
 
-	loggerConfig := zap.NewProductionConfig()
+	// Share the domain logger's KM_LOG_LEVEL/KM_LOG_FORMAT settings, which also set the eBPF
+	// detector's slog level. An invalid setting is already fatal in main, so fall back to defaults.
+	loggerConfig, err := kmlogger.NewConfigFromEnv()
+	if err != nil {
+		loggerConfig = zap.NewProductionConfig()
+	}
 	logger, _ := loggerConfig.Build()
 
 	// Cache TTL - default 1 hour, configurable via env var
@@ -111,59 +233,174 @@ func NewPolylangDetector(config *rest.Config, client *kubernetes.Clientset, doma
 		}
 	}
 
+	options := NewDetectionOptionsFromEnv()
+	for _, err := range options.IgnoredContainers.Errors() {
+		logger.Warn("Ignoring KM_IGNORED_CONTAINER_NAMES entry", zap.Error(err))
+	}
+
+	cache := NewLanguageCache(cacheTTL)
+	if changeLogger, ok := domainLogger.(interface {
+		LanguageChanged(namespace, workloadName, containerName, previousLanguage, previousFramework, language, framework string)
+	}); ok {
+		cache.OnLanguageChange = func(workloadName string, previous, current ContainerInfo) {
+			changeLogger.LanguageChanged(current.Namespace, workloadName, current.ContainerName,
+				previous.Language, previous.Framework, current.Language, current.Framework)
+		}
+	}
+	if upgradeLogger, ok := domainLogger.(interface {
+		DetectionUpgraded(namespace, workloadName, containerName, language, previousConfidence, confidence, evidence string)
+	}); ok {
+		cache.OnConfidenceUpgrade = func(workloadName string, previous, current ContainerInfo) {
+			upgradeLogger.DetectionUpgraded(current.Namespace, workloadName, current.ContainerName,
+				current.Language, previous.Confidence, current.Confidence, latestEvidence(current))
+		}
+	}
+
 	return &PolylangDetector{
 		Clientset:           client,
 		Config:              config,
 		IgnoredNamespaces:   ignoredNs,
 		MonitoredNamespaces: monitoredNs,
 		ServerAddr:          addr,
+		RetryMaxInterval:    envDuration("KM_RPC_RETRY_MAX_INTERVAL", defaultRetryMaxInterval),
+		CompressBatches:     envBool("KM_RPC_COMPRESS", false),
+		FlushInterval:       envDuration("KM_FLUSH_INTERVAL", DefaultFlushInterval),
+		CacheSyncInterval:   envDuration("KM_CACHE_SYNC_INTERVAL", DefaultCacheSyncInterval),
+		StartupDelay:        envDuration("KM_STARTUP_DELAY", DefaultStartupDelay),
+		HeartbeatInterval:   envDuration("KM_HEARTBEAT_INTERVAL", DefaultHeartbeatInterval),
+		NodeName:            nodeName(),
+		SendRetries:         envInt("KM_RPC_SEND_RETRIES", DefaultSendRetries),
+		SendRetryBackoff:    envDuration("KM_RPC_SEND_BACKOFF", DefaultSendRetryBackoff),
+		MaxRequeues:         envInt("KM_RPC_MAX_REQUEUES", DefaultMaxRequeues),
+		DryRun:              envBool("KM_DRY_RUN", false),
 		Logger:              logger,
 		DomainLogger:        domainLogger,
 		Queue:               make(chan ContainerInfo, 100), // Queue with a capacity of 100
 		QueueSize:           5,                             // Batch size
-		Cache:               NewLanguageCache(cacheTTL),
+		PodResults:          make(chan PodDetectionResult, 100),
+		Cache:               cache,
+		Options:             options,
+		ScanPool:            NewPodScanPool(options.ScanWorkers),
+		FailureBackoff:      NewFailureBackoff(),
 	}
 }
 
 // SendBatch sends a batch of container info to the RPC server
 func (pd *PolylangDetector) SendBatch(batch []ContainerInfo) {
+	pd.SendBatchContext(context.TODO(), batch)
+}
+
+// SendBatchContext sends a batch of container info to the RPC server, retrying with backoff
+// until ctx is done. A batch that still fails is requeued and retried, together with any
+// other requeued batches, after the next successful send.
+func (pd *PolylangDetector) SendBatchContext(ctx context.Context, batch []ContainerInfo) {
+	batch = DeduplicateContainerInfos(batch)
 	if len(batch) == 0 {
 		return
 	}
 
-	var reply string
+	// Dry-run pushes nothing, to the updater or to the remote batch sinks
+	if pd.DryRun {
+		pd.logDryRun(batch)
+		pd.markSent(batch)
+		return
+	}
+
+	pd.writeBatchToSinks(ctx, batch)
+
+	// Sinks may be used instead of the updater, in which case no RPC address is configured
+	if pd.ServerAddr == "" {
+		pd.markSent(batch)
+		return
+	}
+
+	if !pd.pushAndRecord(ctx, requeuedBatch{batch: batch}) {
+		return
+	}
 
-	// Ensure we have a connection
-	if pd.RpcClient == nil {
-		pd.Logger.Warn("RPC client not connected, attempting reconnection")
-		if err := pd.DialWithRetry(context.TODO(), time.Second*10); err != nil {
-			pd.Logger.Error("Failed to establish RPC connection", zap.Error(err))
+	// The updater is reachable again, so flush what earlier failures left behind, skipping
+	// containers this batch already carried a newer detection for
+	sent := make(map[string]bool, len(batch))
+	for _, info := range batch {
+		sent[syncKey(info)] = true
+	}
+	pending := pd.takeRequeued()
+	for i, item := range pending {
+		item.batch = slices.DeleteFunc(item.batch, func(info ContainerInfo) bool { return sent[syncKey(info)] })
+		if len(item.batch) == 0 {
+			continue
+		}
+		if !pd.pushAndRecord(ctx, item) {
+			// Down again; keep the rest for the next attempt without counting it against them
+			for _, rest := range pending[i+1:] {
+				pd.requeue(rest)
+			}
 			return
 		}
 	}
+}
 
-	// Try to send the batch
-	err := pd.RpcClient.Call("RPCHandler.PushDetectionResults", batch, &reply)
+// logDryRun logs the results that would have been sent to the updater
+func (pd *PolylangDetector) logDryRun(batch []ContainerInfo) {
+	for _, info := range batch {
+		pd.Logger.Info("Dry run: detection result not sent",
+			zap.String("namespace", info.Namespace),
+			zap.String("workload", info.DeploymentName),
+			zap.String("kind", info.Kind),
+			zap.String("container", info.ContainerName),
+			zap.String("image", info.Image),
+			zap.String("language", info.Language),
+			zap.String("framework", info.Framework),
+			zap.String("version", info.Version),
+			zap.String("confidence", info.Confidence),
+			zap.Strings("evidence", info.Evidence),
+		)
+	}
+}
+
+// pushAndRecord sends a batch to the updater, marking it sent on success and requeueing it on failure
+func (pd *PolylangDetector) pushAndRecord(ctx context.Context, item requeuedBatch) bool {
+	reply, err := pd.pushWithRetry(ctx, item.batch)
 	if err != nil {
-		pd.DomainLogger.RPCBatchFailed(len(batch), err)
+		pd.Logger.Error("Failed to send batch, requeueing", zap.Int("count", len(item.batch)), zap.Error(err))
+		item.requeues++
+		pd.requeue(item)
+		return false
+	}
 
-		// Connection failed, try to reconnect
-		pd.RpcClient = nil // Mark connection as dead
-		if err := pd.DialWithRetry(context.TODO(), time.Second*10); err != nil {
-			pd.Logger.Error("Failed to re-establish RPC connection", zap.Error(err))
-			return
+	pd.DomainLogger.RPCBatchSent(len(item.batch), reply)
+	pd.markSent(item.batch)
+	return true
+}
+
+// DeduplicateContainerInfos collapses entries describing the same container into one
+// Entries are keyed on namespace/workload/container/image/language; on collision the
+// higher-confidence entry wins, and for equal confidence the most recent DetectedAt wins
+func DeduplicateContainerInfos(batch []ContainerInfo) []ContainerInfo {
+	if len(batch) < 2 {
+		return batch
+	}
+
+	index := make(map[string]int, len(batch))
+	deduped := make([]ContainerInfo, 0, len(batch))
+	for _, info := range batch {
+		key := strings.Join([]string{info.Namespace, info.DeploymentName, info.ContainerName, info.Image, info.Language}, "/")
+
+		i, exists := index[key]
+		if !exists {
+			index[key] = len(deduped)
+			deduped = append(deduped, info)
+			continue
 		}
 
-		// Retry sending the batch after reconnection
-		err = pd.RpcClient.Call("RPCHandler.PushDetectionResults", batch, &reply)
-		if err != nil {
-			pd.DomainLogger.RPCBatchFailed(len(batch), err)
-			pd.Logger.Error("Failed to send batch after reconnection", zap.Error(err))
-			return
+		current := deduped[i]
+		newRank, currentRank := info.confidenceScore(), current.confidenceScore()
+		if newRank > currentRank || (newRank == currentRank && info.DetectedAt.After(current.DetectedAt)) {
+			deduped[i] = info
 		}
 	}
 
-	pd.DomainLogger.RPCBatchSent(len(batch), reply)
+	return deduped
 }
 
 // ShouldMonitorNamespace determines if a namespace should be monitored based on configuration
@@ -192,10 +429,70 @@ func (pd *PolylangDetector) ShouldMonitorNamespace(namespace string) bool {
 	return true
 }
 
+// ShouldEnqueue applies DetectionOptions.ShouldEnqueue and logs, once per language, each
+// detected language that is not auto-instrumented and, once per image, each enqueued musl
+// workload, since the same workloads are re-detected every scan cycle
+func (pd *PolylangDetector) ShouldEnqueue(info ContainerInfo, logger *zap.Logger) bool {
+	if _, ok := pd.Options.SupportedLanguages()[info.Language]; !ok {
+		pd.reportUnsupportedLanguage(info.Language)
+		return false
+	}
+	if !pd.Options.ShouldEnqueue(info, logger) {
+		return false
+	}
+
+	if info.NeedsMuslInstrumentation && pd.firstReport("musl:"+info.Image) {
+		if events, ok := pd.DomainLogger.(interface {
+			MuslLibcDetected(namespace, podName, containerName, image, language string)
+		}); ok {
+			events.MuslLibcDetected(info.Namespace, info.PodName, info.ContainerName, info.Image, info.Language)
+		}
+	}
+	return true
+}
+
+// reportUnsupportedLanguage logs an unsupported language the first time it is seen: known
+// infrastructure at debug, anything else as a warning
+func (pd *PolylangDetector) reportUnsupportedLanguage(language string) {
+	if language == "" || language == string(inspectors.LanguageUnknown) || !pd.firstReport("unsupported:"+language) {
+		return
+	}
+
+	if infrastructureLanguages[strings.ToLower(language)] {
+		if events, ok := pd.DomainLogger.(interface{ InfrastructureDetected(language string) }); ok {
+			events.InfrastructureDetected(language)
+		}
+		return
+	}
+	pd.DomainLogger.UnsupportedLanguage(language)
+}
+
+// firstReport records key and reports whether it had not been logged before
+func (pd *PolylangDetector) firstReport(key string) bool {
+	pd.reportedMu.Lock()
+	defer pd.reportedMu.Unlock()
+
+	if pd.reported[key] {
+		return false
+	}
+	if pd.reported == nil {
+		pd.reported = make(map[string]bool)
+	}
+	pd.reported[key] = true
+	return true
+}
+
 // DetectLanguageWithProcInspection detects language using /proc filesystem inspection (DaemonSet mode)
 func (pd *PolylangDetector) DetectLanguageWithProcInspection(namespace, podName string) ([]ContainerInfo, error) {
+	return pd.DetectLanguageWithProcInspectionContext(context.TODO(), namespace, podName)
+}
+
+// DetectLanguageWithProcInspectionContext is DetectLanguageWithProcInspection within ctx; logs
+// carry the scan ID of the cycle ctx belongs to, if any
+func (pd *PolylangDetector) DetectLanguageWithProcInspectionContext(ctx context.Context, namespace, podName string) ([]ContainerInfo, error) {
 	procDetector := NewProcBasedDetector(pd.Clientset, pd.Cache, pd.Logger)
-	return procDetector.DetectLanguageForPod(context.TODO(), namespace, podName)
+	procDetector.Options = pd.Options
+	return procDetector.DetectLanguageForPod(ctx, namespace, podName)
 }
 
 // StartEBPFDetection starts eBPF-based real-time process detection (recommended mode)
@@ -206,69 +503,40 @@ func (pd *PolylangDetector) StartEBPFDetection(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to create eBPF detector: %w", err)
 	}
+	ebpfDetector.Options = pd.Options
+	ebpfDetector.ShouldMonitorNamespace = pd.ShouldMonitorNamespace
+	ebpfDetector.ShouldEnqueue = pd.ShouldEnqueue
+	ebpfDetector.SendPodResult = pd.SendPodDetectionResult
 
 	return ebpfDetector.Start(ctx)
 }
 
 // getPodDeploymentName finds the name of the deployment that owns a given pod.
-func getPodDeploymentName(clientset *kubernetes.Clientset, namespace, podName string) (string, error) {
+func getPodDeploymentName(clientset kubernetes.Interface, namespace, podName string) (string, error) {
 	// Get the pod object
 	pod, err := clientset.CoreV1().Pods(namespace).Get(context.TODO(), podName, metav1.GetOptions{})
 	if err != nil {
 		return "", fmt.Errorf("failed to get pod %s: %w", podName, err)
 	}
 
-	// Find the pod's owner, which is typically a ReplicaSet, DaemonSet, or StatefulSet
-	ownerRef := metav1.GetControllerOf(pod)
-	if ownerRef == nil {
-		return "Standalone Pod", nil
-	}
-
-	// If the owner is a ReplicaSet, we need to go up one more level to find the Deployment
-	if ownerRef.Kind == "ReplicaSet" {
-		replicaSet, err := clientset.AppsV1().ReplicaSets(namespace).Get(context.TODO(), ownerRef.Name, metav1.GetOptions{})
-		if err != nil {
-			return "", fmt.Errorf("failed to get ReplicaSet %s: %w", ownerRef.Name, err)
-		}
-
-		rsOwnerRef := metav1.GetControllerOf(replicaSet)
-		if rsOwnerRef == nil {
-			return "ReplicaSet", nil // The ReplicaSet is a top-level owner
-		}
-		return rsOwnerRef.Name, nil
-	}
+	name, _, err := resolvePodWorkload(clientset, pod)
+	return name, err
+}
 
-	// For DaemonSets and StatefulSets, the pod's owner is the top-level controller
-	if ownerRef.Kind == "DaemonSet" || ownerRef.Kind == "StatefulSet" {
-		return ownerRef.Name, nil
+// resolvePodWorkload returns the name and kind of a pod's top-level owner, naming pods
+// without a controller "Standalone Pod"
+func resolvePodWorkload(clientset kubernetes.Interface, pod *corev1.Pod) (string, string, error) {
+	name, kind, err := ResolveTopOwner(clientset, pod.Namespace, pod)
+	if kind == "Pod" {
+		return "Standalone Pod", kind, nil
 	}
-
-	return ownerRef.Name, fmt.Errorf("unknown owner kind: %s for pod %s", ownerRef.Kind, podName)
+	return name, kind, err
 }
 
-// getWorkloadInfo returns the workload name and kind for a pod
+// getWorkloadInfo returns the workload name and kind for a pod by resolving its top-level owner
 // For pods owned by ReplicaSets managed by a Deployment, it returns the Deployment name and "Deployment" kind
 // This ensures cache reconciliation works correctly by matching the actual resource type in the cluster
-func getWorkloadInfo(clientset *kubernetes.Clientset, pod *corev1.Pod) (string, string) {
-	ownerRef := metav1.GetControllerOf(pod)
-	if ownerRef == nil {
-		return pod.Name, "Pod"
-	}
-
-	// If the owner is a ReplicaSet, check if it's managed by a Deployment
-	if ownerRef.Kind == "ReplicaSet" {
-		replicaSet, err := clientset.AppsV1().ReplicaSets(pod.Namespace).Get(context.TODO(), ownerRef.Name, metav1.GetOptions{})
-		if err == nil {
-			rsOwnerRef := metav1.GetControllerOf(replicaSet)
-			if rsOwnerRef != nil && rsOwnerRef.Kind == "Deployment" {
-				// Return Deployment info for proper reconciliation
-				return rsOwnerRef.Name, "Deployment"
-			}
-		}
-		// Fallback to ReplicaSet if no Deployment found
-		return ownerRef.Name, "ReplicaSet"
-	}
-
-	// For other resource types (DaemonSet, StatefulSet, Job, etc.), use the direct owner
-	return ownerRef.Name, ownerRef.Kind
+func getWorkloadInfo(clientset kubernetes.Interface, pod *corev1.Pod) (string, string) {
+	name, kind, _ := ResolveTopOwner(clientset, pod.Namespace, pod)
+	return name, kind
 }
@@ -1,7 +1,29 @@
 package detector
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"encoding/json"
+	"net"
+	"net/rpc"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/kloudmate/polylang-detector/detector/inspectors"
+	"github.com/kloudmate/polylang-detector/pkg/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func TestShouldMonitorNamespace(t *testing.T) {
@@ -143,3 +165,429 @@ func TestShouldMonitorNamespace_EdgeCases(t *testing.T) {
 		})
 	}
 }
+
+// recordingHandler captures batches pushed over RPC
+type recordingHandler struct {
+	mu      sync.Mutex
+	batches [][]ContainerInfo
+	reject  error // returned for every batch when set
+}
+
+func (h *recordingHandler) PushDetectionResults(results []ContainerInfo, reply *string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.batches = append(h.batches, results)
+	if h.reject != nil {
+		return h.reject
+	}
+	*reply = "ok"
+	return nil
+}
+
+func (h *recordingHandler) PushCompressedDetectionResults(batch CompressedBatch, reply *string) error {
+	results, err := DecompressBatch(batch)
+	if err != nil {
+		return err
+	}
+	return h.PushDetectionResults(results, reply)
+}
+
+// startTestRPCServer serves handler on a loopback listener and returns its address
+func startTestRPCServer(t *testing.T, handler *recordingHandler) string {
+	t.Helper()
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("RPCHandler", handler); err != nil {
+		t.Fatalf("failed to register handler: %v", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go server.Accept(listener)
+
+	return listener.Addr().String()
+}
+
+// newTestDetector returns a detector with no-op loggers suitable for unit tests
+func newTestDetector() *PolylangDetector {
+	return &PolylangDetector{
+		Logger:       zap.NewNop(),
+		DomainLogger: &logger.DomainLogger{Logger: zap.NewNop()},
+	}
+}
+
+func TestNewPolylangDetectorLoggerFollowsLogLevel(t *testing.T) {
+	t.Setenv("KM_LOG_LEVEL", "debug")
+
+	pd := NewPolylangDetector(nil, nil, &logger.DomainLogger{Logger: zap.NewNop()})
+
+	if !pd.Logger.Core().Enabled(zapcore.DebugLevel) {
+		t.Error("expected the detector logger to honor KM_LOG_LEVEL=debug")
+	}
+}
+
+func TestShouldEnqueueReportsUnsupportedLanguagesOnce(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	pd := newTestDetector()
+	pd.DomainLogger = &logger.DomainLogger{Logger: zap.New(core)}
+	pd.Options = NewDetectionOptionsFromEnv()
+
+	redis := ContainerInfo{Language: "Redis"}
+	redis.setConfidence(inspectors.ConfidenceHigh)
+	for range 3 {
+		if pd.ShouldEnqueue(redis, zap.NewNop()) {
+			t.Fatal("expected Redis not to be enqueued")
+		}
+	}
+
+	if warnings := logs.FilterLevelExact(zapcore.WarnLevel).Len(); warnings != 0 {
+		t.Errorf("expected no unsupported-language warning for Redis, got %d", warnings)
+	}
+	if debug := logs.FilterField(zap.String("event", "detection.infrastructure")).Len(); debug != 1 {
+		t.Errorf("expected one infrastructure debug entry across cycles, got %d", debug)
+	}
+
+	// A genuinely unsupported runtime still deserves a warning, once per detector
+	crystal := ContainerInfo{Language: "Crystal"}
+	crystal.setConfidence(inspectors.ConfidenceHigh)
+	pd.ShouldEnqueue(crystal, zap.NewNop())
+	pd.ShouldEnqueue(crystal, zap.NewNop())
+	if warnings := logs.FilterField(zap.String("event", "detection.unsupported")).Len(); warnings != 1 {
+		t.Errorf("expected one unsupported-language warning, got %d", warnings)
+	}
+
+	// Each detector keeps its own record of what it reported
+	other := newTestDetector()
+	other.DomainLogger = &logger.DomainLogger{Logger: zap.New(core)}
+	other.ShouldEnqueue(crystal, zap.NewNop())
+	if warnings := logs.FilterField(zap.String("event", "detection.unsupported")).Len(); warnings != 2 {
+		t.Errorf("expected a second detector to warn again, got %d warnings", warnings)
+	}
+}
+
+func TestDeduplicateContainerInfos(t *testing.T) {
+	now := time.Now()
+	base := ContainerInfo{
+		Namespace:      "default",
+		DeploymentName: "api",
+		ContainerName:  "app",
+		Image:          "api:1.0",
+		Language:       "Java",
+	}
+
+	older := base
+	older.Confidence = "high"
+	older.DetectedAt = now.Add(-time.Minute)
+
+	newer := base
+	newer.Confidence = "high"
+	newer.DetectedAt = now
+
+	weaker := base
+	weaker.Confidence = "medium"
+	weaker.DetectedAt = now.Add(time.Minute)
+
+	other := base
+	other.ContainerName = "sidecar"
+	other.Confidence = "low"
+
+	result := DeduplicateContainerInfos([]ContainerInfo{older, weaker, other, newer})
+	if len(result) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(result))
+	}
+	if !result[0].DetectedAt.Equal(now) || result[0].Confidence != "high" {
+		t.Errorf("expected latest high-confidence entry to win, got %+v", result[0])
+	}
+	if result[1].ContainerName != "sidecar" {
+		t.Errorf("expected distinct container to be kept, got %+v", result[1])
+	}
+}
+
+func TestSendBatchDeduplicates(t *testing.T) {
+	handler := &recordingHandler{}
+	addr := startTestRPCServer(t, handler)
+
+	client, err := rpc.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer client.Close()
+
+	pd := newTestDetector()
+	pd.ServerAddr = addr
+	pd.RpcClient = client
+
+	info := ContainerInfo{Namespace: "default", DeploymentName: "api", ContainerName: "app", Image: "api:1.0", Language: "Go", Confidence: "high"}
+	pd.SendBatch([]ContainerInfo{info, info, info})
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	if len(handler.batches) != 1 {
+		t.Fatalf("expected 1 batch, got %d", len(handler.batches))
+	}
+	if len(handler.batches[0]) != 1 {
+		t.Errorf("expected deduplicated batch of 1, got %d", len(handler.batches[0]))
+	}
+}
+
+// ownedBy returns object metadata with a controller owner reference
+func ownedBy(name, namespace, ownerKind, ownerName string) metav1.ObjectMeta {
+	controller := true
+	meta := metav1.ObjectMeta{Name: name, Namespace: namespace}
+	if ownerKind != "" {
+		meta.OwnerReferences = []metav1.OwnerReference{{Kind: ownerKind, Name: ownerName, Controller: &controller}}
+	}
+	return meta
+}
+
+func TestWorkloadInfoForBatchPods(t *testing.T) {
+	tests := []struct {
+		name         string
+		objects      []runtime.Object
+		pod          *corev1.Pod
+		expectedName string
+		expectedKind string
+	}{
+		{
+			name:         "job-owned pod",
+			objects:      []runtime.Object{&batchv1.Job{ObjectMeta: ownedBy("migrate", "default", "", "")}},
+			pod:          &corev1.Pod{ObjectMeta: ownedBy("migrate-x7k2p", "default", "Job", "migrate")},
+			expectedName: "migrate",
+			expectedKind: "Job",
+		},
+		{
+			name: "cronjob-owned pod",
+			objects: []runtime.Object{
+				&batchv1.Job{ObjectMeta: ownedBy("report-28461230", "default", "CronJob", "report")},
+				&batchv1.CronJob{ObjectMeta: ownedBy("report", "default", "", "")},
+			},
+			pod:          &corev1.Pod{ObjectMeta: ownedBy("report-28461230-abcde", "default", "Job", "report-28461230")},
+			expectedName: "report",
+			expectedKind: "CronJob",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(append(tt.objects, tt.pod)...)
+
+			name, kind := getWorkloadInfo(clientset, tt.pod)
+			if name != tt.expectedName || kind != tt.expectedKind {
+				t.Errorf("getWorkloadInfo() = %s/%s, want %s/%s", name, kind, tt.expectedName, tt.expectedKind)
+			}
+
+			depName, err := getPodDeploymentName(clientset, tt.pod.Namespace, tt.pod.Name)
+			if err != nil {
+				t.Fatalf("getPodDeploymentName() returned error: %v", err)
+			}
+			if depName != tt.expectedName {
+				t.Errorf("getPodDeploymentName() = %s, want %s", depName, tt.expectedName)
+			}
+
+			// The proc detector takes name and kind from the same top-level owner
+			if name, kind, _ := resolvePodWorkload(clientset, tt.pod); name != tt.expectedName || kind != tt.expectedKind {
+				t.Errorf("resolvePodWorkload() = %s/%s, want %s/%s", name, kind, tt.expectedName, tt.expectedKind)
+			}
+		})
+	}
+}
+
+func TestSendBatchCompressedRoundTrip(t *testing.T) {
+	handler := &recordingHandler{}
+	addr := startTestRPCServer(t, handler)
+
+	client, err := rpc.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer client.Close()
+
+	pd := newTestDetector()
+	pd.ServerAddr = addr
+	pd.RpcClient = client
+	pd.CompressBatches = true
+
+	detectedAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	batch := []ContainerInfo{
+		{Namespace: "shop", DeploymentName: "api", ContainerName: "app", Image: "api:1.0", Language: "Java", Confidence: "high", DetectedAt: detectedAt, Evidence: []string{"[proc] java process detected as Java with high confidence"}},
+		{Namespace: "shop", DeploymentName: "web", ContainerName: "app", Image: "web:2.1", Language: "nodejs", Confidence: "medium", DetectedAt: detectedAt, EnvVars: map[string]string{"NODE_ENV": "production"}},
+	}
+	pd.SendBatch(batch)
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	if len(handler.batches) != 1 {
+		t.Fatalf("expected 1 batch, got %d", len(handler.batches))
+	}
+	if !reflect.DeepEqual(handler.batches[0], batch) {
+		t.Errorf("decompressed batch differs:\n got %+v\nwant %+v", handler.batches[0], batch)
+	}
+}
+
+// legacyHandler is an updater that predates PushCompressedDetectionResults
+type legacyHandler struct {
+	mu      sync.Mutex
+	batches int
+}
+
+func (h *legacyHandler) PushDetectionResults(results []ContainerInfo, reply *string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.batches++
+	*reply = "ok"
+	return nil
+}
+
+func TestSendBatchRemembersUncompressedFallback(t *testing.T) {
+	server := rpc.NewServer()
+	handler := &legacyHandler{}
+	if err := server.RegisterName("RPCHandler", handler); err != nil {
+		t.Fatalf("failed to register handler: %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go server.Accept(listener)
+
+	client, err := rpc.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer client.Close()
+
+	core, logs := observer.New(zapcore.WarnLevel)
+	pd := newTestDetector()
+	pd.Logger = zap.New(core)
+	pd.ServerAddr = listener.Addr().String()
+	pd.RpcClient = client
+	pd.CompressBatches = true
+
+	for range 3 {
+		pd.SendBatch([]ContainerInfo{{Namespace: "shop", DeploymentName: "api", Language: "Java", DetectedAt: time.Now()}})
+	}
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	if handler.batches != 3 {
+		t.Errorf("expected every batch to arrive uncompressed, got %d", handler.batches)
+	}
+	if fallbacks := logs.FilterMessage("RPC server does not support compressed batches, sending uncompressed").Len(); fallbacks != 1 {
+		t.Errorf("expected the compressed method to be tried once, got %d fallbacks", fallbacks)
+	}
+}
+
+func TestDecompressBatchRejectsOversizedPayload(t *testing.T) {
+	var payload bytes.Buffer
+	zw := gzip.NewWriter(&payload)
+	zw.Write(make([]byte, MaxDecompressedBatchSize+1))
+	zw.Close()
+
+	_, err := DecompressBatch(CompressedBatch{Version: CompressedBatchVersion, Encoding: "gzip", Count: 1, Payload: payload.Bytes()})
+	if err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("expected the oversized batch to be rejected, got %v", err)
+	}
+}
+
+func TestUnsentChangesSkipsUnchangedWorkloads(t *testing.T) {
+	pd := newTestDetector()
+
+	api := ContainerInfo{Namespace: "shop", DeploymentName: "api", ContainerName: "app", Language: "Java", DetectedAt: time.Now()}
+	web := ContainerInfo{Namespace: "shop", DeploymentName: "web", ContainerName: "app", Language: "nodejs", DetectedAt: time.Now()}
+	pd.SendBatch([]ContainerInfo{api, web})
+
+	// Re-detection refreshes DetectedAt without changing the result
+	api.DetectedAt = time.Now().Add(time.Minute)
+	web.Framework = "Express"
+
+	changed := pd.UnsentChanges([]ContainerInfo{api, web})
+	if len(changed) != 1 || changed[0].DeploymentName != "web" {
+		t.Fatalf("expected only the changed workload, got %+v", changed)
+	}
+
+	pd.resetSent()
+	if changed := pd.UnsentChanges([]ContainerInfo{api, web}); len(changed) != 2 {
+		t.Errorf("expected a full resync after reset, got %d entries", len(changed))
+	}
+}
+
+func TestUnsentChangesIgnoresOtherReplicas(t *testing.T) {
+	pd := newTestDetector()
+
+	first := ContainerInfo{PodName: "api-7d9f-abcde", Namespace: "shop", DeploymentName: "api", ContainerName: "app", Language: "Java",
+		EnvVars: map[string]string{"POD_IP": "10.0.0.4"}}
+	pd.SendBatch([]ContainerInfo{first})
+
+	// Another replica of the same workload reports the same detection
+	second := first
+	second.PodName = "api-7d9f-fghij"
+	second.EnvVars = map[string]string{"POD_IP": "10.0.0.9"}
+	if changed := pd.UnsentChanges([]ContainerInfo{second}); len(changed) != 0 {
+		t.Errorf("expected another replica's identical detection not to be resent, got %+v", changed)
+	}
+}
+
+func TestContainerInfoJSONRoundTrip(t *testing.T) {
+	info := ContainerInfo{
+		PodName:       "checkout-7d9f",
+		Namespace:     "shop",
+		ContainerName: "app",
+		Image:         "shop/checkout:1.4",
+		Kind:          "Deployment",
+		EnvVars:       map[string]string{"JAVA_OPTS": "-Xmx512m"},
+		DetectedAt:    time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+		Language:      "Java",
+		Framework:     "Spring Boot",
+		Evidence:      []string{"[proc] java process detected as Java with high confidence"},
+	}
+	info.setConfidence(inspectors.ConfidenceHigh)
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("failed to unmarshal into map: %v", err)
+	}
+	var keys []string
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	// Stable wire names; optional fields that are unset (e.g. version) are omitted
+	want := []string{"confidence", "confidence_score", "container_name", "deployment_name", "detected_at",
+		"enabled", "env_vars", "evidence", "framework", "image", "kind", "language", "namespace", "pod_name"}
+	if !slices.Equal(keys, want) {
+		t.Errorf("unexpected JSON fields\n got: %v\nwant: %v", keys, want)
+	}
+	if fields["detected_at"] != "2025-03-01T12:00:00Z" {
+		t.Errorf("expected RFC 3339 detected_at, got %v", fields["detected_at"])
+	}
+
+	var decoded ContainerInfo
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, info) {
+		t.Errorf("JSON round trip changed the info\n got: %+v\nwant: %+v", decoded, info)
+	}
+
+	// The RPC path encodes with gob, which ignores the JSON tags
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(info); err != nil {
+		t.Fatalf("failed to gob-encode: %v", err)
+	}
+	var gobDecoded ContainerInfo
+	if err := gob.NewDecoder(&buf).Decode(&gobDecoded); err != nil {
+		t.Fatalf("failed to gob-decode: %v", err)
+	}
+	if !reflect.DeepEqual(gobDecoded, info) {
+		t.Errorf("gob round trip changed the info\n got: %+v\nwant: %+v", gobDecoded, info)
+	}
+}
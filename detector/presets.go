@@ -9,6 +9,42 @@ var OtelSupportedLanguages = map[string]string{
 	".NET":   "dotnet",
 }
 
+// defaultSkipContainerNames are service-mesh and agent sidecars whose own runtime
+// would otherwise be reported instead of the application's. KM_SKIP_CONTAINER_NAMES
+// replaces them.
+var defaultSkipContainerNames = []string{"istio-proxy", "istio-init", "linkerd-proxy", "linkerd-init", "envoy", "vault-agent", "vault-agent-init"}
+
+// sidecarImages are the image names (the last path element, without tag or digest) of
+// common sidecars: datastore client proxies, service-mesh proxies, and log and telemetry
+// agents. Unlike defaultSkipContainerNames they are still detected, but never taken for
+// the pod's language while it has another container with a known language.
+var sidecarImages = []string{
+	"cloud-sql-proxy", "gce-proxy", "alloydb-auth-proxy", "pgbouncer", "proxysql", "twemproxy",
+	"proxyv2", "envoy", "linkerd2-proxy", "vault", "fluent-bit", "fluentd", "promtail", "vector",
+	"opentelemetry-collector", "opentelemetry-collector-contrib", "otel-collector",
+}
+
+// infrastructureLanguages are (lowercased) detections of off-the-shelf infrastructure rather
+// than application runtimes. They are never auto-instrumented, so they are not worth a warning.
+var infrastructureLanguages = map[string]bool{
+	"mongodb":       true,
+	"redis":         true,
+	"nginx":         true,
+	"postgresql":    true,
+	"mysql":         true,
+	"mariadb":       true,
+	"memcached":     true,
+	"rabbitmq":      true,
+	"kafka":         true,
+	"zookeeper":     true,
+	"elasticsearch": true,
+	"haproxy":       true,
+	"envoy":         true,
+	"traefik":       true,
+	"apache":        true,
+	"etcd":          true,
+}
+
 var envVarKeywords = map[string]string{
 	"GODEBUG":                     "Go",
 	"GOENV":                       "Go",
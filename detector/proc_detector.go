@@ -2,9 +2,10 @@ package detector
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
-	"strings"
+	"path/filepath"
 	"time"
 
 	"github.com/kloudmate/polylang-detector/detector/inspectors"
@@ -21,17 +22,22 @@ type ProcBasedDetector struct {
 	LanguageDetector *inspectors.LanguageDetector
 	Cache            *LanguageCache
 	Logger           *zap.Logger
+	Options          DetectionOptions
 }
 
 // NewProcBasedDetector creates a new /proc-based language detector
 func NewProcBasedDetector(clientset *kubernetes.Clientset, cache *LanguageCache, logger *zap.Logger) *ProcBasedDetector {
 	// Set proc dir to /host/proc if running in DaemonSet with hostPID
-	if _, err := os.Stat("/host/proc"); err == nil {
+	_, err := os.Stat("/host/proc")
+	hostProc := err == nil
+	if hostProc {
 		process.SetProcDir("/host/proc")
 		logger.Info("Using /host/proc for process inspection (DaemonSet mode)")
 	} else {
 		logger.Info("Using /proc for process inspection")
 	}
+	process.SetCgroupDir(hostCgroupRoot(hostProc))
+	logger.Info("Using cgroup root for container process lookup", zap.String("cgroup_root", process.GetCgroupDir()))
 
 	return &ProcBasedDetector{
 		Clientset:        clientset,
@@ -41,30 +47,47 @@ func NewProcBasedDetector(clientset *kubernetes.Clientset, cache *LanguageCache,
 	}
 }
 
+// hostCgroupMount is where DaemonSet manifests mount the host's /sys/fs/cgroup
+const hostCgroupMount = "/host/sys/fs/cgroup"
+
+// hostCgroupRoot returns the cgroup root to build container cgroup paths from:
+// KM_HOST_CGROUP_ROOT when set, else the host mount when reading the host's /proc (and
+// the mount exists), else /sys/fs/cgroup
+func hostCgroupRoot(hostProc bool) string {
+	if root := os.Getenv("KM_HOST_CGROUP_ROOT"); root != "" {
+		return root
+	}
+	if hostProc {
+		if _, err := os.Stat(hostCgroupMount); err == nil {
+			return hostCgroupMount
+		}
+	}
+	return "/sys/fs/cgroup"
+}
+
 // DetectLanguageForPod detects languages for all containers in a pod using /proc inspection
 func (pd *ProcBasedDetector) DetectLanguageForPod(ctx context.Context, namespace, podName string) ([]ContainerInfo, error) {
+	logger := ScanLogger(ctx, pd.Logger)
 	pod, err := pd.Clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get pod: %w", err)
 	}
 
-	if pod.Status.Phase != corev1.PodRunning {
-		return nil, fmt.Errorf("pod is not running: %s", pod.Status.Phase)
+	if !pd.Options.ShouldDetectPod(pod) {
+		return nil, fmt.Errorf("pod is not ready for detection: phase %s", pod.Status.Phase)
 	}
 
-	var results []ContainerInfo
+	// Resolve the pod's top-level owner once, so the workload name and kind always agree
+	depName, ownerKind, _ := resolvePodWorkload(pd.Clientset, pod)
 
-	// Get pod's owner information
-	ownerRef := metav1.GetControllerOf(pod)
-	var ownerKind string
-	if ownerRef == nil {
-		ownerKind = "Pod"
-	} else {
-		ownerKind = ownerRef.Kind
-	}
+	// Inspect each container in the pod (app, init, and ephemeral, minus skipped sidecars) in parallel.
+	// Results are stored by index so the returned order matches the pod spec.
+	containers := pd.Options.containersToScan(pod)
+	detected := make([]*ContainerInfo, len(containers))
+	failures := make([]error, len(containers))
+	forEachContainer(containers, pd.Options.ContainerConcurrency, func(i int, pc podContainer) {
+		container := pc.Container
 
-	// For each container in the pod
-	for _, container := range pod.Spec.Containers {
 		// Check cache first
 		containerEnvVars := make(map[string]string)
 		for _, env := range container.Env {
@@ -73,65 +96,89 @@ func (pd *ProcBasedDetector) DetectLanguageForPod(ctx context.Context, namespace
 			}
 		}
 
-		if cachedInfo, found := pd.Cache.Get(container.Image, containerEnvVars); found {
-			// Update pod-specific information
-			cachedInfo.PodName = podName
-			cachedInfo.Namespace = namespace
-			cachedInfo.ContainerName = container.Name
-			cachedInfo.DetectedAt = time.Now()
-
-			// Get deployment name
-			depName, _ := getPodDeploymentName(pd.Clientset, namespace, podName)
-			cachedInfo.DeploymentName = depName
-
-			results = append(results, *cachedInfo)
-			pd.Logger.Debug("Cache hit",
+		imageRef := containerImageRef(pod, &container)
+		if cachedInfo, found := pd.Cache.Get(imageRef, containerEnvVars); found {
+			// Update pod-specific information on a copy so the cached entry isn't mutated
+			info := *cachedInfo
+			info.PodName = podName
+			info.Namespace = namespace
+			info.ContainerName = container.Name
+			info.Image = container.Image
+			info.ContainerClass = pc.Class
+			info.Runtime = containerRuntime(pod, container.Name)
+			info.DetectedAt = time.Now()
+			info.DeploymentName = depName
+			info.Kind = ownerKind
+			pd.Options.WorkloadIdentity.Apply(&info, pod)
+
+			detected[i] = &info
+			logger.Debug("Cache hit",
 				zap.String("image", container.Image),
-				zap.String("language", cachedInfo.Language),
+				zap.String("language", info.Language),
 			)
-			continue
+			return
 		}
 
-		pd.Logger.Debug("Cache miss, performing detection",
+		logger.Debug("Cache miss, performing detection",
 			zap.String("image", container.Image),
 		)
 
 		// Find container processes using /proc
 		containerInfo, err := pd.detectContainerLanguage(ctx, pod, container)
 		if err != nil {
-			pd.Logger.Error("Failed to detect language for container",
+			logger.Error("Failed to detect language for container",
 				zap.String("namespace", namespace),
 				zap.String("pod", podName),
 				zap.String("container", container.Name),
 				zap.Error(err),
 			)
-			continue
+			failures[i] = fmt.Errorf("container %s: %w", container.Name, err)
+			return
 		}
 
-		// Get deployment name
-		depName, _ := getPodDeploymentName(pd.Clientset, namespace, podName)
 		containerInfo.DeploymentName = depName
 		containerInfo.Kind = ownerKind
+		containerInfo.ContainerClass = pc.Class
+		pd.Options.WorkloadIdentity.Apply(containerInfo, pod)
 
 		// Store in cache
-		pd.Cache.Set(container.Image, containerEnvVars, *containerInfo)
-		pd.Logger.Debug("Cached detection result",
+		pd.Cache.Set(imageRef, containerEnvVars, *containerInfo)
+		logger.Debug("Cached detection result",
 			zap.String("image", container.Image),
 			zap.String("language", containerInfo.Language),
 		)
 
-		results = append(results, *containerInfo)
+		detected[i] = containerInfo
+	})
+
+	return podDetectionResults(detected, failures)
+}
+
+// podDetectionResults collects the detected containers of a pod. It fails when nothing was
+// detected and at least one container failed, so the pod is backed off instead of being
+// retried every cycle.
+func podDetectionResults(detected []*ContainerInfo, failures []error) ([]ContainerInfo, error) {
+	var results []ContainerInfo
+	for _, info := range detected {
+		if info != nil {
+			results = append(results, *info)
+		}
 	}
 
+	if err := errors.Join(failures...); len(results) == 0 && err != nil {
+		return nil, fmt.Errorf("no container could be detected: %w", err)
+	}
 	return results, nil
 }
 
 // detectContainerLanguage detects the language of a specific container
 func (pd *ProcBasedDetector) detectContainerLanguage(ctx context.Context, pod *corev1.Pod, container corev1.Container) (*ContainerInfo, error) {
+	logger := ScanLogger(ctx, pd.Logger)
 	info := &ContainerInfo{
 		PodName:       pod.Name,
 		Namespace:     pod.Namespace,
 		ContainerName: container.Name,
+		Runtime:       containerRuntime(pod, container.Name),
 		Image:         container.Image,
 		EnvVars:       make(map[string]string),
 		DetectedAt:    time.Now(),
@@ -149,26 +196,16 @@ func (pd *ProcBasedDetector) detectContainerLanguage(ctx context.Context, pod *c
 	// Strategy: Find processes in cgroup matching this container
 
 	// Get container status to find container ID
-	var containerID string
-	for _, status := range pod.Status.ContainerStatuses {
-		if status.Name == container.Name && status.ContainerID != "" {
-			// Extract container ID from containerID field
-			// Format: docker://abc123... or containerd://abc123...
-			parts := strings.Split(status.ContainerID, "://")
-			if len(parts) == 2 {
-				containerID = parts[1] // Keep full container ID (don't truncate)
-				break
-			}
-		}
-	}
+	containerID := containerStatusID(pod, container.Name)
 
 	if containerID == "" {
 		return nil, fmt.Errorf("container ID not found for %s", container.Name)
 	}
 
-	pd.Logger.Debug("Looking for container PIDs",
+	logger.Debug("Looking for container PIDs",
 		zap.String("container", container.Name),
 		zap.String("containerID", containerID),
+		zap.String("runtime", info.Runtime),
 	)
 
 	// Get PIDs for this container
@@ -181,30 +218,27 @@ func (pd *ProcBasedDetector) detectContainerLanguage(ctx context.Context, pod *c
 		return nil, fmt.Errorf("no processes found for container %s", container.Name)
 	}
 
-	// Detect language for each process and collect results
-	var detections []*inspectors.DetectionResult
-	for _, pid := range pids {
-		procCtx, err := process.GetProcessContext(pid)
-		if err != nil {
-			pd.Logger.Debug("Failed to get process context",
-				zap.Int("pid", pid),
-				zap.Error(err),
-			)
-			continue
-		}
+	// Processes in a container share its network namespace, so any PID shows its listening ports
+	info.Ports = process.ListeningPorts(pids[0])
 
-		// Run language detection
-		result, err := pd.LanguageDetector.Detect(procCtx)
+	// Detect language for each process and collect results, keeping evidence from every process.
+	// The longest-running process is inspected first so helpers started later don't win ties.
+	var detections []*inspectors.DetectionResult
+	var detectedProcs []*process.ProcessContext
+	var evidence evidenceAccumulator
+	for _, pid := range process.OrderByStartTime(pids) {
+		procCtx, result, err := detectPID(pd.LanguageDetector, pid)
 		if err != nil {
 			// Check if it's a conflict error
-			if conflictErr, ok := err.(*inspectors.ErrLanguageDetectionConflict); ok {
-				pd.Logger.Warn("Language detection conflict",
+			var conflictErr *inspectors.ErrLanguageDetectionConflict
+			if errors.As(err, &conflictErr) {
+				logger.Warn("Language detection conflict",
 					zap.Int("pid", pid),
 					zap.String("error", conflictErr.Error()),
 				)
 				continue
 			}
-			pd.Logger.Debug("Failed to detect language for process",
+			logger.Debug("Failed to detect language for process",
 				zap.Int("pid", pid),
 				zap.Error(err),
 			)
@@ -213,29 +247,64 @@ func (pd *ProcBasedDetector) detectContainerLanguage(ctx context.Context, pod *c
 
 		if result != nil && result.Language != inspectors.LanguageUnknown {
 			detections = append(detections, result)
+			detectedProcs = append(detectedProcs, procCtx)
+			evidence.Add("proc", fmt.Sprintf("%s process detected as %s with %s confidence",
+				filepath.Base(procCtx.Executable), result.Language, result.Confidence))
 		}
 	}
 
 	// Select the best detection result
 	if len(detections) == 0 {
 		info.Language = "Unknown"
-		info.Confidence = "low"
+		info.setConfidence(inspectors.ConfidenceLow)
 		return info, nil
 	}
 
-	// Use the first high-confidence detection, or the first result if no high-confidence found
-	bestResult := detections[0]
-	for _, result := range detections {
-		if result.Confidence == "high" {
-			bestResult = result
+	// Use the first high-confidence detection, or the first result if no high-confidence found.
+	// Detections are in start-time order, so the main process is preferred.
+	bestResult, bestProc := detections[0], detectedProcs[0]
+	for i, result := range detections {
+		if result.Confidence >= inspectors.ConfidenceHigh {
+			bestResult, bestProc = result, detectedProcs[i]
 			break
 		}
 	}
+	// A process manager's daemon (e.g. PM2's) runs no application code, so prefer a process it manages
+	if bestResult.ProcessRole == "manager" {
+		for i, result := range detections {
+			if result.ProcessRole != "manager" && result.Language == bestResult.Language {
+				bestResult, bestProc = result, detectedProcs[i]
+				break
+			}
+		}
+	}
+	// A pre-fork server's master carries the app's full command line, so prefer it over a worker
+	if bestResult.ProcessRole == "worker" {
+		for i, result := range detections {
+			if result.ProcessRole == "master" && result.Language == bestResult.Language {
+				bestResult, bestProc = result, detectedProcs[i]
+				break
+			}
+		}
+	}
 
 	info.Language = string(bestResult.Language)
 	info.Framework = bestResult.Framework
-	info.Confidence = bestResult.Confidence
-	info.Evidence = []string{fmt.Sprintf("Detected via /proc inspection with %s confidence", bestResult.Confidence)}
+	info.Version = bestResult.Version
+	info.FrameworkVersion = bestResult.FrameworkVersion
+	info.AppServer = bestResult.AppServer
+	info.RuntimeMode = bestResult.RuntimeMode
+	info.ProcessRole = bestResult.ProcessRole
+	info.WorkerCount = bestResult.WorkerCount
+	info.WebappContexts = bestResult.WebappContexts
+	info.GuestLanguage = string(bestResult.GuestLanguage)
+	info.AgentDetected = bestResult.AgentDetected
+	info.Agents = bestResult.Agents
+	info.AlreadyInstrumented = bestResult.AlreadyInstrumented
+	info.setConfidence(bestResult.Confidence)
+	info.setBinaryInfo(bestProc)
+	evidence.Add("proc", fmt.Sprintf("Detected via /proc inspection with %s confidence", bestResult.Confidence))
+	info.Evidence = evidence.Entries()
 
 	return info, nil
 }
@@ -0,0 +1,35 @@
+package detector
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHostCgroupRoot(t *testing.T) {
+	t.Setenv("KM_HOST_CGROUP_ROOT", "/custom/cgroup")
+	if root := hostCgroupRoot(true); root != "/custom/cgroup" {
+		t.Errorf("expected KM_HOST_CGROUP_ROOT to win, got %s", root)
+	}
+
+	t.Setenv("KM_HOST_CGROUP_ROOT", "")
+	if root := hostCgroupRoot(false); root != "/sys/fs/cgroup" {
+		t.Errorf("expected the default cgroup root without host /proc, got %s", root)
+	}
+}
+
+func TestPodDetectionResultsFailsWhenNoContainerDetected(t *testing.T) {
+	failed := errors.New("no processes found for container app")
+
+	if _, err := podDetectionResults([]*ContainerInfo{nil, nil}, []error{failed, nil}); err == nil {
+		t.Error("expected an error when every container failed or was skipped")
+	}
+
+	results, err := podDetectionResults([]*ContainerInfo{{ContainerName: "app"}, nil}, []error{nil, failed})
+	if err != nil || len(results) != 1 {
+		t.Errorf("expected the detected container despite a failed sibling, got %v, %v", results, err)
+	}
+
+	if results, err := podDetectionResults(nil, nil); err != nil || results != nil {
+		t.Errorf("expected no error for a pod without containers to scan, got %v, %v", results, err)
+	}
+}
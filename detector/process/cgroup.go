@@ -0,0 +1,108 @@
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// CgroupInfo is the Kubernetes pod and container a process belongs to, parsed from /proc/[pid]/cgroup
+type CgroupInfo struct {
+	// PodUID is the pod UID in its canonical dashed form
+	PodUID string
+	// ContainerID is the full container ID without a runtime prefix
+	ContainerID string
+}
+
+// containerScopePrefixes are the runtime prefixes of systemd container scopes
+var containerScopePrefixes = []string{"docker-", "cri-containerd-", "crio-"}
+
+// ParseCgroup extracts the pod UID and container ID from cgroup file content. It handles
+// cgroup v1 and v2 with both the cgroupfs and systemd drivers for Docker, containerd and CRI-O:
+//
+//	0::/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod<uid_with_underscores>.slice/cri-containerd-<id>.scope
+//	11:memory:/kubepods/besteffort/pod<uid>/<id>
+//	12:pids:/kubepods.slice/kubepods-pod<uid>.slice/docker-<id>.scope
+func ParseCgroup(content string) CgroupInfo {
+	var info CgroupInfo
+	for _, line := range strings.Split(content, "\n") {
+		// hierarchy-ID:controller-list:cgroup-path
+		parts := strings.SplitN(strings.TrimSpace(line), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+
+		for _, component := range strings.Split(parts[2], "/") {
+			if uid := podUIDFromComponent(component); uid != "" && info.PodUID == "" {
+				info.PodUID = uid
+			}
+			if id := containerIDFromComponent(component); id != "" && info.ContainerID == "" {
+				info.ContainerID = id
+			}
+		}
+		if info.PodUID != "" && info.ContainerID != "" {
+			break
+		}
+	}
+	return info
+}
+
+// ReadCgroup parses /proc/[pid]/cgroup, returning a zero CgroupInfo if it can't be read
+func ReadCgroup(pid int) CgroupInfo {
+	data, err := os.ReadFile(filepath.Join(procDir, strconv.Itoa(pid), "cgroup"))
+	if err != nil {
+		return CgroupInfo{}
+	}
+	return ParseCgroup(string(data))
+}
+
+// MatchesContainerID reports whether the cgroup's container ID equals containerID, which
+// may be the full ID or a short prefix of it (at least 12 characters)
+func (c CgroupInfo) MatchesContainerID(containerID string) bool {
+	if c.ContainerID == "" || len(containerID) < 12 {
+		return false
+	}
+	return strings.HasPrefix(c.ContainerID, containerID) || strings.HasPrefix(containerID, c.ContainerID)
+}
+
+// podUIDFromComponent returns the pod UID from a "pod<uid>" (cgroupfs) or
+// "kubepods-<qos>-pod<uid>.slice" (systemd) path component
+func podUIDFromComponent(component string) string {
+	component = strings.TrimSuffix(component, ".slice")
+	idx := strings.LastIndex(component, "pod")
+	if idx < 0 || (idx > 0 && component[idx-1] != '-') {
+		return ""
+	}
+	uid := strings.ReplaceAll(component[idx+len("pod"):], "_", "-")
+	if len(uid) != 36 {
+		return ""
+	}
+	return uid
+}
+
+// containerIDFromComponent returns the container ID from a "<runtime>-<id>.scope" (systemd)
+// or bare "<id>" (cgroupfs) path component
+func containerIDFromComponent(component string) string {
+	id := strings.TrimSuffix(component, ".scope")
+	for _, prefix := range containerScopePrefixes {
+		if strings.HasPrefix(id, prefix) {
+			id = strings.TrimPrefix(id, prefix)
+			break
+		}
+	}
+	if len(id) < 12 || !isHex(id) {
+		return ""
+	}
+	return id
+}
+
+// isHex reports whether s consists only of lowercase hexadecimal digits
+func isHex(s string) bool {
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+package process_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/kloudmate/polylang-detector/detector/process"
+	"github.com/kloudmate/polylang-detector/internal/proctest"
+)
+
+const (
+	testPodUID      = "8eb9b7bf-0432-40ad-ba5e-34a9fa74501a"
+	testContainerID = "3f4e5d6c7b8a91a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f7081920"
+)
+
+func TestParseCgroup(t *testing.T) {
+	podUnderscores := "8eb9b7bf_0432_40ad_ba5e_34a9fa74501a"
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"containerd v2 systemd", "0::/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod" + podUnderscores + ".slice/cri-containerd-" + testContainerID + ".scope\n"},
+		{"containerd v1 cgroupfs", "12:pids:/kubepods/besteffort/pod" + testPodUID + "/" + testContainerID + "\n11:memory:/kubepods/besteffort/pod" + testPodUID + "/" + testContainerID + "\n"},
+		{"docker v2 systemd", "0::/kubepods.slice/kubepods-pod" + podUnderscores + ".slice/docker-" + testContainerID + ".scope\n"},
+		{"docker v1 cgroupfs", "10:memory:/kubepods/burstable/pod" + testPodUID + "/" + testContainerID + "\n"},
+		{"cri-o v2 systemd", "0::/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod" + podUnderscores + ".slice/crio-" + testContainerID + ".scope\n"},
+		{"cri-o v1 systemd", "4:cpu,cpuacct:/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod" + podUnderscores + ".slice/crio-" + testContainerID + ".scope\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := process.ParseCgroup(tt.content)
+			if info.PodUID != testPodUID {
+				t.Errorf("expected pod UID %s, got %q", testPodUID, info.PodUID)
+			}
+			if info.ContainerID != testContainerID {
+				t.Errorf("expected container ID %s, got %q", testContainerID, info.ContainerID)
+			}
+			if !info.MatchesContainerID(testContainerID[:12]) {
+				t.Error("expected the short container ID to match")
+			}
+		})
+	}
+}
+
+func TestParseCgroupIgnoresHostProcess(t *testing.T) {
+	info := process.ParseCgroup("0::/system.slice/crio-conmon-" + testContainerID + ".scope\n")
+	if info.PodUID != "" || info.ContainerID != "" {
+		t.Errorf("expected no pod or container, got %+v", info)
+	}
+}
+
+func TestGetContainerPIDsMatchesProcCgroup(t *testing.T) {
+	root := proctest.New(t)
+
+	cgroups := map[int]string{
+		10: "0::/kubepods.slice/kubepods-pod8eb9b7bf_0432_40ad_ba5e_34a9fa74501a.slice/cri-containerd-" + testContainerID + ".scope\n",
+		11: "0::/kubepods.slice/kubepods-pod8eb9b7bf_0432_40ad_ba5e_34a9fa74501a.slice/cri-containerd-" + testContainerID + ".scope\n",
+		12: "0::/kubepods.slice/kubepods-pod8eb9b7bf_0432_40ad_ba5e_34a9fa74501a.slice/cri-containerd-aaaabbbbccccddddeeeeffff0000111122223333444455556666777788889999.scope\n",
+		13: "0::/init.scope\n",
+	}
+	for pid, cgroup := range cgroups {
+		proctest.WriteProcess(t, root, proctest.Process{PID: pid, Cgroup: cgroup})
+	}
+
+	pids, err := process.GetContainerPIDs(testContainerID[:12])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	slices.Sort(pids)
+	if !slices.Equal(pids, []int{10, 11}) {
+		t.Errorf("expected PIDs [10 11], got %v", pids)
+	}
+}
+
+func TestGetContainerPIDsGlobsUnderCgroupRoot(t *testing.T) {
+	proctest.New(t) // No /proc cgroup matches, forcing the glob fallback
+
+	cgroupRoot := t.TempDir()
+	previous := process.GetCgroupDir()
+	process.SetCgroupDir(cgroupRoot)
+	t.Cleanup(func() { process.SetCgroupDir(previous) })
+
+	scope := filepath.Join(cgroupRoot, "kubepods.slice", "kubepods-besteffort.slice",
+		"kubepods-besteffort-pod8eb9b7bf_0432_40ad_ba5e_34a9fa74501a.slice", "cri-containerd-"+testContainerID+".scope")
+	if err := os.MkdirAll(scope, 0o755); err != nil {
+		t.Fatalf("failed to create cgroup dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(scope, "cgroup.procs"), []byte("21\n22\n"), 0o644); err != nil {
+		t.Fatalf("failed to write cgroup.procs: %v", err)
+	}
+
+	pids, err := process.GetContainerPIDs(testContainerID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(pids, []int{21, 22}) {
+		t.Errorf("expected PIDs [21 22], got %v", pids)
+	}
+}
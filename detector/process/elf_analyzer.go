@@ -1,9 +1,11 @@
 package process
 
 import (
+	"bytes"
 	"debug/buildinfo"
 	"debug/elf"
 	"fmt"
+	"io"
 	"os"
 	"regexp"
 	"strings"
@@ -17,21 +19,354 @@ func NewELFAnalyzer() *ELFAnalyzer {
 	return &ELFAnalyzer{}
 }
 
-// IsGoBinary checks if a binary is a Go executable using buildinfo
-func (ea *ELFAnalyzer) IsGoBinary(executablePath string) (bool, string, error) {
+// IsGoBinary checks if a binary is a Go executable using buildinfo, returning its Go version
+// and the module paths of its dependencies
+func (ea *ELFAnalyzer) IsGoBinary(executablePath string) (bool, string, []string, error) {
 	if executablePath == "" {
-		return false, "", fmt.Errorf("executable path is empty")
+		return false, "", nil, fmt.Errorf("executable path is empty")
 	}
 
 	info, err := buildinfo.ReadFile(executablePath)
 	if err != nil {
-		return false, "", nil // Not a Go binary
+		return false, "", nil, nil // Not a Go binary
 	}
 
 	// Extract Go version
 	version := info.GoVersion
 
-	return true, version, nil
+	deps := make([]string, 0, len(info.Deps))
+	for _, dep := range info.Deps {
+		deps = append(deps, dep.Path)
+	}
+
+	return true, version, deps, nil
+}
+
+// goRuntimeSections are sections emitted by the Go linker even when buildinfo is absent
+var goRuntimeSections = []string{".note.go.buildid", ".gopclntab", ".go.buildinfo"}
+
+// goRuntimeSymbols are Go runtime symbols present in every unstripped Go binary
+var goRuntimeSymbols = []string{"runtime.goexit", "runtime.main", "runtime.morestack"}
+
+// HasGoRuntimeMarkers checks for Go linker sections and runtime symbols in a binary.
+// Bazel (rules_go) and other non-"go build" toolchains may omit debug/buildinfo data,
+// so IsGoBinary returns false for them; this is the fallback that still identifies Go.
+func (ea *ELFAnalyzer) HasGoRuntimeMarkers(executablePath string) (bool, error) {
+	if executablePath == "" {
+		return false, nil
+	}
+
+	elfFile, err := elf.Open(executablePath)
+	if err != nil {
+		return false, nil // Not an ELF file or can't read
+	}
+	defer elfFile.Close()
+
+	for _, name := range goRuntimeSections {
+		if elfFile.Section(name) != nil {
+			return true, nil
+		}
+	}
+
+	symbols, err := elfFile.Symbols()
+	if err == nil {
+		for _, sym := range symbols {
+			for _, goSym := range goRuntimeSymbols {
+				if sym.Name == goSym {
+					return true, nil
+				}
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// goPclntabFunctions are runtime function names kept in the pclntab of every Go binary,
+// including ones stripped of symbols, section headers and buildinfo. All of them must be
+// present, so a binary that merely mentions one (e.g. a debugger) is not taken for Go.
+var goPclntabFunctions = []string{"runtime.goexit", "runtime.gopanic", "runtime.morestack", "runtime.mstart"}
+
+// goPclntabSections are where the pclntab lives when section headers are present
+var goPclntabSections = []string{".gopclntab", ".text"}
+
+// HasGoRuntimeStrings checks a binary's pclntab for the Go runtime function names. It is
+// a weaker fallback than HasGoRuntimeMarkers for binaries whose sections and symbols were
+// stripped; without section headers the start of the file is scanned instead.
+func (ea *ELFAnalyzer) HasGoRuntimeStrings(executablePath string) (bool, error) {
+	if executablePath == "" {
+		return false, nil
+	}
+
+	scanner := newMarkerScanner(goPclntabFunctions, MaxMarkerScanBytes, len(goPclntabFunctions))
+	err := scanner.scanSections(executablePath, goPclntabSections)
+	return scanner.found == len(goPclntabFunctions), err
+}
+
+// graalNativeImageMarkers are strings embedded by GraalVM native-image (SubstrateVM) in the image heap
+var graalNativeImageMarkers = []string{"com.oracle.svm", "SubstrateVM"}
+
+// GraalImageHeapSections hold the image heap of a GraalVM native image: .svm_heap in
+// recent releases, .rodata in older ones
+var GraalImageHeapSections = []string{".svm_heap", ".rodata"}
+
+// MaxMarkerScanBytes bounds how much of a binary is scanned for embedded marker strings
+const MaxMarkerScanBytes = 64 << 20
+
+// HasGraalVMNativeImageMarkers checks if a binary was produced by GraalVM native-image.
+// Such binaries are native ELF files without libjvm, so they look like C/C++ or Go
+// executables unless the SubstrateVM image heap section or its class names are found.
+func (ea *ELFAnalyzer) HasGraalVMNativeImageMarkers(executablePath string) (bool, error) {
+	if executablePath == "" {
+		return false, nil
+	}
+
+	elfFile, err := elf.Open(executablePath)
+	if err != nil {
+		return false, nil // Not an ELF file or can't read
+	}
+	defer elfFile.Close()
+
+	if elfFile.Section(".svm_heap") != nil {
+		return true, nil
+	}
+
+	return sectionsContainAny(executablePath, GraalImageHeapSections, graalNativeImageMarkers, MaxMarkerScanBytes)
+}
+
+// dotnetNativeAOTSections are sections the .NET Native AOT compiler emits for managed code
+var dotnetNativeAOTSections = []string{".managedcode", "__managedcode"}
+
+// dotnetNativeAOTSymbols are symbol prefixes from the Native AOT runtime and compiled CoreLib
+var dotnetNativeAOTSymbols = []string{"S_P_CoreLib_", "g_compressedGlobalReadWriteDataBlob", "RhpNewFast"}
+
+// HasDotNetNativeAOTMarkers checks if a binary was published with .NET Native AOT.
+// These binaries embed the runtime and don't load libcoreclr, so they otherwise
+// look like plain C/C++ executables.
+func (ea *ELFAnalyzer) HasDotNetNativeAOTMarkers(executablePath string) (bool, error) {
+	if executablePath == "" {
+		return false, nil
+	}
+
+	elfFile, err := elf.Open(executablePath)
+	if err != nil {
+		return false, nil // Not an ELF file or can't read
+	}
+	defer elfFile.Close()
+
+	for _, name := range dotnetNativeAOTSections {
+		if elfFile.Section(name) != nil {
+			return true, nil
+		}
+	}
+
+	symbols, err := elfFile.Symbols()
+	if err == nil {
+		for _, sym := range symbols {
+			for _, prefix := range dotnetNativeAOTSymbols {
+				if strings.HasPrefix(sym.Name, prefix) {
+					return true, nil
+				}
+			}
+		}
+	}
+
+	// Stripped binaries keep the mangled CoreLib names in their string data
+	return sectionsContainAny(executablePath, []string{".rodata", ".data"}, []string{"S_P_CoreLib_"}, MaxMarkerScanBytes)
+}
+
+// nodeSEAFuse is the sentinel Node.js flips to ":1" when a single-executable
+// application blob has been injected into the binary
+const nodeSEAFuse = "NODE_SEA_FUSE_fce680ab2cc467b6e072b8b5df1996b2:1"
+
+// HasNodeSEAMarkers checks if a binary is a Node.js single-executable application (SEA).
+// SEAs are copies of the node binary under the application's own name, so neither the
+// executable name nor the cmdline mentions node.
+func (ea *ELFAnalyzer) HasNodeSEAMarkers(executablePath string) (bool, error) {
+	if executablePath == "" {
+		return false, nil
+	}
+
+	// The fuse is a string constant, so only the data sections are scanned
+	return sectionsContainAny(executablePath, []string{".rodata", ".data"}, []string{nodeSEAFuse}, MaxMarkerScanBytes)
+}
+
+// pyInstallerCookie is the magic that starts the PyInstaller archive cookie appended to the bootloader
+const pyInstallerCookie = "MEI\x0c\x0b\x0a\x0b\x0e"
+
+// pyInstallerTailBytes is how much of a binary's end is searched for the archive cookie,
+// leaving room for data (e.g. signatures) appended after the archive
+const pyInstallerTailBytes = 64 << 10
+
+// HasPyInstallerMarkers checks if a binary is a PyInstaller bundle. The bootloader is a
+// plain native executable that extracts and loads libpython at runtime, so the bundle
+// is recognized by the archive cookie at its end or the bootloader's _MEIPASS variable.
+func (ea *ELFAnalyzer) HasPyInstallerMarkers(executablePath string) (bool, error) {
+	if executablePath == "" {
+		return false, nil
+	}
+
+	elfFile, err := elf.Open(executablePath)
+	if err != nil {
+		return false, nil // Not an ELF file or can't read
+	}
+	elfFile.Close()
+
+	if found, err := fileTailContains(executablePath, pyInstallerCookie, pyInstallerTailBytes); found || err != nil {
+		return found, err
+	}
+	return sectionsContainAny(executablePath, []string{".rodata"}, []string{"_MEIPASS"}, MaxMarkerScanBytes)
+}
+
+// fileTailContains reports whether marker occurs in the last limit bytes of a file
+func fileTailContains(filePath, marker string, limit int64) (bool, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return false, err
+	}
+
+	offset := max(0, info.Size()-limit)
+	tail := make([]byte, info.Size()-offset)
+	if _, err := file.ReadAt(tail, offset); err != nil && err != io.EOF {
+		return false, err
+	}
+	return bytes.Contains(tail, []byte(marker)), nil
+}
+
+// FileContainsAny reports whether any marker occurs in the first limit bytes of a file.
+// The file is read in chunks that overlap by the longest marker so matches spanning
+// a chunk boundary are still found.
+func FileContainsAny(filePath string, markers []string, limit int64) (bool, error) {
+	found, err := countFileMarkers(filePath, markers, limit, 1)
+	return found >= 1, err
+}
+
+// FileContainsAll reports whether every marker occurs in the first limit bytes of a file
+func FileContainsAll(filePath string, markers []string, limit int64) (bool, error) {
+	found, err := countFileMarkers(filePath, markers, limit, len(markers))
+	return found == len(markers), err
+}
+
+// ScanMarkers reports which markers occur in the named sections of an ELF binary,
+// reading at most limit bytes in a single pass. Binaries stripped of their section
+// headers are scanned from the start of the file instead; other files match nothing.
+func ScanMarkers(filePath string, sections, markers []string, limit int64) ([]bool, error) {
+	scanner := newMarkerScanner(markers, limit, len(markers))
+	err := scanner.scanSections(filePath, sections)
+	return scanner.seen, err
+}
+
+// sectionsContainAny reports whether any marker occurs in the named sections of an ELF
+// binary, scanning it as ScanMarkers does
+func sectionsContainAny(filePath string, sections, markers []string, limit int64) (bool, error) {
+	scanner := newMarkerScanner(markers, limit, 1)
+	err := scanner.scanSections(filePath, sections)
+	return scanner.found >= 1, err
+}
+
+// countFileMarkers counts the distinct markers occurring in the first limit bytes of a
+// file, stopping early once want of them have been found
+func countFileMarkers(filePath string, markers []string, limit int64, want int) (int, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	scanner := newMarkerScanner(markers, limit, want)
+	err = scanner.scan(file)
+	return scanner.found, err
+}
+
+// markerScanner searches a sequence of readers for markers, sharing one byte budget
+type markerScanner struct {
+	markers   []string
+	seen      []bool
+	found     int
+	want      int
+	remaining int64
+}
+
+func newMarkerScanner(markers []string, limit int64, want int) *markerScanner {
+	return &markerScanner{markers: markers, seen: make([]bool, len(markers)), want: want, remaining: limit}
+}
+
+// done reports whether enough markers were found or the byte budget is spent
+func (s *markerScanner) done() bool {
+	return s.found >= s.want || s.remaining <= 0
+}
+
+// scanSections scans the named sections of an ELF binary, or the whole file when it has
+// no section headers
+func (s *markerScanner) scanSections(filePath string, sections []string) error {
+	elfFile, err := elf.Open(filePath)
+	if err != nil {
+		return nil // Not an ELF file or can't read
+	}
+	defer elfFile.Close()
+
+	if len(elfFile.Sections) == 0 {
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		return s.scan(file)
+	}
+
+	for _, name := range sections {
+		section := elfFile.Section(name)
+		if section == nil || section.Type == elf.SHT_NOBITS {
+			continue
+		}
+		if err := s.scan(section.Open()); err != nil || s.done() {
+			return err
+		}
+	}
+	return nil
+}
+
+// scan reads r in chunks that overlap by the longest marker, so matches spanning a
+// chunk boundary are still found
+func (s *markerScanner) scan(r io.Reader) error {
+	overlap := 0
+	for _, marker := range s.markers {
+		overlap = max(overlap, len(marker)-1)
+	}
+
+	const chunkSize = 1 << 20
+	buffer := make([]byte, overlap+chunkSize)
+	carried := 0
+	for !s.done() {
+		n, err := r.Read(buffer[carried : carried+int(min(chunkSize, s.remaining))])
+		if n > 0 {
+			s.remaining -= int64(n)
+			window := buffer[:carried+n]
+			for i, marker := range s.markers {
+				if !s.seen[i] && bytes.Contains(window, []byte(marker)) {
+					s.seen[i] = true
+					if s.found++; s.found >= s.want {
+						return nil
+					}
+				}
+			}
+			carried = copy(buffer, window[max(0, len(window)-overlap):])
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // HasRustSymbols checks if binary has Rust symbols
@@ -160,6 +495,35 @@ func (ea *ELFAnalyzer) GetDynamicLibraries(executablePath string) ([]string, err
 	return libraries, nil
 }
 
+// elfArchitectures maps ELF machine types to GOARCH-style architecture names
+var elfArchitectures = map[elf.Machine]string{
+	elf.EM_X86_64:  "amd64",
+	elf.EM_AARCH64: "arm64",
+	elf.EM_386:     "386",
+	elf.EM_ARM:     "arm",
+	elf.EM_PPC64:   "ppc64le",
+	elf.EM_S390:    "s390x",
+	elf.EM_RISCV:   "riscv64",
+}
+
+// GetArchitecture returns the binary's CPU architecture (amd64, arm64, ...) from its ELF header
+func (ea *ELFAnalyzer) GetArchitecture(executablePath string) (string, error) {
+	if executablePath == "" {
+		return "", nil
+	}
+
+	elfFile, err := elf.Open(executablePath)
+	if err != nil {
+		return "", nil
+	}
+	defer elfFile.Close()
+
+	if arch, ok := elfArchitectures[elfFile.Machine]; ok {
+		return arch, nil
+	}
+	return strings.ToLower(strings.TrimPrefix(elfFile.Machine.String(), "EM_")), nil
+}
+
 // GetLibcType determines if the binary uses musl or glibc
 func (ea *ELFAnalyzer) GetLibcType(executablePath string) (string, error) {
 	if executablePath == "" {
@@ -0,0 +1,75 @@
+package process
+
+import (
+	"debug/elf"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kloudmate/polylang-detector/internal/elftest"
+)
+
+func TestFileContainsAnyAcrossChunkBoundary(t *testing.T) {
+	// Place the marker so it straddles the 1 MiB read boundary
+	data := make([]byte, (1<<20)+64)
+	copy(data[(1<<20)-5:], "SubstrateVM")
+	path := filepath.Join(t.TempDir(), "boundary")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+
+	found, err := FileContainsAny(path, []string{"SubstrateVM"}, 8<<20)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !found {
+		t.Error("expected marker spanning a chunk boundary to be found")
+	}
+}
+
+func TestGetArchitectureAndLibc(t *testing.T) {
+	tests := []struct {
+		name        string
+		machine     elf.Machine
+		interpreter string
+		wantArch    string
+		wantLibc    string
+	}{
+		{"glibc-amd64", elf.EM_X86_64, "/lib64/ld-linux-x86-64.so.2", "amd64", "glibc"},
+		{"musl-arm64", elf.EM_AARCH64, "/lib/ld-musl-aarch64.so.1", "arm64", "musl"},
+	}
+
+	analyzer := NewELFAnalyzer()
+	for _, tt := range tests {
+		exe := elftest.Write(t, tt.name, elftest.Options{
+			Machine:     tt.machine,
+			Interpreter: tt.interpreter,
+			Symbols:     []string{"main"},
+		})
+
+		ctx := &ProcessContext{PID: -1, Executable: exe}
+		if arch, _ := analyzer.GetArchitecture(ExecutableFile(ctx)); arch != tt.wantArch {
+			t.Errorf("%s: expected architecture %s, got %s", tt.name, tt.wantArch, arch)
+		}
+		if libc, _ := analyzer.GetLibcType(ExecutableFile(ctx)); libc != tt.wantLibc {
+			t.Errorf("%s: expected libc %s, got %s", tt.name, tt.wantLibc, libc)
+		}
+	}
+}
+
+func TestScanMarkersLimitsScanToNamedSections(t *testing.T) {
+	exe := elftest.Write(t, "server", elftest.Options{
+		Sections: []elftest.Section{
+			{Name: ".rodata", Type: elf.SHT_PROGBITS, Data: []byte("\x00/axum-0.7.5/src/serve.rs\x00")},
+			{Name: ".comment", Type: elf.SHT_PROGBITS, Data: []byte("\x00/rocket-0.5.0/src/lib.rs\x00")},
+		},
+	})
+
+	found, err := ScanMarkers(exe, []string{".rodata"}, []string{"/axum-", "/rocket-"}, 1<<20)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !found[0] || found[1] {
+		t.Errorf("expected only the .rodata marker to be found, got %v", found)
+	}
+}
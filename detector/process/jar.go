@@ -0,0 +1,81 @@
+package process
+
+import (
+	"archive/zip"
+	"bufio"
+	"path"
+	"strings"
+)
+
+// JarFile holds the parts of an executable jar used for framework detection
+type JarFile struct {
+	// Manifest holds the main attributes of META-INF/MANIFEST.MF
+	Manifest map[string]string
+	// Libs lists the file names of nested jars under BOOT-INF/lib/ (Spring Boot fat jars)
+	Libs []string
+}
+
+// ExecutableJar returns a host-readable path to the jar passed to "-jar" on the process
+// command line, or "" if the process was not started from a jar. The path is resolved
+// through /proc/[pid]/root like script paths.
+func ExecutableJar(ctx *ProcessContext) string {
+	args := strings.Fields(ctx.Cmdline)
+	for i, arg := range args {
+		if arg == "-jar" && i+1 < len(args) {
+			return scriptPath(ctx.PID, args[i+1])
+		}
+	}
+	return ""
+}
+
+// ReadJar reads the manifest and nested library names of a jar file
+func ReadJar(jarPath string) (*JarFile, error) {
+	reader, err := zip.OpenReader(jarPath)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	jar := &JarFile{Manifest: make(map[string]string)}
+	for _, file := range reader.File {
+		switch {
+		case file.Name == "META-INF/MANIFEST.MF":
+			if err := readManifest(file, jar.Manifest); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(file.Name, "BOOT-INF/lib/") && strings.HasSuffix(file.Name, ".jar"):
+			jar.Libs = append(jar.Libs, path.Base(file.Name))
+		}
+	}
+	return jar, nil
+}
+
+// readManifest parses the main section of a jar manifest into attrs, joining
+// continuation lines (which start with a single space) onto the previous value
+func readManifest(file *zip.File, attrs map[string]string) error {
+	rc, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	var lastKey string
+	scanner := bufio.NewScanner(rc)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			break // End of the main section
+		}
+		if strings.HasPrefix(line, " ") && lastKey != "" {
+			attrs[lastKey] += line[1:]
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		lastKey = strings.TrimSpace(key)
+		attrs[lastKey] = strings.TrimSpace(value)
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,62 @@
+package process
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// tcpListenState is the socket state value for LISTEN in /proc/net/tcp
+const tcpListenState = "0A"
+
+// ListeningPorts returns the TCP ports listening in the process's network namespace, read
+// from /proc/[pid]/net/tcp and tcp6. For a container this covers every port its pod listens
+// on. Ports are sorted and de-duplicated; the result is nil when neither table can be read.
+func ListeningPorts(pid int) []int {
+	netDir := filepath.Join(procDir, strconv.Itoa(pid), "net")
+
+	seen := make(map[int]bool)
+	var ports []int
+	for _, table := range []string{"tcp", "tcp6"} {
+		file, err := os.Open(filepath.Join(netDir, table))
+		if err != nil {
+			continue
+		}
+		for _, port := range parseListeningPorts(file) {
+			if !seen[port] {
+				seen[port] = true
+				ports = append(ports, port)
+			}
+		}
+		file.Close()
+	}
+
+	sort.Ints(ports)
+	return ports
+}
+
+// parseListeningPorts extracts local ports of LISTEN sockets from a /proc/net/tcp table,
+// whose rows look like "0: 00000000:1F90 00000000:0000 0A ..."
+func parseListeningPorts(file *os.File) []int {
+	var ports []int
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // Skip header
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || fields[3] != tcpListenState {
+			continue
+		}
+
+		_, hexPort, found := strings.Cut(fields[1], ":")
+		if !found {
+			continue
+		}
+		if port, err := strconv.ParseUint(hexPort, 16, 16); err == nil && port > 0 {
+			ports = append(ports, int(port))
+		}
+	}
+	return ports
+}
@@ -0,0 +1,50 @@
+package process_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kloudmate/polylang-detector/detector/process"
+	"github.com/kloudmate/polylang-detector/internal/proctest"
+)
+
+const sampleTCP = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 31337 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:2382 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 31338 1 0000000000000000 100 0 0 10 0
+   2: 0A00000F:1F90 0A000010:D4C2 01 00000000:00000000 00:00000000 00000000  1000        0 31339 1 0000000000000000 20 4 30 10 -1
+`
+
+const sampleTCP6 = `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000000000000:1F90 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 41337 1 0000000000000000 100 0 0 10 0
+   1: 00000000000000000000000000000000:0050 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 41338 1 0000000000000000 100 0 0 10 0
+`
+
+func TestListeningPortsReadsTCPTables(t *testing.T) {
+	root := proctest.New(t)
+
+	netDir := filepath.Join(root, "21", "net")
+	if err := os.MkdirAll(netDir, 0o755); err != nil {
+		t.Fatalf("failed to create net dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(netDir, "tcp"), []byte(sampleTCP), 0o644); err != nil {
+		t.Fatalf("failed to write tcp table: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(netDir, "tcp6"), []byte(sampleTCP6), 0o644); err != nil {
+		t.Fatalf("failed to write tcp6 table: %v", err)
+	}
+
+	// 8080 is listed in both tables and as an established connection; only LISTEN rows count
+	if got, want := process.ListeningPorts(21), []int{80, 8080, 9090}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected ports %v, got %v", want, got)
+	}
+}
+
+func TestListeningPortsMissingTables(t *testing.T) {
+	proctest.New(t)
+
+	if ports := process.ListeningPorts(21); ports != nil {
+		t.Errorf("expected nil ports when tables are unreadable, got %v", ports)
+	}
+}
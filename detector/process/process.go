@@ -2,9 +2,12 @@ package process
 
 import (
 	"bufio"
+	"cmp"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -18,6 +21,11 @@ type ProcessContext struct {
 	Environ     map[string]string
 	CgroupPath  string
 	ContainerID string
+
+	// maps caches /proc/[pid]/maps so every inspector's DeepScan shares a single read
+	maps    *ProcessFile
+	mapsErr error
+	mapsSet bool
 }
 
 // ProcessFile represents a file in /proc/[pid]/
@@ -38,6 +46,18 @@ func GetProcDir() string {
 	return procDir
 }
 
+var cgroupDir = "/sys/fs/cgroup" // Can be overridden for testing or host cgroup access
+
+// SetCgroupDir sets the cgroup filesystem root (e.g., /host/sys/fs/cgroup for DaemonSet mode)
+func SetCgroupDir(dir string) {
+	cgroupDir = dir
+}
+
+// GetCgroupDir returns the current cgroup filesystem root
+func GetCgroupDir() string {
+	return cgroupDir
+}
+
 // FindAllProcesses scans /proc and returns all process PIDs
 func FindAllProcesses() ([]int, error) {
 	entries, err := os.ReadDir(procDir)
@@ -87,33 +107,12 @@ func GetProcessContext(pid int) (*ProcessContext, error) {
 	ctx.Cmdline = strings.ReplaceAll(string(cmdlineBytes), "\x00", " ")
 
 	// Read environment variables
-	envBytes, err := os.ReadFile(filepath.Join(procPath, "environ"))
-	if err == nil {
-		envPairs := strings.Split(string(envBytes), "\x00")
-		for _, pair := range envPairs {
-			if pair == "" {
-				continue
-			}
-			parts := strings.SplitN(pair, "=", 2)
-			if len(parts) == 2 {
-				ctx.Environ[parts[0]] = parts[1]
-			}
-		}
+	if environ, err := ReadEnviron(pid); err == nil {
+		ctx.Environ = environ
 	}
 
 	// Read parent PID
-	statusFile := filepath.Join(procPath, "status")
-	if data, err := os.ReadFile(statusFile); err == nil {
-		for _, line := range strings.Split(string(data), "\n") {
-			if strings.HasPrefix(line, "PPid:") {
-				fields := strings.Fields(line)
-				if len(fields) >= 2 {
-					ctx.PPID, _ = strconv.Atoi(fields[1])
-					break
-				}
-			}
-		}
-	}
+	ctx.PPID = readPPID(pid)
 
 	// Read cgroup to get container ID
 	cgroupFile := filepath.Join(procPath, "cgroup")
@@ -125,6 +124,180 @@ func GetProcessContext(pid int) (*ProcessContext, error) {
 	return ctx, nil
 }
 
+// shellNames are interpreters commonly used as entrypoint wrappers
+var shellNames = []string{"sh", "bash", "dash", "ash", "zsh", "busybox"}
+
+// maxShellWrapperDepth bounds how many nested shell wrappers are followed
+const maxShellWrapperDepth = 5
+
+// IsShell reports whether the process is a shell, judged by its executable or first cmdline argument
+func IsShell(ctx *ProcessContext) bool {
+	names := []string{filepath.Base(ctx.Executable)}
+	if fields := strings.Fields(ctx.Cmdline); len(fields) > 0 {
+		names = append(names, filepath.Base(fields[0]))
+	}
+
+	for _, name := range names {
+		for _, shell := range shellNames {
+			if name == shell {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ReadEnviron reads the environment variables of a process from /proc/[pid]/environ
+func ReadEnviron(pid int) (map[string]string, error) {
+	envBytes, err := os.ReadFile(filepath.Join(procDir, strconv.Itoa(pid), "environ"))
+	if err != nil {
+		return nil, err
+	}
+
+	environ := make(map[string]string)
+	for _, pair := range strings.Split(string(envBytes), "\x00") {
+		if key, value, found := strings.Cut(pair, "="); found && key != "" {
+			environ[key] = value
+		}
+	}
+	return environ, nil
+}
+
+// MainThreadChildPIDs returns the children forked by the main thread of pid, read from
+// /proc/[pid]/task/[pid]/children. Unlike ChildPIDs it does not walk all of /proc, but it
+// misses children of other threads and needs a kernel built with CONFIG_PROC_CHILDREN.
+func MainThreadChildPIDs(pid int) ([]int, error) {
+	id := strconv.Itoa(pid)
+	data, err := os.ReadFile(filepath.Join(procDir, id, "task", id, "children"))
+	if err != nil {
+		return nil, err
+	}
+
+	var children []int
+	for _, field := range strings.Fields(string(data)) {
+		if child, err := strconv.Atoi(field); err == nil {
+			children = append(children, child)
+		}
+	}
+	return children, nil
+}
+
+// ChildPIDs returns the PIDs whose parent is pid, in ascending order
+func ChildPIDs(pid int) ([]int, error) {
+	pids, err := FindAllProcesses()
+	if err != nil {
+		return nil, err
+	}
+
+	var children []int
+	for _, candidate := range pids {
+		if readPPID(candidate) == pid {
+			children = append(children, candidate)
+		}
+	}
+	sort.Ints(children)
+	return children, nil
+}
+
+// maxOpenFiles bounds how many file descriptors of a process are resolved
+const maxOpenFiles = 1024
+
+// OpenFiles returns the paths of the files a process has open, from the /proc/[pid]/fd
+// symlinks. Sockets, pipes, anonymous inodes and devices are left out.
+func OpenFiles(pid int) ([]string, error) {
+	fdDir := filepath.Join(procDir, strconv.Itoa(pid), "fd")
+	entries, err := os.ReadDir(fdDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for i, entry := range entries {
+		if i == maxOpenFiles {
+			break
+		}
+		target, err := os.Readlink(filepath.Join(fdDir, entry.Name()))
+		if err != nil || !filepath.IsAbs(target) || strings.HasPrefix(target, "/dev/") {
+			continue
+		}
+		files = append(files, target)
+	}
+	return files, nil
+}
+
+// FollowShellWrapper returns the application process started by a shell entrypoint.
+// When ctx is a shell, its children are walked (depth-first, bounded) until a
+// non-shell process is found; otherwise, or when no such child exists, ctx is returned.
+func FollowShellWrapper(ctx *ProcessContext) *ProcessContext {
+	if child := findNonShellChild(ctx, maxShellWrapperDepth); child != nil {
+		return child
+	}
+	return ctx
+}
+
+// findNonShellChild returns the first non-shell descendant of a shell process, or nil
+func findNonShellChild(ctx *ProcessContext, depth int) *ProcessContext {
+	if depth == 0 || !IsShell(ctx) {
+		return nil
+	}
+
+	children, err := ChildPIDs(ctx.PID)
+	if err != nil {
+		return nil
+	}
+
+	for _, pid := range children {
+		child, err := GetProcessContext(pid)
+		if err != nil {
+			continue
+		}
+		if !IsShell(child) {
+			return child
+		}
+		if descendant := findNonShellChild(child, depth-1); descendant != nil {
+			return descendant
+		}
+	}
+	return nil
+}
+
+// readPPID reads the parent PID from /proc/[pid]/status, returning 0 if unavailable
+func readPPID(pid int) int {
+	data, err := os.ReadFile(filepath.Join(procDir, strconv.Itoa(pid), "status"))
+	if err != nil {
+		return 0
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "PPid:") {
+			fields := strings.Fields(line)
+			if len(fields) >= 2 {
+				ppid, _ := strconv.Atoi(fields[1])
+				return ppid
+			}
+		}
+	}
+	return 0
+}
+
+// ExecutableFile returns a path to the process's executable that is readable from this
+// process. /proc/[pid]/exe resolves inside the process's mount namespace, so it works for
+// containerized processes whose Executable path only exists in the container; when it is
+// unavailable ctx.Executable is returned.
+func ExecutableFile(ctx *ProcessContext) string {
+	exeLink := filepath.Join(procDir, strconv.Itoa(ctx.PID), "exe")
+	if _, err := os.Stat(exeLink); err == nil {
+		return exeLink
+	}
+	return ctx.Executable
+}
+
+// ContainerFilePath maps a path as seen by the process (relative paths are resolved against
+// its working directory) to one readable from this process through /proc/[pid]/root
+func ContainerFilePath(ctx *ProcessContext, path string) string {
+	return scriptPath(ctx.PID, path)
+}
+
 // ReadMapsFile reads /proc/[pid]/maps file
 func ReadMapsFile(pid int) (*ProcessFile, error) {
 	mapsPath := filepath.Join(procDir, strconv.Itoa(pid), "maps")
@@ -139,127 +312,105 @@ func ReadMapsFile(pid int) (*ProcessFile, error) {
 	}, nil
 }
 
-// extractContainerID extracts container ID from cgroup path
-func extractContainerID(cgroupContent string) string {
-	// Parse cgroup content to find container ID
-	// Format examples:
-	// 0::/kubepods/besteffort/pod<pod-uid>/container-id
-	// 0::/docker/container-id
-	// 12:pids:/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod<uid>.slice/docker-<container-id>.scope
-
-	lines := strings.Split(cgroupContent, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		// Docker container ID pattern
-		if strings.Contains(line, "docker-") {
-			parts := strings.Split(line, "docker-")
-			if len(parts) > 1 {
-				containerID := strings.TrimSuffix(parts[1], ".scope")
-				if len(containerID) >= 12 {
-					return containerID[:12] // Return first 12 chars
-				}
-			}
-		}
-
-		// Containerd pattern
-		if strings.Contains(line, "cri-containerd-") {
-			parts := strings.Split(line, "cri-containerd-")
-			if len(parts) > 1 {
-				containerID := strings.TrimSuffix(parts[1], ".scope")
-				if len(containerID) >= 12 {
-					return containerID[:12]
-				}
-			}
-		}
-
-		// CRI-O pattern
-		if strings.Contains(line, "crio-") {
-			parts := strings.Split(line, "crio-")
-			if len(parts) > 1 {
-				containerID := strings.TrimSuffix(parts[1], ".scope")
-				if len(containerID) >= 12 {
-					return containerID[:12]
-				}
-			}
-		}
+// MapsFile returns /proc/[pid]/maps for the process, reading it on first use and
+// returning the cached result afterwards. A ProcessContext is inspected by a single
+// goroutine, so the cache is not synchronized.
+func (ctx *ProcessContext) MapsFile() (*ProcessFile, error) {
+	if !ctx.mapsSet {
+		ctx.maps, ctx.mapsErr = ReadMapsFile(ctx.PID)
+		ctx.mapsSet = true
 	}
+	return ctx.maps, ctx.mapsErr
+}
 
-	return ""
+// extractContainerID returns the short (12 character) container ID from cgroup content
+func extractContainerID(cgroupContent string) string {
+	containerID := ParseCgroup(cgroupContent).ContainerID
+	if len(containerID) > 12 {
+		return containerID[:12]
+	}
+	return containerID
 }
 
-// GetContainerPIDs returns all PIDs belonging to a specific container
+// GetContainerPIDs returns all PIDs belonging to a specific container. Each process's
+// /proc/[pid]/cgroup is matched against the container ID first, which works for any cgroup
+// layout; the known paths under the cgroup root are only globbed when that finds nothing.
 func GetContainerPIDs(containerID string) ([]int, error) {
 	if containerID == "" {
 		return nil, fmt.Errorf("container ID is empty")
 	}
 
+	if pids := findPIDsByCgroup(containerID); len(pids) > 0 {
+		return pids, nil
+	}
+
 	// Support both 12-char short ID and full 64-char ID
 	shortID := containerID
 	if len(containerID) > 12 {
 		shortID = containerID[:12]
 	}
 
-	// Try cgroup paths with both full and short container IDs
+	// Try cgroup paths (relative to the cgroup root) with both full and short container IDs
 	// Order: cgroup v2 unified hierarchy first (modern systems), then v1
 	cgroupPaths := []string{
 		// === Cgroup v2 (unified hierarchy) - Modern Kubernetes/containerd ===
 		// GKE/Containerd with QoS classes (Burstable, BestEffort, Guaranteed)
-		// Pattern: /sys/fs/cgroup/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod<UUID>.slice/cri-containerd-<ID>.scope/
-		fmt.Sprintf("/sys/fs/cgroup/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod*.slice/cri-containerd-%s.scope/cgroup.procs", containerID),
-		fmt.Sprintf("/sys/fs/cgroup/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod*.slice/cri-containerd-%s.scope/cgroup.procs", containerID),
-		fmt.Sprintf("/sys/fs/cgroup/kubepods.slice/kubepods-pod*.slice/cri-containerd-%s.scope/cgroup.procs", containerID), // Guaranteed QoS
+		// Pattern: kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod<UUID>.slice/cri-containerd-<ID>.scope/
+		fmt.Sprintf("kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod*.slice/cri-containerd-%s.scope/cgroup.procs", containerID),
+		fmt.Sprintf("kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod*.slice/cri-containerd-%s.scope/cgroup.procs", containerID),
+		fmt.Sprintf("kubepods.slice/kubepods-pod*.slice/cri-containerd-%s.scope/cgroup.procs", containerID), // Guaranteed QoS
 
 		// Same patterns with short container ID
-		fmt.Sprintf("/sys/fs/cgroup/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod*.slice/cri-containerd-%s.scope/cgroup.procs", shortID),
-		fmt.Sprintf("/sys/fs/cgroup/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod*.slice/cri-containerd-%s.scope/cgroup.procs", shortID),
-		fmt.Sprintf("/sys/fs/cgroup/kubepods.slice/kubepods-pod*.slice/cri-containerd-%s.scope/cgroup.procs", shortID),
+		fmt.Sprintf("kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod*.slice/cri-containerd-%s.scope/cgroup.procs", shortID),
+		fmt.Sprintf("kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod*.slice/cri-containerd-%s.scope/cgroup.procs", shortID),
+		fmt.Sprintf("kubepods.slice/kubepods-pod*.slice/cri-containerd-%s.scope/cgroup.procs", shortID),
 
 		// Generic patterns without QoS specificity (fallback)
-		fmt.Sprintf("/sys/fs/cgroup/kubepods.slice/kubepods-*.slice/kubepods-*-pod*.slice/cri-containerd-%s.scope/cgroup.procs", containerID),
-		fmt.Sprintf("/sys/fs/cgroup/kubepods.slice/kubepods-*.slice/kubepods-*-pod*.slice/cri-containerd-%s.scope/cgroup.procs", shortID),
+		fmt.Sprintf("kubepods.slice/kubepods-*.slice/kubepods-*-pod*.slice/cri-containerd-%s.scope/cgroup.procs", containerID),
+		fmt.Sprintf("kubepods.slice/kubepods-*.slice/kubepods-*-pod*.slice/cri-containerd-%s.scope/cgroup.procs", shortID),
 
 		// Containerd - system slice
-		fmt.Sprintf("/sys/fs/cgroup/system.slice/containerd.service/kubepods-*.slice/kubepods-*-pod*.slice/cri-containerd-%s.scope/cgroup.procs", containerID),
-		fmt.Sprintf("/sys/fs/cgroup/system.slice/containerd.service/kubepods-*.slice/kubepods-*-pod*.slice/cri-containerd-%s.scope/cgroup.procs", shortID),
+		fmt.Sprintf("system.slice/containerd.service/kubepods-*.slice/kubepods-*-pod*.slice/cri-containerd-%s.scope/cgroup.procs", containerID),
+		fmt.Sprintf("system.slice/containerd.service/kubepods-*.slice/kubepods-*-pod*.slice/cri-containerd-%s.scope/cgroup.procs", shortID),
 
 		// Simplified containerd patterns (very broad search)
-		fmt.Sprintf("/sys/fs/cgroup/kubepods.slice/*/*/cri-containerd-%s.scope/cgroup.procs", containerID),
-		fmt.Sprintf("/sys/fs/cgroup/kubepods.slice/*/*/cri-containerd-%s.scope/cgroup.procs", shortID),
+		fmt.Sprintf("kubepods.slice/*/*/cri-containerd-%s.scope/cgroup.procs", containerID),
+		fmt.Sprintf("kubepods.slice/*/*/cri-containerd-%s.scope/cgroup.procs", shortID),
 
 		// Docker on cgroup v2 with QoS
-		fmt.Sprintf("/sys/fs/cgroup/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod*.slice/docker-%s.scope/cgroup.procs", containerID),
-		fmt.Sprintf("/sys/fs/cgroup/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod*.slice/docker-%s.scope/cgroup.procs", containerID),
-		fmt.Sprintf("/sys/fs/cgroup/kubepods.slice/kubepods-pod*.slice/docker-%s.scope/cgroup.procs", containerID),
-		fmt.Sprintf("/sys/fs/cgroup/kubepods.slice/kubepods-*.slice/kubepods-*-pod*.slice/docker-%s.scope/cgroup.procs", containerID),
-		fmt.Sprintf("/sys/fs/cgroup/kubepods.slice/kubepods-*.slice/kubepods-*-pod*.slice/docker-%s.scope/cgroup.procs", shortID),
+		fmt.Sprintf("kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod*.slice/docker-%s.scope/cgroup.procs", containerID),
+		fmt.Sprintf("kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod*.slice/docker-%s.scope/cgroup.procs", containerID),
+		fmt.Sprintf("kubepods.slice/kubepods-pod*.slice/docker-%s.scope/cgroup.procs", containerID),
+		fmt.Sprintf("kubepods.slice/kubepods-*.slice/kubepods-*-pod*.slice/docker-%s.scope/cgroup.procs", containerID),
+		fmt.Sprintf("kubepods.slice/kubepods-*.slice/kubepods-*-pod*.slice/docker-%s.scope/cgroup.procs", shortID),
 
 		// CRI-O on cgroup v2 with QoS
-		fmt.Sprintf("/sys/fs/cgroup/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod*.slice/crio-%s.scope/cgroup.procs", containerID),
-		fmt.Sprintf("/sys/fs/cgroup/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod*.slice/crio-%s.scope/cgroup.procs", containerID),
-		fmt.Sprintf("/sys/fs/cgroup/kubepods.slice/kubepods-pod*.slice/crio-%s.scope/cgroup.procs", containerID),
-		fmt.Sprintf("/sys/fs/cgroup/kubepods.slice/kubepods-*.slice/kubepods-*-pod*.slice/crio-%s.scope/cgroup.procs", containerID),
-		fmt.Sprintf("/sys/fs/cgroup/kubepods.slice/kubepods-*.slice/kubepods-*-pod*.slice/crio-%s.scope/cgroup.procs", shortID),
+		fmt.Sprintf("kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod*.slice/crio-%s.scope/cgroup.procs", containerID),
+		fmt.Sprintf("kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod*.slice/crio-%s.scope/cgroup.procs", containerID),
+		fmt.Sprintf("kubepods.slice/kubepods-pod*.slice/crio-%s.scope/cgroup.procs", containerID),
+		fmt.Sprintf("kubepods.slice/kubepods-*.slice/kubepods-*-pod*.slice/crio-%s.scope/cgroup.procs", containerID),
+		fmt.Sprintf("kubepods.slice/kubepods-*.slice/kubepods-*-pod*.slice/crio-%s.scope/cgroup.procs", shortID),
 
 		// === Cgroup v1 (legacy) ===
 		// Docker
-		fmt.Sprintf("/sys/fs/cgroup/system.slice/docker-%s.scope/cgroup.procs", containerID),
-		fmt.Sprintf("/sys/fs/cgroup/system.slice/docker-%s.scope/cgroup.procs", shortID),
+		fmt.Sprintf("system.slice/docker-%s.scope/cgroup.procs", containerID),
+		fmt.Sprintf("system.slice/docker-%s.scope/cgroup.procs", shortID),
 		// Kubernetes with Docker
-		fmt.Sprintf("/sys/fs/cgroup/kubepods/pod*/docker-%s/cgroup.procs", containerID),
-		fmt.Sprintf("/sys/fs/cgroup/kubepods/pod*/docker-%s/cgroup.procs", shortID),
-		fmt.Sprintf("/sys/fs/cgroup/kubepods.slice/kubepods-pod*.slice/docker-%s.scope/cgroup.procs", containerID),
-		fmt.Sprintf("/sys/fs/cgroup/kubepods.slice/kubepods-pod*.slice/docker-%s.scope/cgroup.procs", shortID),
+		fmt.Sprintf("kubepods/pod*/docker-%s/cgroup.procs", containerID),
+		fmt.Sprintf("kubepods/pod*/docker-%s/cgroup.procs", shortID),
+		fmt.Sprintf("kubepods.slice/kubepods-pod*.slice/docker-%s.scope/cgroup.procs", containerID),
+		fmt.Sprintf("kubepods.slice/kubepods-pod*.slice/docker-%s.scope/cgroup.procs", shortID),
 		// Containerd v1
-		fmt.Sprintf("/sys/fs/cgroup/system.slice/cri-containerd-%s.scope/cgroup.procs", containerID),
-		fmt.Sprintf("/sys/fs/cgroup/system.slice/cri-containerd-%s.scope/cgroup.procs", shortID),
+		fmt.Sprintf("system.slice/cri-containerd-%s.scope/cgroup.procs", containerID),
+		fmt.Sprintf("system.slice/cri-containerd-%s.scope/cgroup.procs", shortID),
 		// CRI-O v1
-		fmt.Sprintf("/sys/fs/cgroup/system.slice/crio-%s.scope/cgroup.procs", containerID),
-		fmt.Sprintf("/sys/fs/cgroup/system.slice/crio-%s.scope/cgroup.procs", shortID),
+		fmt.Sprintf("system.slice/crio-%s.scope/cgroup.procs", containerID),
+		fmt.Sprintf("system.slice/crio-%s.scope/cgroup.procs", shortID),
 	}
 
 	var attemptedPaths []string
 	for _, pattern := range cgroupPaths {
+		pattern = filepath.Join(cgroupDir, pattern)
 		matches, err := filepath.Glob(pattern)
 		if err != nil {
 			continue
@@ -301,6 +452,22 @@ func GetContainerPIDs(containerID string) ([]int, error) {
 		containerID, shortID, len(cgroupPaths), attemptedPaths)
 }
 
+// findPIDsByCgroup returns the PIDs whose cgroup names containerID
+func findPIDsByCgroup(containerID string) []int {
+	allPids, err := FindAllProcesses()
+	if err != nil {
+		return nil
+	}
+
+	var pids []int
+	for _, pid := range allPids {
+		if ReadCgroup(pid).MatchesContainerID(containerID) {
+			pids = append(pids, pid)
+		}
+	}
+	return pids
+}
+
 // IsProcessEqualToAny checks if process executable or cmdline matches any of the given names
 func IsProcessEqualToAny(ctx *ProcessContext, processNames []string) bool {
 	exeName := filepath.Base(ctx.Executable)
@@ -329,3 +496,57 @@ func ContainsBinary(mapsFile *ProcessFile, binaries []string) bool {
 
 	return false
 }
+
+// statStartTimeField is the index of starttime in /proc/[pid]/stat, counted from the
+// state field that follows the parenthesized command name
+const statStartTimeField = 19
+
+// ProcessStartTime returns a process's start time in clock ticks since boot, read from
+// /proc/[pid]/stat, or 0 if it can't be read
+func ProcessStartTime(pid int) uint64 {
+	data, err := os.ReadFile(filepath.Join(procDir, strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return 0
+	}
+
+	// The command name may contain spaces or parentheses, so split after its closing paren
+	stat := string(data)
+	end := strings.LastIndexByte(stat, ')')
+	if end < 0 {
+		return 0
+	}
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) <= statStartTimeField {
+		return 0
+	}
+
+	startTime, _ := strconv.ParseUint(fields[statStartTimeField], 10, 64)
+	return startTime
+}
+
+// OrderByStartTime orders a container's PIDs so the longest-running process, normally
+// the application started as the container's init, comes first. Short-lived helpers
+// such as exec'd health checks start later and sort after it. PIDs whose start time
+// can't be read keep their relative order at the end.
+func OrderByStartTime(pids []int) []int {
+	startTimes := make(map[int]uint64, len(pids))
+	for _, pid := range pids {
+		startTimes[pid] = ProcessStartTime(pid)
+	}
+
+	ordered := slices.Clone(pids)
+	slices.SortStableFunc(ordered, func(a, b int) int {
+		ta, tb := startTimes[a], startTimes[b]
+		switch {
+		case ta == tb:
+			return 0
+		case ta == 0:
+			return 1
+		case tb == 0:
+			return -1
+		default:
+			return cmp.Compare(ta, tb)
+		}
+	})
+	return ordered
+}
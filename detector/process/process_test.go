@@ -0,0 +1,92 @@
+package process_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/kloudmate/polylang-detector/detector/process"
+	"github.com/kloudmate/polylang-detector/internal/proctest"
+)
+
+func TestFollowShellWrapperFindsApplication(t *testing.T) {
+	root := proctest.New(t)
+	proctest.WriteProcess(t, root, proctest.Process{PID: 1, Exe: "/bin/bash", Cmdline: "/bin/bash\x00/entrypoint.sh\x00"})
+	proctest.WriteProcess(t, root, proctest.Process{PID: 7, PPID: 1, Exe: "/usr/bin/java", Cmdline: "java\x00-jar\x00/app/app.jar\x00"})
+
+	shell, err := process.GetProcessContext(1)
+	if err != nil {
+		t.Fatalf("failed to read process: %v", err)
+	}
+
+	app := process.FollowShellWrapper(shell)
+	if app.PID != 7 {
+		t.Fatalf("expected java child PID 7, got %d (%s)", app.PID, app.Executable)
+	}
+	if app.PPID != 1 {
+		t.Errorf("expected PPID 1, got %d", app.PPID)
+	}
+}
+
+func TestFollowShellWrapperNestedShells(t *testing.T) {
+	root := proctest.New(t)
+	proctest.WriteProcess(t, root, proctest.Process{PID: 1, Exe: "/bin/busybox", Cmdline: "sh\x00-c\x00/start.sh\x00"})
+	proctest.WriteProcess(t, root, proctest.Process{PID: 5, PPID: 1, Exe: "/bin/dash", Cmdline: "/bin/sh\x00/start.sh\x00"})
+	proctest.WriteProcess(t, root, proctest.Process{PID: 9, PPID: 5, Exe: "/usr/local/bin/node", Cmdline: "node\x00server.js\x00"})
+
+	shell, err := process.GetProcessContext(1)
+	if err != nil {
+		t.Fatalf("failed to read process: %v", err)
+	}
+
+	if app := process.FollowShellWrapper(shell); app.PID != 9 {
+		t.Errorf("expected node PID 9, got %d", app.PID)
+	}
+}
+
+func TestFollowShellWrapperKeepsNonShell(t *testing.T) {
+	root := proctest.New(t)
+	proctest.WriteProcess(t, root, proctest.Process{PID: 1, Exe: "/usr/bin/python3", Cmdline: "python3\x00app.py\x00"})
+	proctest.WriteProcess(t, root, proctest.Process{PID: 3, PPID: 1, Exe: "/bin/sh", Cmdline: "sh\x00-c\x00true\x00"})
+
+	proc, err := process.GetProcessContext(1)
+	if err != nil {
+		t.Fatalf("failed to read process: %v", err)
+	}
+
+	if got := process.FollowShellWrapper(proc); got != proc {
+		t.Errorf("expected non-shell process to be returned unchanged, got PID %d", got.PID)
+	}
+}
+
+// writeStat writes a /proc/<pid>/stat line with the given command name and start time
+func writeStat(t *testing.T, root string, pid int, comm string, startTime uint64) {
+	t.Helper()
+
+	stat := fmt.Sprintf("%d (%s) S 1 %d %d 0 -1 4194560 100 0 0 0 5 3 0 0 20 0 12 0 %d 123456 789 18446744073709551615\n",
+		pid, comm, pid, pid, startTime)
+	if err := os.WriteFile(filepath.Join(root, strconv.Itoa(pid), "stat"), []byte(stat), 0o644); err != nil {
+		t.Fatalf("failed to write stat: %v", err)
+	}
+}
+
+func TestOrderByStartTimePrefersLongestRunning(t *testing.T) {
+	root := proctest.New(t)
+	proctest.WriteProcess(t, root, proctest.Process{PID: 31, Exe: "/usr/bin/curl", Cmdline: "curl\x00-sf\x00http://localhost:8080/health\x00"})
+	writeStat(t, root, 31, "curl", 98000)
+	proctest.WriteProcess(t, root, proctest.Process{PID: 17, Exe: "/usr/bin/java", Cmdline: "java\x00-jar\x00/app/app.jar\x00"})
+	writeStat(t, root, 17, "java (main)", 1200)
+	proctest.WriteProcess(t, root, proctest.Process{PID: 9, Exe: "/bin/sleep", Cmdline: "sleep\x00inf\x00"})
+
+	// curl is enumerated first but started long after the java server; sleep has no stat
+	got := process.OrderByStartTime([]int{31, 9, 17})
+	if want := []int{17, 31, 9}; !slices.Equal(got, want) {
+		t.Errorf("expected order %v, got %v", want, got)
+	}
+	if process.ProcessStartTime(17) != 1200 {
+		t.Errorf("expected start time 1200 despite spaces in the command name, got %d", process.ProcessStartTime(17))
+	}
+}
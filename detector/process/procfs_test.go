@@ -0,0 +1,163 @@
+package process_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/kloudmate/polylang-detector/detector/process"
+	"github.com/kloudmate/polylang-detector/internal/proctest"
+)
+
+func TestGetProcessContextReadsFakeProc(t *testing.T) {
+	root := proctest.New(t)
+	proctest.WriteProcess(t, root, proctest.Process{
+		PID:     42,
+		PPID:    1,
+		Exe:     "/usr/local/bin/python3.12",
+		Cmdline: "python3\x00-m\x00uvicorn\x00app:app\x00",
+		Environ: []string{"PYTHON_VERSION=3.12.1", "EMPTY=", "NOT_A_PAIR", "URL=http://a/?x=1"},
+		Cgroup:  "0::/kubepods.slice/kubepods-pod8eb9b7bf_0432_40ad_ba5e_34a9fa74501a.slice/cri-containerd-" + testContainerID + ".scope\n",
+		Maps:    "7f0000000000-7f0000001000 r-xp 00000000 08:01 1234 /usr/local/lib/libpython3.12.so.1.0\n",
+	})
+
+	ctx, err := process.GetProcessContext(42)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ctx.Executable != "/usr/local/bin/python3.12" || ctx.PPID != 1 {
+		t.Errorf("expected python3.12 with PPID 1, got %q with PPID %d", ctx.Executable, ctx.PPID)
+	}
+	if ctx.Cmdline != "python3 -m uvicorn app:app " {
+		t.Errorf("unexpected cmdline %q", ctx.Cmdline)
+	}
+	wantEnv := map[string]string{"PYTHON_VERSION": "3.12.1", "EMPTY": "", "URL": "http://a/?x=1"}
+	if len(ctx.Environ) != len(wantEnv) {
+		t.Errorf("expected environ %v, got %v", wantEnv, ctx.Environ)
+	}
+	for key, value := range wantEnv {
+		if got, ok := ctx.Environ[key]; !ok || got != value {
+			t.Errorf("expected %s=%q, got %q", key, value, got)
+		}
+	}
+	if ctx.ContainerID != testContainerID[:12] {
+		t.Errorf("expected the short container ID from cgroup, got %q", ctx.ContainerID)
+	}
+
+	maps, err := ctx.MapsFile()
+	if err != nil {
+		t.Fatalf("failed to read maps: %v", err)
+	}
+	if !process.ContainsBinary(maps, []string{"libpython3"}) {
+		t.Error("expected maps to list libpython3")
+	}
+}
+
+func TestGetProcessContextPartialEntries(t *testing.T) {
+	root := proctest.New(t)
+
+	// A kernel thread or zombie has an empty cmdline and no readable exe, environ or cgroup
+	dir := proctest.WriteProcess(t, root, proctest.Process{PID: 2})
+	if err := os.WriteFile(filepath.Join(dir, "cmdline"), nil, 0o644); err != nil {
+		t.Fatalf("failed to write cmdline: %v", err)
+	}
+	ctx, err := process.GetProcessContext(2)
+	if err != nil {
+		t.Fatalf("expected an empty cmdline to be tolerated, got %v", err)
+	}
+	if ctx.Executable != "" || ctx.Cmdline != "" || len(ctx.Environ) != 0 || ctx.ContainerID != "" {
+		t.Errorf("expected an empty context, got %+v", ctx)
+	}
+	if _, err := ctx.MapsFile(); err == nil {
+		t.Error("expected an error reading missing maps")
+	}
+
+	// A process that exited between listing and reading has no cmdline
+	proctest.WriteProcess(t, root, proctest.Process{PID: 3, Exe: "/usr/bin/java"})
+	if _, err := process.GetProcessContext(3); err == nil {
+		t.Error("expected an error for a missing cmdline")
+	}
+	if _, err := process.GetProcessContext(4); err == nil {
+		t.Error("expected an error for a missing process")
+	}
+}
+
+func TestGetProcessContextPermissionDenied(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("file permissions are not enforced for root")
+	}
+
+	root := proctest.New(t)
+	dir := proctest.WriteProcess(t, root, proctest.Process{
+		PID:     50,
+		Exe:     "/usr/bin/node",
+		Cmdline: "node\x00server.js\x00",
+		Environ: []string{"NODE_ENV=production"},
+	})
+
+	// Another user's environ is unreadable without CAP_SYS_PTRACE; detection goes on without it
+	if err := os.Chmod(filepath.Join(dir, "environ"), 0); err != nil {
+		t.Fatalf("failed to chmod environ: %v", err)
+	}
+	ctx, err := process.GetProcessContext(50)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ctx.Environ) != 0 || ctx.Cmdline != "node server.js " {
+		t.Errorf("expected cmdline without environ, got %+v", ctx)
+	}
+
+	if err := os.Chmod(filepath.Join(dir, "cmdline"), 0); err != nil {
+		t.Fatalf("failed to chmod cmdline: %v", err)
+	}
+	if _, err := process.GetProcessContext(50); err == nil {
+		t.Error("expected an error for an unreadable cmdline")
+	}
+}
+
+func TestFindAllProcessesListsPIDDirectories(t *testing.T) {
+	root := proctest.New(t)
+	proctest.WriteProcess(t, root, proctest.Process{PID: 1, Cmdline: "/sbin/init\x00"})
+	proctest.WriteProcess(t, root, proctest.Process{PID: 42, Cmdline: "java\x00"})
+	for _, dir := range []string{"sys", "net"} {
+		if err := os.Mkdir(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatalf("failed to create %s: %v", dir, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "99"), nil, 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := os.Symlink("42", filepath.Join(root, "self")); err != nil {
+		t.Fatalf("failed to link self: %v", err)
+	}
+
+	pids, err := process.FindAllProcesses()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(pids, []int{1, 42}) {
+		t.Errorf("expected PIDs [1 42], got %v", pids)
+	}
+
+	proctest.Use(t, filepath.Join(root, "missing"))
+	if _, err := process.FindAllProcesses(); err == nil {
+		t.Error("expected an error for a missing proc dir")
+	}
+}
+
+func TestGetContainerPIDsErrors(t *testing.T) {
+	root := proctest.New(t)
+	previous := process.GetCgroupDir()
+	process.SetCgroupDir(t.TempDir())
+	t.Cleanup(func() { process.SetCgroupDir(previous) })
+
+	if _, err := process.GetContainerPIDs(""); err == nil {
+		t.Error("expected an error for an empty container ID")
+	}
+
+	proctest.WriteProcess(t, root, proctest.Process{PID: 7, Cmdline: "nginx\x00", Cgroup: "0::/system.slice/nginx.service\n"})
+	if pids, err := process.GetContainerPIDs(testContainerID); err == nil {
+		t.Errorf("expected an error when no process is in the container, got %v", pids)
+	}
+}
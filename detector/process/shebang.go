@@ -0,0 +1,95 @@
+package process
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// maxShebangArgs bounds how many leading cmdline arguments are checked for a script path
+const maxShebangArgs = 3
+
+// maxShebangLength bounds how much of a script's first line is read
+const maxShebangLength = 256
+
+// ScriptInterpreter returns the interpreter named by the shebang line of the first script
+// referenced on the process command line, or "" if none is found. Scripts are read
+// through /proc/[pid]/root so paths resolve inside the process's container, and
+// relative paths are resolved against the process's working directory.
+func ScriptInterpreter(ctx *ProcessContext) string {
+	args := strings.Fields(ctx.Cmdline)
+	if len(args) > maxShebangArgs {
+		args = args[:maxShebangArgs]
+	}
+
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			continue
+		}
+		if interpreter := ParseShebang(readFirstLine(scriptPath(ctx.PID, arg))); interpreter != "" {
+			return interpreter
+		}
+	}
+	return ""
+}
+
+// ParseShebang returns the interpreter name from a "#!" line, following
+// "/usr/bin/env" to the program it runs (e.g. "#!/usr/bin/env python3" -> "python3").
+func ParseShebang(line string) string {
+	if !strings.HasPrefix(line, "#!") {
+		return ""
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(line, "#!"))
+	if len(fields) == 0 {
+		return ""
+	}
+
+	interpreter := filepath.Base(fields[0])
+	if interpreter != "env" {
+		return interpreter
+	}
+
+	// Skip env flags (e.g. -S) and variable assignments
+	for _, field := range fields[1:] {
+		if strings.HasPrefix(field, "-") || strings.Contains(field, "=") {
+			continue
+		}
+		return filepath.Base(field)
+	}
+	return ""
+}
+
+// scriptPath maps a path as seen by the process to one readable from the host
+func scriptPath(pid int, path string) string {
+	procPath := filepath.Join(procDir, strconv.Itoa(pid))
+	if !filepath.IsAbs(path) {
+		cwd, err := os.Readlink(filepath.Join(procPath, "cwd"))
+		if err != nil {
+			return ""
+		}
+		path = filepath.Join(cwd, path)
+	}
+	return filepath.Join(procPath, "root", path)
+}
+
+// readFirstLine returns the first line of a regular file, or "" if it can't be read
+func readFirstLine(path string) string {
+	if path == "" {
+		return ""
+	}
+	if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+		return ""
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	line, _ := bufio.NewReaderSize(file, maxShebangLength).ReadSlice('\n')
+	return strings.TrimRight(string(line), "\r\n")
+}
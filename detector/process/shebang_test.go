@@ -0,0 +1,46 @@
+package process_test
+
+import (
+	"testing"
+
+	"github.com/kloudmate/polylang-detector/detector/process"
+	"github.com/kloudmate/polylang-detector/internal/proctest"
+)
+
+func TestParseShebang(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"#!/usr/bin/env python3", "python3"},
+		{"#!/usr/bin/python3.11 -u", "python3.11"},
+		{"#!/usr/bin/ruby", "ruby"},
+		{"#!/usr/bin/env -S node --enable-source-maps", "node"},
+		{"#!/usr/bin/env NODE_ENV=production node", "node"},
+		{"#! /bin/bash", "bash"},
+		{"#!/usr/bin/env", ""},
+		{"import os", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := process.ParseShebang(tt.line); got != tt.want {
+			t.Errorf("ParseShebang(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestScriptInterpreterResolvesRelativeScript(t *testing.T) {
+	root := proctest.New(t)
+	proctest.WriteProcess(t, root, proctest.Process{PID: 12, PPID: 1, Exe: "/usr/bin/python3.11", Cwd: "/srv", Cmdline: "/usr/bin/python3\x00./app.py\x00--port\x008080\x00"})
+
+	proctest.WriteContainerFile(t, root, 12, "/srv/app.py", []byte("#!/usr/bin/env python3\nprint('hi')\n"))
+
+	ctx, err := process.GetProcessContext(12)
+	if err != nil {
+		t.Fatalf("failed to read process: %v", err)
+	}
+	if got := process.ScriptInterpreter(ctx); got != "python3" {
+		t.Errorf("expected python3, got %q", got)
+	}
+}
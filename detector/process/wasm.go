@@ -0,0 +1,188 @@
+package process
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// wasmMagic opens every binary WebAssembly module, followed by a 4-byte version
+var wasmMagic = []byte("\x00asm")
+
+// maxWasmScanBytes bounds how far into a module the producers section is searched for
+const maxWasmScanBytes = 64 << 20
+
+// maxWasmProducersBytes bounds the size of a producers section that is decoded
+const maxWasmProducersBytes = 64 << 10
+
+// WasmModule returns a host-readable path to the WebAssembly module a wasm runtime is
+// running, or "" if none is found. The module is the first ".wasm" argument on the command
+// line, else the first open ".wasm" file, else the component source in the Spin manifest
+// (spin.toml) the process was started with.
+func WasmModule(ctx *ProcessContext) string {
+	args := strings.Fields(ctx.Cmdline)
+	for _, arg := range args[min(1, len(args)):] {
+		if strings.HasSuffix(arg, ".wasm") {
+			return scriptPath(ctx.PID, arg)
+		}
+	}
+
+	if files, err := OpenFiles(ctx.PID); err == nil {
+		for _, file := range files {
+			if strings.HasSuffix(file, ".wasm") {
+				return scriptPath(ctx.PID, file)
+			}
+		}
+	}
+
+	return spinComponentSource(ctx, args)
+}
+
+// spinComponentSource returns a host-readable path to the first component module declared
+// in the Spin manifest passed with -f/--from, or spin.toml in the working directory
+func spinComponentSource(ctx *ProcessContext, args []string) string {
+	manifest := "spin.toml"
+	for i, arg := range args {
+		if (arg == "-f" || arg == "--from") && i+1 < len(args) {
+			manifest = args[i+1]
+		}
+	}
+	if !strings.HasSuffix(manifest, ".toml") {
+		return ""
+	}
+
+	manifestPath := scriptPath(ctx.PID, manifest)
+	if manifestPath == "" {
+		return ""
+	}
+	file, err := os.Open(manifestPath)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), "=")
+		if !found || strings.TrimSpace(key) != "source" {
+			continue
+		}
+		source := strings.Trim(strings.TrimSpace(value), `"'`)
+		if !strings.HasSuffix(source, ".wasm") {
+			continue
+		}
+		if !path.IsAbs(source) {
+			// Component sources are relative to the manifest's directory
+			return filepath.Join(filepath.Dir(manifestPath), source)
+		}
+		return scriptPath(ctx.PID, source)
+	}
+	return ""
+}
+
+// ReadWasmProducers reads the "producers" custom section of a WebAssembly module, which
+// toolchains use to record the source language and the tools that built the module. It
+// returns the section's fields (e.g. "language", "processed-by") mapped to the names they
+// list, or nil if the module has no producers section.
+func ReadWasmProducers(modulePath string) (map[string][]string, error) {
+	file, err := os.Open(modulePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(io.LimitReader(file, maxWasmScanBytes))
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(reader, header); err != nil || !bytes.Equal(header[:4], wasmMagic) {
+		return nil, errors.New("not a WebAssembly module: " + modulePath)
+	}
+
+	for {
+		id, err := reader.ReadByte()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		size, err := binary.ReadUvarint(reader)
+		if err != nil {
+			return nil, err
+		}
+
+		if size > maxWasmScanBytes {
+			return nil, errors.New("wasm section overruns the module: size " + strconv.FormatUint(size, 10))
+		}
+		if id != 0 || size > maxWasmProducersBytes {
+			if _, err := reader.Discard(int(size)); err != nil {
+				// The module ends, or the scan bound is reached, before a producers section
+				return nil, nil
+			}
+			continue
+		}
+
+		content := make([]byte, size)
+		if _, err := io.ReadFull(reader, content); err != nil {
+			return nil, err
+		}
+		section := bytes.NewReader(content)
+		if name, err := readWasmName(section); err == nil && name == "producers" {
+			return readWasmProducerFields(section)
+		}
+	}
+}
+
+// readWasmProducerFields decodes the body of a producers section: a vector of fields, each
+// a name and a vector of (name, version) pairs
+func readWasmProducerFields(section *bytes.Reader) (map[string][]string, error) {
+	fieldCount, err := binary.ReadUvarint(section)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string][]string)
+	for range fieldCount {
+		field, err := readWasmName(section)
+		if err != nil {
+			return nil, err
+		}
+		valueCount, err := binary.ReadUvarint(section)
+		if err != nil {
+			return nil, err
+		}
+		for range valueCount {
+			name, err := readWasmName(section)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := readWasmName(section); err != nil { // version
+				return nil, err
+			}
+			fields[field] = append(fields[field], name)
+		}
+	}
+	return fields, nil
+}
+
+// readWasmName reads a length-prefixed UTF-8 name
+func readWasmName(section *bytes.Reader) (string, error) {
+	length, err := binary.ReadUvarint(section)
+	if err != nil {
+		return "", err
+	}
+	if length > uint64(section.Len()) {
+		return "", errors.New("wasm name overruns its section: length " + strconv.FormatUint(length, 10))
+	}
+	name := make([]byte, length)
+	if _, err := io.ReadFull(section, name); err != nil {
+		return "", err
+	}
+	return string(name), nil
+}
@@ -0,0 +1,87 @@
+package detector
+
+import (
+	"container/list"
+	"sync"
+)
+
+// defaultProcessTrackerSize caps the number of PIDs remembered by the eBPF event consumer
+const defaultProcessTrackerSize = 10000
+
+// processTracker remembers the language detected for recently seen PIDs so that repeated
+// exec events for the same process are not re-detected. It is bounded with LRU eviction
+// and safe for concurrent use, since exit handling and pruning may run on other goroutines.
+type processTracker struct {
+	mu      sync.Mutex
+	maxSize int
+	entries map[int]*list.Element
+	order   *list.List // front = most recently used
+}
+
+// trackedProcess is the value stored in the LRU list
+type trackedProcess struct {
+	pid      int
+	language string
+}
+
+// newProcessTracker creates a tracker holding at most maxSize PIDs
+func newProcessTracker(maxSize int) *processTracker {
+	if maxSize <= 0 {
+		maxSize = defaultProcessTrackerSize
+	}
+	return &processTracker{
+		maxSize: maxSize,
+		entries: make(map[int]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Seen reports whether the PID is tracked, refreshing its LRU position
+func (pt *processTracker) Seen(pid int) bool {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	elem, exists := pt.entries[pid]
+	if exists {
+		pt.order.MoveToFront(elem)
+	}
+	return exists
+}
+
+// Add records the language for a PID, evicting the least recently used entry when full
+func (pt *processTracker) Add(pid int, language string) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	if elem, exists := pt.entries[pid]; exists {
+		elem.Value.(*trackedProcess).language = language
+		pt.order.MoveToFront(elem)
+		return
+	}
+
+	pt.entries[pid] = pt.order.PushFront(&trackedProcess{pid: pid, language: language})
+
+	for pt.order.Len() > pt.maxSize {
+		oldest := pt.order.Back()
+		pt.order.Remove(oldest)
+		delete(pt.entries, oldest.Value.(*trackedProcess).pid)
+	}
+}
+
+// Remove forgets a PID, typically because the process exited
+func (pt *processTracker) Remove(pid int) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	if elem, exists := pt.entries[pid]; exists {
+		pt.order.Remove(elem)
+		delete(pt.entries, pid)
+	}
+}
+
+// Len returns the number of tracked PIDs
+func (pt *processTracker) Len() int {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	return len(pt.entries)
+}
@@ -0,0 +1,61 @@
+package detector
+
+import (
+	"testing"
+
+	"github.com/kloudmate/polylang-detector/detector/inspectors"
+	runtimedetector "github.com/odigos-io/runtime-detector"
+	"go.uber.org/zap"
+)
+
+func TestProcessTrackerEvictsLeastRecentlyUsed(t *testing.T) {
+	tracker := newProcessTracker(2)
+	tracker.Add(1, "Java")
+	tracker.Add(2, "Go")
+
+	// Touch PID 1 so PID 2 becomes the eviction candidate
+	tracker.Seen(1)
+	tracker.Add(3, "Python")
+
+	if tracker.Len() != 2 {
+		t.Fatalf("expected 2 tracked PIDs, got %d", tracker.Len())
+	}
+	if !tracker.Seen(1) || !tracker.Seen(3) {
+		t.Error("expected PIDs 1 and 3 to be retained")
+	}
+	if tracker.Seen(2) {
+		t.Error("expected PID 2 to be evicted")
+	}
+}
+
+func TestHandleProcessEventExecAndExit(t *testing.T) {
+	ed := &EBPFDetector{
+		LanguageDetector: inspectors.NewLanguageDetector(),
+		Logger:           zap.NewNop(),
+		processes:        newProcessTracker(defaultProcessTrackerSize),
+	}
+
+	for pid := 100; pid < 105; pid++ {
+		ed.handleProcessEvent(runtimedetector.ProcessEvent{
+			EventType: runtimedetector.ProcessExecEvent,
+			PID:       pid,
+			ExecDetails: &runtimedetector.ProcessExecDetails{
+				ExePath: "/usr/bin/java",
+				CmdLine: "java -jar /app/app.jar",
+			},
+		})
+	}
+	if ed.processes.Len() != 5 {
+		t.Fatalf("expected 5 tracked PIDs after exec events, got %d", ed.processes.Len())
+	}
+
+	for pid := 100; pid < 103; pid++ {
+		ed.handleProcessEvent(runtimedetector.ProcessEvent{
+			EventType: runtimedetector.ProcessExitEvent,
+			PID:       pid,
+		})
+	}
+	if ed.processes.Len() != 2 {
+		t.Errorf("expected 2 tracked PIDs after exit events, got %d", ed.processes.Len())
+	}
+}
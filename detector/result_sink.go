@@ -0,0 +1,64 @@
+package detector
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// ResultSink receives every detection result consumed from the queue, in addition to the
+// RPC updater. Implementations must be safe for concurrent use.
+type ResultSink interface {
+	Name() string
+	Write(info ContainerInfo) error
+	Close() error
+}
+
+// BatchSink receives whole batches as they are sent to the updater, reusing the
+// queue-size and flush-interval batching in the RPC client
+type BatchSink interface {
+	Name() string
+	WriteBatch(ctx context.Context, batch []ContainerInfo) error
+	Close() error
+}
+
+// WriteToSinks forwards a detection result to all configured sinks, logging failures
+func (pd *PolylangDetector) WriteToSinks(info ContainerInfo) {
+	for _, sink := range pd.Sinks {
+		if err := sink.Write(info); err != nil {
+			pd.Logger.Error("Failed to write detection result to sink",
+				zap.String("sink", sink.Name()),
+				zap.String("namespace", info.Namespace),
+				zap.String("container", info.ContainerName),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// writeBatchToSinks forwards a batch to all configured batch sinks, logging failures
+func (pd *PolylangDetector) writeBatchToSinks(ctx context.Context, batch []ContainerInfo) {
+	for _, sink := range pd.BatchSinks {
+		if err := sink.WriteBatch(ctx, batch); err != nil {
+			pd.Logger.Error("Failed to write detection batch to sink",
+				zap.String("sink", sink.Name()),
+				zap.Int("count", len(batch)),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// CloseSinks flushes and closes all configured sinks
+func (pd *PolylangDetector) CloseSinks() {
+	for _, sink := range pd.Sinks {
+		if err := sink.Close(); err != nil {
+			pd.Logger.Error("Failed to close sink", zap.String("sink", sink.Name()), zap.Error(err))
+		}
+	}
+	for _, sink := range pd.BatchSinks {
+		if err := sink.Close(); err != nil {
+			pd.Logger.Error("Failed to close sink", zap.String("sink", sink.Name()), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package detector
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"fmt"
+	"io"
+
+	"go.uber.org/zap"
+)
+
+// CompressedBatchVersion identifies the envelope format so servers can reject unknown versions
+const CompressedBatchVersion = 1
+
+// MaxDecompressedBatchSize caps how many bytes DecompressBatch inflates, so a small payload
+// can't exhaust the receiver's memory
+const MaxDecompressedBatchSize = 32 << 20
+
+// CompressedBatch is a gzip-compressed, gob-encoded []ContainerInfo sent to
+// RPCHandler.PushCompressedDetectionResults when KM_RPC_COMPRESS is enabled
+type CompressedBatch struct {
+	Version  int
+	Encoding string
+	Count    int
+	Payload  []byte
+}
+
+// CompressBatch gob-encodes and gzips a batch into a versioned envelope
+func CompressBatch(batch []ContainerInfo) (CompressedBatch, error) {
+	compressed, _, err := compressBatch(batch)
+	return compressed, err
+}
+
+// compressBatch is CompressBatch, also returning the size of the gob encoding it compressed
+func compressBatch(batch []ContainerInfo) (CompressedBatch, int, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	encoded := &countingWriter{w: zw}
+	if err := gob.NewEncoder(encoded).Encode(batch); err != nil {
+		return CompressedBatch{}, 0, fmt.Errorf("failed to encode batch: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return CompressedBatch{}, 0, fmt.Errorf("failed to compress batch: %w", err)
+	}
+
+	return CompressedBatch{
+		Version:  CompressedBatchVersion,
+		Encoding: "gzip",
+		Count:    len(batch),
+		Payload:  buf.Bytes(),
+	}, encoded.n, nil
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += n
+	return n, err
+}
+
+// DecompressBatch restores the batch from an envelope produced by CompressBatch
+func DecompressBatch(cb CompressedBatch) ([]ContainerInfo, error) {
+	if cb.Version != CompressedBatchVersion || cb.Encoding != "gzip" {
+		return nil, fmt.Errorf("unsupported compressed batch version %d encoding %q", cb.Version, cb.Encoding)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(cb.Payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open compressed batch: %w", err)
+	}
+	defer zr.Close()
+
+	// Read one byte past the cap to tell a batch of exactly the maximum size from a larger one
+	raw, err := io.ReadAll(io.LimitReader(zr, MaxDecompressedBatchSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress batch: %w", err)
+	}
+	if len(raw) > MaxDecompressedBatchSize {
+		return nil, fmt.Errorf("compressed batch exceeds %d bytes once decompressed", MaxDecompressedBatchSize)
+	}
+
+	var batch []ContainerInfo
+	if err := gob.NewDecoder(bytes.NewReader(raw)).Decode(&batch); err != nil {
+		return nil, fmt.Errorf("failed to decode batch: %w", err)
+	}
+	if len(batch) != cb.Count {
+		return nil, fmt.Errorf("compressed batch holds %d results, expected %d", len(batch), cb.Count)
+	}
+	return batch, nil
+}
+
+// pushBatch sends a batch to the updater, compressing it when enabled. Servers that
+// predate compression don't expose the compressed method, in which case the batch is
+// sent uncompressed, as is every later batch until the next connection.
+func (pd *PolylangDetector) pushBatch(batch []ContainerInfo, reply *string) error {
+	if !pd.CompressBatches || pd.compressUnsupported.Load() {
+		return pd.RpcClient.Call("RPCHandler.PushDetectionResults", batch, reply)
+	}
+
+	compressed, uncompressedBytes, err := compressBatch(batch)
+	if err != nil {
+		return err
+	}
+	pd.Logger.Debug("Compressed detection batch",
+		zap.Int("count", len(batch)),
+		zap.Int("uncompressed_bytes", uncompressedBytes),
+		zap.Int("compressed_bytes", len(compressed.Payload)),
+	)
+
+	err = pd.RpcClient.Call("RPCHandler.PushCompressedDetectionResults", compressed, reply)
+	if err != nil && isMissingMethod(err) {
+		pd.Logger.Warn("RPC server does not support compressed batches, sending uncompressed")
+		pd.compressUnsupported.Store(true)
+		return pd.RpcClient.Call("RPCHandler.PushDetectionResults", batch, reply)
+	}
+	return err
+}
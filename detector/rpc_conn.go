@@ -2,36 +2,214 @@ package detector
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
 	"net/rpc"
+	"strconv"
+	"strings"
 	"time"
+
+	"go.uber.org/zap"
 )
 
-// DialWithRetry attempts to connect to the RPC server with a backoff
+// defaultRetryMaxInterval caps the exponential backoff between RPC dial attempts
+const defaultRetryMaxInterval = time.Minute
+
+// DialWithRetry attempts to connect to the RPC server with exponential backoff.
+// ServerAddr may list several comma-separated updater endpoints; each round tries them
+// in order starting from the last healthy one, so a down replica fails over to the next.
+// An endpoint of the form unix:///path/to.sock is dialed over a Unix domain socket.
+// The first round is made immediately; retryInterval is the initial delay between rounds,
+// doubled after each failed round (with jitter) up to RetryMaxInterval. Nothing is dialed in dry-run mode.
 func (c *PolylangDetector) DialWithRetry(ctx context.Context, retryInterval time.Duration) error {
-	time.Sleep(time.Second * 10)
+	if c.DryRun {
+		return nil
+	}
+
+	maxInterval := c.RetryMaxInterval
+	if maxInterval <= 0 {
+		maxInterval = defaultRetryMaxInterval
+	}
+	interval := min(retryInterval, maxInterval)
+
 	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if c.dialEndpoints() {
+			// A (re)connected updater may have lost its state, so the next cache sync resends everything
+			c.resetSent()
+			return nil
+		}
+
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		default:
+		case <-time.After(jitter(interval)):
+		}
+
+		interval = min(interval*2, maxInterval)
+	}
+}
+
+// dialEndpoints tries each endpoint once, starting from the active one, and keeps the first that
+// connects in place of any previous client. Calls are serialized, as both DialWithRetry and
+// pushWithRetry dial.
+func (c *PolylangDetector) dialEndpoints() bool {
+	c.dialMu.Lock()
+	defer c.dialMu.Unlock()
+
+	addrs := c.serverAddrs()
+	c.endpointMu.Lock()
+	start := c.activeEndpoint
+	c.endpointMu.Unlock()
+
+	for i := range addrs {
+		idx := (start + i) % len(addrs)
+		addr := addrs[idx]
+
+		c.DomainLogger.(interface {
+			RPCConnectionInitiated(address string)
+		}).RPCConnectionInitiated(addr)
+
+		client, err := rpc.Dial(EndpointNetwork(addr))
+		if err == nil {
 			c.DomainLogger.(interface {
-				RPCConnectionInitiated(address string)
-			}).RPCConnectionInitiated(c.ServerAddr)
-
-			client, err := rpc.Dial("tcp", c.ServerAddr)
-			if err == nil {
-				c.DomainLogger.(interface {
-					RPCConnectionEstablished(address string)
-				}).RPCConnectionEstablished(c.ServerAddr)
-				c.RpcClient = client
-				return nil
+				RPCConnectionEstablished(address string)
+			}).RPCConnectionEstablished(addr)
+			c.endpointMu.Lock()
+			c.activeEndpoint = idx
+			c.endpointMu.Unlock()
+			if c.RpcClient != nil {
+				c.RpcClient.Close()
 			}
+			c.RpcClient = client
+			c.compressUnsupported.Store(false)
+			c.unsupportedMethods.Clear()
+			return true
+		}
 
-			c.DomainLogger.(interface {
-				RPCConnectionFailed(address string, err error)
-			}).RPCConnectionFailed(c.ServerAddr, err)
+		c.DomainLogger.(interface {
+			RPCConnectionFailed(address string, err error)
+		}).RPCConnectionFailed(addr, err)
+	}
+	return false
+}
+
+// callOptional calls an RPC method that older updaters lack. Once the connected updater
+// reports the method missing, that is logged once and further calls are skipped until the
+// next reconnect.
+func (c *PolylangDetector) callOptional(method string, args any, reply any) error {
+	if _, unsupported := c.unsupportedMethods.Load(method); unsupported {
+		return nil
+	}
+
+	err := c.RpcClient.Call(method, args, reply)
+	if err != nil && isMissingMethod(err) {
+		c.Logger.Warn("RPC server does not support method, skipping it until reconnect", zap.String("method", method))
+		c.unsupportedMethods.Store(method, true)
+		return nil
+	}
+	return err
+}
 
-			time.Sleep(retryInterval)
+// isMissingMethod reports whether err is the updater rejecting a call to a method it doesn't register
+func isMissingMethod(err error) bool {
+	return strings.Contains(err.Error(), "can't find method")
+}
+
+// serverAddrs splits ServerAddr into its comma-separated endpoints
+func (c *PolylangDetector) serverAddrs() []string {
+	var addrs []string
+	for _, addr := range strings.Split(c.ServerAddr, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// EndpointNetwork splits an RPC endpoint into the network and address to dial or listen on:
+// "unix:///run/km.sock" is the Unix socket /run/km.sock, anything else is a TCP host:port
+func EndpointNetwork(endpoint string) (network, address string) {
+	if path, ok := strings.CutPrefix(endpoint, "unix://"); ok {
+		return "unix", path
+	}
+	return "tcp", endpoint
+}
+
+// ValidateServerAddr checks that ServerAddr (KM_CFG_UPDATER_RPC_ADDR) lists at least one
+// endpoint and that each is a TCP host:port or a unix:// socket path, and normalizes it by
+// trimming whitespace, dropping empty entries and a tcp:// scheme. It is a no-op in dry-run
+// mode, where nothing is dialed. Without an updater the detector runs sink-only, so the
+// address may be empty once a sink is configured; validate after the sinks are set up.
+func (c *PolylangDetector) ValidateServerAddr() error {
+	if c.DryRun {
+		return nil
+	}
+
+	addrs := c.serverAddrs()
+	if len(addrs) == 0 {
+		if len(c.Sinks) > 0 || len(c.BatchSinks) > 0 {
+			c.ServerAddr = ""
+			return nil
+		}
+		return errors.New("KM_CFG_UPDATER_RPC_ADDR is not set and no sink is configured: expected host:port or unix:///path/to.sock")
+	}
+	for i, addr := range addrs {
+		addr = strings.TrimPrefix(addr, "tcp://")
+		if err := validateEndpoint(addr); err != nil {
+			return fmt.Errorf("invalid KM_CFG_UPDATER_RPC_ADDR endpoint %q: %w", addrs[i], err)
+		}
+		addrs[i] = addr
+	}
+	c.ServerAddr = strings.Join(addrs, ",")
+	return nil
+}
+
+// validateEndpoint checks a single RPC endpoint as understood by EndpointNetwork
+func validateEndpoint(endpoint string) error {
+	network, address := EndpointNetwork(endpoint)
+	if network == "unix" {
+		if address == "" {
+			return errors.New("missing socket path after unix://")
 		}
+		return nil
+	}
+
+	if strings.Contains(address, "://") {
+		return errors.New("unsupported scheme: expected host:port or unix:///path/to.sock")
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("expected host:port: %w", err)
+	}
+	if host == "" {
+		return errors.New("missing host")
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("port %q is not a number between 1 and 65535", port)
+	}
+	return nil
+}
+
+// markEndpointDead moves past the active endpoint so the next dial starts with the following one
+func (c *PolylangDetector) markEndpointDead() {
+	c.endpointMu.Lock()
+	defer c.endpointMu.Unlock()
+	if n := len(c.serverAddrs()); n > 0 {
+		c.activeEndpoint = (c.activeEndpoint + 1) % n
+	}
+}
+
+// jitter returns a random duration in [d/2, d) so reconnecting detectors don't retry in lockstep
+func jitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
 	}
+	half := d / 2
+	return half + rand.N(half)
 }
@@ -0,0 +1,220 @@
+package detector
+
+import (
+	"context"
+	"net"
+	"net/rpc"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestDialWithRetryConnectsOnceServerComesUp(t *testing.T) {
+	// Reserve a free port, then release it so the first dial attempts fail
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to reserve port: %v", err)
+	}
+	addr := probe.Addr().String()
+	probe.Close()
+
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			return
+		}
+		t.Cleanup(func() { listener.Close() })
+
+		server := rpc.NewServer()
+		server.RegisterName("RPCHandler", &recordingHandler{})
+		server.Accept(listener)
+	}()
+
+	pd := newTestDetector()
+	pd.ServerAddr = addr
+	pd.RetryMaxInterval = 200 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	if err := pd.DialWithRetry(ctx, 50*time.Millisecond); err != nil {
+		t.Fatalf("expected connection, got %v", err)
+	}
+	defer pd.RpcClient.Close()
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected connection well under the old 10s startup delay, took %v", elapsed)
+	}
+}
+
+func TestDialWithRetryRespectsContextCancellation(t *testing.T) {
+	pd := newTestDetector()
+	pd.ServerAddr = "127.0.0.1:1" // nothing listens here
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if err := pd.DialWithRetry(ctx, time.Second); err == nil {
+		t.Fatal("expected an error once the context is cancelled")
+	}
+}
+
+func TestDialWithRetryFailsOverToNextEndpoint(t *testing.T) {
+	// The first endpoint refuses connections; the second accepts
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to reserve port: %v", err)
+	}
+	deadAddr := probe.Addr().String()
+	probe.Close()
+
+	handler := &recordingHandler{}
+	liveAddr := startTestRPCServer(t, handler)
+
+	pd := newTestDetector()
+	pd.ServerAddr = deadAddr + ", " + liveAddr
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := pd.DialWithRetry(ctx, time.Second); err != nil {
+		t.Fatalf("expected failover to the second endpoint, got %v", err)
+	}
+	defer pd.RpcClient.Close()
+
+	if pd.activeEndpoint != 1 {
+		t.Errorf("expected the second endpoint to be marked healthy, got index %d", pd.activeEndpoint)
+	}
+
+	pd.SendBatch([]ContainerInfo{{Namespace: "shop", DeploymentName: "api", ContainerName: "app", Language: "Go"}})
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	if len(handler.batches) != 1 {
+		t.Errorf("expected the batch to reach the healthy endpoint, got %d batches", len(handler.batches))
+	}
+}
+
+func TestSendHeartbeatSkipsMissingMethodUntilReconnect(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	pd := newTestDetector()
+	pd.Logger = zap.New(core)
+	// recordingHandler is an old updater without the Heartbeat method
+	pd.ServerAddr = startTestRPCServer(t, &recordingHandler{})
+	if !pd.dialEndpoints() {
+		t.Fatal("expected to connect to the updater")
+	}
+	defer func() { pd.RpcClient.Close() }()
+
+	for range 3 {
+		pd.SendHeartbeat()
+	}
+	if logs.Len() != 1 || logs.All()[0].ContextMap()["method"] != "RPCHandler.Heartbeat" {
+		t.Fatalf("expected the missing method to be logged once, got %+v", logs.All())
+	}
+
+	if !pd.dialEndpoints() {
+		t.Fatal("expected to reconnect to the updater")
+	}
+	pd.SendHeartbeat()
+	if logs.Len() != 2 {
+		t.Errorf("expected the method to be retried after reconnecting, got %d log entries", logs.Len())
+	}
+}
+
+func TestSendBatchOverUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "updater.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("failed to listen on unix socket: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	handler := &recordingHandler{}
+	server := rpc.NewServer()
+	server.RegisterName("RPCHandler", handler)
+	go server.Accept(listener)
+
+	pd := newTestDetector()
+	pd.ServerAddr = "unix://" + socket
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := pd.DialWithRetry(ctx, time.Second); err != nil {
+		t.Fatalf("expected a unix socket connection, got %v", err)
+	}
+	defer pd.RpcClient.Close()
+
+	pd.SendBatch([]ContainerInfo{{Namespace: "shop", DeploymentName: "api", ContainerName: "app", Language: "Go"}})
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	if len(handler.batches) != 1 {
+		t.Errorf("expected the batch to arrive over the unix socket, got %d batches", len(handler.batches))
+	}
+}
+
+func TestValidateServerAddr(t *testing.T) {
+	tests := []struct {
+		addr    string
+		want    string
+		invalid bool
+	}{
+		{addr: "", invalid: true},
+		{addr: " , ", invalid: true},
+		{addr: "km-config-updater", invalid: true},
+		{addr: "km-config-updater:", invalid: true},
+		{addr: "km-config-updater:http", invalid: true},
+		{addr: "km-config-updater:70000", invalid: true},
+		{addr: ":6790", invalid: true},
+		{addr: "http://km-config-updater:6790", invalid: true},
+		{addr: "unix://", invalid: true},
+		{addr: "km-config-updater:6790,bad", invalid: true},
+		{addr: "km-config-updater:6790", want: "km-config-updater:6790"},
+		{addr: " tcp://10.0.0.5:6790 , [fd00::1]:6790,", want: "10.0.0.5:6790,[fd00::1]:6790"},
+		{addr: "unix:///run/km/updater.sock", want: "unix:///run/km/updater.sock"},
+	}
+
+	for _, tt := range tests {
+		pd := &PolylangDetector{ServerAddr: tt.addr}
+		err := pd.ValidateServerAddr()
+		if tt.invalid {
+			if err == nil || !strings.Contains(err.Error(), "KM_CFG_UPDATER_RPC_ADDR") {
+				t.Errorf("%q: expected an error naming KM_CFG_UPDATER_RPC_ADDR, got %v", tt.addr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.addr, err)
+		} else if pd.ServerAddr != tt.want {
+			t.Errorf("%q: expected it normalized to %q, got %q", tt.addr, tt.want, pd.ServerAddr)
+		}
+	}
+
+	if err := (&PolylangDetector{DryRun: true}).ValidateServerAddr(); err != nil {
+		t.Errorf("expected no address to be required in dry-run mode, got %v", err)
+	}
+}
+
+func TestValidateServerAddrAllowsSinkOnlyMode(t *testing.T) {
+	pd := &PolylangDetector{ServerAddr: " , ", BatchSinks: []BatchSink{&countingBatchSink{}}}
+	if err := pd.ValidateServerAddr(); err != nil {
+		t.Fatalf("expected a sink to stand in for the updater, got %v", err)
+	}
+	if pd.ServerAddr != "" {
+		t.Errorf("expected the blank address to be normalized to empty, got %q", pd.ServerAddr)
+	}
+
+	// An invalid address is still rejected when a sink is configured
+	pd = &PolylangDetector{ServerAddr: "km-config-updater", BatchSinks: []BatchSink{&countingBatchSink{}}}
+	if err := pd.ValidateServerAddr(); err == nil {
+		t.Error("expected the invalid address to be rejected")
+	}
+}
@@ -0,0 +1,119 @@
+package detector
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/rpc"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Defaults for SendBatch retries, used when the corresponding env var is unset or invalid
+const (
+	DefaultSendRetries      = 3
+	DefaultSendRetryBackoff = time.Second
+	DefaultMaxRequeues      = 3
+)
+
+// maxRequeuedBatches bounds how many failed batches are held for a later retry
+const maxRequeuedBatches = 50
+
+// errNotConnected is returned when no updater endpoint accepted a connection
+var errNotConnected = errors.New("no RPC endpoint reachable")
+
+// requeuedBatch is a batch that exhausted its send attempts, kept for a later SendBatch
+type requeuedBatch struct {
+	batch    []ContainerInfo
+	requeues int // times the batch has already been requeued
+}
+
+// pushWithRetry sends a batch, reconnecting and backing off between attempts. It makes up
+// to SendRetries attempts, doubling SendRetryBackoff (with jitter) after each failure.
+// Only transport errors are retried; an error returned by the updater itself is not.
+func (pd *PolylangDetector) pushWithRetry(ctx context.Context, batch []ContainerInfo) (string, error) {
+	attempts := max(pd.SendRetries, 1)
+	backoff := pd.SendRetryBackoff
+	if backoff <= 0 {
+		backoff = DefaultSendRetryBackoff
+	}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-time.After(jitter(backoff)):
+			}
+			backoff *= 2
+		}
+
+		if pd.RpcClient == nil {
+			if !pd.dialEndpoints() {
+				err = errNotConnected
+				continue
+			}
+			// A (re)connected updater may have lost its state, so the next cache sync resends everything
+			pd.resetSent()
+		}
+
+		var reply string
+		if err = pd.pushBatch(batch, &reply); err == nil {
+			return reply, nil
+		}
+		pd.DomainLogger.RPCBatchFailed(len(batch), err)
+		if !isTransportError(err) {
+			// The updater rejected the batch over a healthy connection, resending won't help
+			return "", err
+		}
+
+		// Connection failed, fail over to the next endpoint on the next attempt
+		pd.RpcClient.Close()
+		pd.RpcClient = nil
+		pd.markEndpointDead()
+	}
+	return "", err
+}
+
+// isTransportError reports whether err means the connection to the updater broke, as opposed
+// to the updater answering the call with an error (rpc.ServerError)
+func isTransportError(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, rpc.ErrShutdown) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.As(err, &netErr)
+}
+
+// requeue holds a batch that could not be sent so the next SendBatch retries it. Batches
+// requeued more than MaxRequeues times, or beyond maxRequeuedBatches, are dropped.
+func (pd *PolylangDetector) requeue(item requeuedBatch) {
+	pd.requeueMu.Lock()
+	defer pd.requeueMu.Unlock()
+
+	if item.requeues > pd.MaxRequeues {
+		pd.Logger.Error("Dropping batch after exhausting requeues",
+			zap.Int("count", len(item.batch)),
+			zap.Int("requeues", item.requeues-1),
+		)
+		return
+	}
+	if len(pd.requeued) >= maxRequeuedBatches {
+		pd.Logger.Error("Retry queue full, dropping oldest batch", zap.Int("count", len(pd.requeued[0].batch)))
+		pd.requeued = pd.requeued[1:]
+	}
+	pd.requeued = append(pd.requeued, item)
+}
+
+// takeRequeued removes and returns all requeued batches
+func (pd *PolylangDetector) takeRequeued() []requeuedBatch {
+	pd.requeueMu.Lock()
+	defer pd.requeueMu.Unlock()
+
+	items := pd.requeued
+	pd.requeued = nil
+	return items
+}
@@ -0,0 +1,175 @@
+package detector
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/rpc"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// reserveAddr returns a loopback address nothing is listening on yet
+func reserveAddr(t *testing.T) string {
+	t.Helper()
+
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to reserve port: %v", err)
+	}
+	addr := probe.Addr().String()
+	probe.Close()
+	return addr
+}
+
+// serveAt starts an RPC server for handler on addr
+func serveAt(t *testing.T, addr string, handler *recordingHandler) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatalf("failed to listen on %s: %v", addr, err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	server := rpc.NewServer()
+	server.RegisterName("RPCHandler", handler)
+	go server.Accept(listener)
+}
+
+func TestSendBatchRetriesUntilServerRecovers(t *testing.T) {
+	addr := reserveAddr(t)
+	handler := &recordingHandler{}
+	started := make(chan struct{})
+	go func() {
+		defer close(started)
+		time.Sleep(150 * time.Millisecond)
+		serveAt(t, addr, handler)
+	}()
+	t.Cleanup(func() { <-started })
+
+	pd := newTestDetector()
+	pd.ServerAddr = addr
+	pd.SendRetries = 10
+	pd.SendRetryBackoff = 30 * time.Millisecond
+
+	pd.SendBatch([]ContainerInfo{{Namespace: "shop", DeploymentName: "api", ContainerName: "app", Language: "Go"}})
+	defer pd.RpcClient.Close()
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	if len(handler.batches) != 1 {
+		t.Errorf("expected the batch to land once the server recovered, got %d batches", len(handler.batches))
+	}
+}
+
+func TestSendBatchRequeuesUntilUpdaterReturns(t *testing.T) {
+	addr := reserveAddr(t)
+	pd := newTestDetector()
+	pd.ServerAddr = addr
+	pd.SendRetries = 2
+	pd.SendRetryBackoff = time.Millisecond
+	pd.MaxRequeues = 3
+
+	// The updater is down for every attempt, so the batch is requeued instead of dropped
+	pd.SendBatch([]ContainerInfo{{Namespace: "shop", DeploymentName: "api", ContainerName: "app", Language: "Go"}})
+	if len(pd.requeued) != 1 {
+		t.Fatalf("expected the failed batch to be requeued, got %d", len(pd.requeued))
+	}
+
+	handler := &recordingHandler{}
+	serveAt(t, addr, handler)
+	pd.SendBatch([]ContainerInfo{{Namespace: "shop", DeploymentName: "web", ContainerName: "app", Language: "Java"}})
+	defer pd.RpcClient.Close()
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	if len(handler.batches) != 2 || handler.batches[1][0].DeploymentName != "api" {
+		t.Errorf("expected the new batch followed by the requeued one, got %v", handler.batches)
+	}
+	if len(pd.requeued) != 0 {
+		t.Errorf("expected the retry queue to be drained, got %d", len(pd.requeued))
+	}
+}
+
+func TestPushWithRetryKeepsConnectionOnServerError(t *testing.T) {
+	handler := &recordingHandler{reject: errors.New("invalid batch")}
+	pd := newTestDetector()
+	pd.ServerAddr = startTestRPCServer(t, handler)
+	pd.SendRetries = 3
+	pd.SendRetryBackoff = time.Millisecond
+
+	_, err := pd.pushWithRetry(context.Background(), []ContainerInfo{{Namespace: "shop", DeploymentName: "api", ContainerName: "app", Language: "Go"}})
+	var serverErr rpc.ServerError
+	if !errors.As(err, &serverErr) {
+		t.Fatalf("expected the updater's error to be returned, got %v", err)
+	}
+	if pd.RpcClient == nil {
+		t.Fatal("expected the connection to be kept after a server error")
+	}
+	defer pd.RpcClient.Close()
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	if len(handler.batches) != 1 {
+		t.Errorf("expected a rejected batch not to be resent, got %d attempts", len(handler.batches))
+	}
+}
+
+func TestRequeueDropsBatchAfterMaxRequeues(t *testing.T) {
+	pd := newTestDetector()
+	pd.MaxRequeues = 2
+
+	pd.requeue(requeuedBatch{batch: []ContainerInfo{{ContainerName: "a"}}, requeues: 2})
+	pd.requeue(requeuedBatch{batch: []ContainerInfo{{ContainerName: "b"}}, requeues: 3})
+
+	if len(pd.requeued) != 1 || pd.requeued[0].batch[0].ContainerName != "a" {
+		t.Errorf("expected only the batch within MaxRequeues to be kept, got %+v", pd.requeued)
+	}
+}
+
+// countingBatchSink counts the batches written to it
+type countingBatchSink struct {
+	batches int
+}
+
+func (s *countingBatchSink) Name() string { return "counting" }
+func (s *countingBatchSink) Close() error { return nil }
+
+func (s *countingBatchSink) WriteBatch(context.Context, []ContainerInfo) error {
+	s.batches++
+	return nil
+}
+
+func TestDryRunLogsInsteadOfSending(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	pd := newTestDetector()
+	pd.Logger = zap.New(core)
+	pd.ServerAddr = reserveAddr(t)
+	pd.DryRun = true
+	batchSink := &countingBatchSink{}
+	pd.BatchSinks = []BatchSink{batchSink}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := pd.DialWithRetry(ctx, time.Millisecond); err != nil {
+		t.Fatalf("expected dry run to skip dialing, got %v", err)
+	}
+
+	pd.SendBatch([]ContainerInfo{{Namespace: "shop", DeploymentName: "api", ContainerName: "app", Language: "Go", Evidence: []string{"binary: Go buildinfo"}}})
+
+	if pd.RpcClient != nil {
+		t.Error("expected no RPC connection in dry-run mode")
+	}
+	if batchSink.batches != 0 {
+		t.Errorf("expected no batch pushed to sinks in dry-run mode, got %d", batchSink.batches)
+	}
+	entries := logs.FilterMessage("Dry run: detection result not sent").All()
+	if len(entries) != 1 || entries[0].ContextMap()["language"] != "Go" {
+		t.Errorf("expected the result to be logged, got %v", entries)
+	}
+}
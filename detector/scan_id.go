@@ -0,0 +1,32 @@
+package detector
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// scanIDKey is the context key holding the current scan cycle's ID
+type scanIDKey struct{}
+
+// WithNewScanID starts a scan cycle: it returns a context carrying a fresh scan ID, and the ID
+func WithNewScanID(ctx context.Context) (context.Context, string) {
+	scanID := uuid.NewString()
+	return context.WithValue(ctx, scanIDKey{}, scanID), scanID
+}
+
+// ScanID returns the scan cycle ID carried by ctx, or "" outside a scan cycle
+func ScanID(ctx context.Context) string {
+	scanID, _ := ctx.Value(scanIDKey{}).(string)
+	return scanID
+}
+
+// ScanLogger returns logger with a scan_id field when ctx belongs to a scan cycle, so the
+// interleaved logs of concurrent pod detections can be grouped by cycle
+func ScanLogger(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	if scanID := ScanID(ctx); scanID != "" {
+		return logger.With(zap.String("scan_id", scanID))
+	}
+	return logger
+}
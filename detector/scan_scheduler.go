@@ -0,0 +1,76 @@
+package detector
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// defaultScanWorkers is the default number of pods detected concurrently
+const defaultScanWorkers = 10
+
+// PodScanPool bounds the number of concurrent pod detections so large clusters
+// don't spawn thousands of goroutines hitting the API server and /proc at once
+type PodScanPool struct {
+	sem chan struct{}
+}
+
+// NewPodScanPool creates a pool running at most size detections concurrently
+func NewPodScanPool(size int) *PodScanPool {
+	if size <= 0 {
+		size = defaultScanWorkers
+	}
+	return &PodScanPool{sem: make(chan struct{}, size)}
+}
+
+// Go waits for a free worker slot and runs fn on it in a new goroutine.
+// It returns false without running fn if ctx is cancelled while waiting.
+func (p *PodScanPool) Go(ctx context.Context, fn func()) bool {
+	select {
+	case p.sem <- struct{}{}:
+	case <-ctx.Done():
+		return false
+	}
+
+	go func() {
+		defer func() { <-p.sem }()
+		fn()
+	}()
+	return true
+}
+
+// Wait blocks until every detection started on the pool has finished
+func (p *PodScanPool) Wait() {
+	for range cap(p.sem) {
+		p.sem <- struct{}{}
+	}
+	for range cap(p.sem) {
+		<-p.sem
+	}
+}
+
+// InterleavePodsByNamespace reorders pods round-robin across namespaces so that a
+// namespace with thousands of pods cannot starve the others within a scan cycle.
+// Namespaces are visited in order of first appearance and the relative order of
+// pods within a namespace is preserved.
+func InterleavePodsByNamespace(pods []corev1.Pod) []corev1.Pod {
+	var namespaces []string
+	byNamespace := make(map[string][]corev1.Pod)
+	for _, pod := range pods {
+		if _, exists := byNamespace[pod.Namespace]; !exists {
+			namespaces = append(namespaces, pod.Namespace)
+		}
+		byNamespace[pod.Namespace] = append(byNamespace[pod.Namespace], pod)
+	}
+
+	interleaved := make([]corev1.Pod, 0, len(pods))
+	for round := 0; len(interleaved) < len(pods); round++ {
+		for _, ns := range namespaces {
+			if round < len(byNamespace[ns]) {
+				interleaved = append(interleaved, byNamespace[ns][round])
+			}
+		}
+	}
+
+	return interleaved
+}
@@ -0,0 +1,85 @@
+package detector
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testPod(namespace, name string) corev1.Pod {
+	return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+}
+
+func TestInterleavePodsByNamespace(t *testing.T) {
+	pods := []corev1.Pod{
+		testPod("busy", "b1"),
+		testPod("busy", "b2"),
+		testPod("busy", "b3"),
+		testPod("quiet", "q1"),
+		testPod("other", "o1"),
+		testPod("quiet", "q2"),
+	}
+
+	result := InterleavePodsByNamespace(pods)
+
+	expected := []string{"busy/b1", "quiet/q1", "other/o1", "busy/b2", "quiet/q2", "busy/b3"}
+	if len(result) != len(expected) {
+		t.Fatalf("expected %d pods, got %d", len(expected), len(result))
+	}
+	for i, pod := range result {
+		if got := pod.Namespace + "/" + pod.Name; got != expected[i] {
+			t.Errorf("position %d: expected %s, got %s", i, expected[i], got)
+		}
+	}
+}
+
+func TestPodScanPoolBoundsConcurrentDetections(t *testing.T) {
+	const workers = 3
+	pool := NewPodScanPool(workers)
+
+	var inFlight, maxInFlight int32
+	var wg sync.WaitGroup
+	fakeDetect := func() {
+		defer wg.Done()
+		current := atomic.AddInt32(&inFlight, 1)
+		for {
+			observed := atomic.LoadInt32(&maxInFlight)
+			if current <= observed || atomic.CompareAndSwapInt32(&maxInFlight, observed, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+	}
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		if !pool.Go(context.Background(), fakeDetect) {
+			t.Fatal("expected detection to be scheduled")
+		}
+	}
+	wg.Wait()
+
+	if maxInFlight > workers {
+		t.Errorf("expected at most %d concurrent detections, observed %d", workers, maxInFlight)
+	}
+}
+
+func TestPodScanPoolStopsOnCancelledContext(t *testing.T) {
+	pool := NewPodScanPool(1)
+	release := make(chan struct{})
+	pool.Go(context.Background(), func() { <-release })
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if pool.Go(ctx, func() { t.Error("detection should not run") }) {
+		t.Error("expected Go to report cancellation while the pool is full")
+	}
+}
@@ -0,0 +1,64 @@
+package detector
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// syncKey identifies a workload container across detections
+func syncKey(info ContainerInfo) string {
+	return strings.Join([]string{info.Namespace, info.DeploymentName, info.ContainerName}, "/")
+}
+
+// syncHash hashes the detection content of info. DetectedAt is excluded because it is
+// refreshed on every cache hit without the detection itself changing, and PodName and
+// EnvVars because they differ between replicas of the same workload.
+func syncHash(info ContainerInfo) string {
+	info.DetectedAt = time.Time{}
+	info.PodName = ""
+	info.EnvVars = nil
+	data, err := json.Marshal(info)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+// UnsentChanges returns the containers whose detection differs from what was last
+// successfully sent to the updater, including ones never sent
+func (pd *PolylangDetector) UnsentChanges(containers []ContainerInfo) []ContainerInfo {
+	pd.sentMu.Lock()
+	defer pd.sentMu.Unlock()
+
+	var changed []ContainerInfo
+	for _, info := range containers {
+		if hash, sent := pd.sentHashes[syncKey(info)]; !sent || hash != syncHash(info) {
+			changed = append(changed, info)
+		}
+	}
+	return changed
+}
+
+// markSent records the batch as delivered so unchanged entries are skipped by later syncs
+func (pd *PolylangDetector) markSent(batch []ContainerInfo) {
+	pd.sentMu.Lock()
+	defer pd.sentMu.Unlock()
+
+	if pd.sentHashes == nil {
+		pd.sentHashes = make(map[string]string)
+	}
+	for _, info := range batch {
+		pd.sentHashes[syncKey(info)] = syncHash(info)
+	}
+}
+
+// resetSent forgets all delivered hashes so the next sync resends everything,
+// e.g. after reconnecting to an updater that may have lost its state
+func (pd *PolylangDetector) resetSent() {
+	pd.sentMu.Lock()
+	defer pd.sentMu.Unlock()
+	pd.sentHashes = nil
+}
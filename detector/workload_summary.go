@@ -0,0 +1,157 @@
+package detector
+
+import (
+	"maps"
+	"slices"
+	"sort"
+
+	"go.uber.org/zap"
+)
+
+// WorkloadSummary is the one-answer-per-workload view of its containers' detections
+type WorkloadSummary struct {
+	Namespace    string `json:"namespace"`
+	WorkloadName string `json:"workload_name"`
+	WorkloadKind string `json:"workload_kind"`
+	// DominantLanguage is the language of most application containers, ties broken by total
+	// confidence; sidecars only count when the workload has nothing else
+	DominantLanguage string `json:"dominant_language"`
+	// PrimaryLanguage is the language of the workload's main application container, which
+	// sidecars (e.g. a datastore proxy) can outnumber; see PodDetectionResult
+	PrimaryLanguage  string `json:"primary_language"`
+	PrimaryContainer string `json:"primary_container,omitempty"`
+	// Languages lists the distinct detected languages, sorted
+	Languages []string `json:"languages,omitempty"`
+	// Polyglot is set when the workload's containers run more than one language
+	Polyglot   bool     `json:"polyglot"`
+	Frameworks []string `json:"frameworks,omitempty"`
+}
+
+// Summarize aggregates the workload's container detections. Containers whose language is
+// Unknown count towards nothing; options decide which containers are sidecars, which only
+// decide the dominant and primary language when the workload has no application container.
+func (e WorkloadCacheEntry) Summarize(options DetectionOptions) WorkloadSummary {
+	summary := WorkloadSummary{
+		Namespace:    e.Namespace,
+		WorkloadName: e.WorkloadName,
+		WorkloadKind: e.WorkloadKind,
+	}
+
+	languages := make(map[string]bool)
+	counts := make(map[string]int)
+	scores := make(map[string]int)
+	sidecarCounts := make(map[string]int)
+	sidecarScores := make(map[string]int)
+	for _, info := range e.Containers {
+		if info.Language == "" || info.Language == "Unknown" {
+			continue
+		}
+		if info.Framework != "" && !slices.Contains(summary.Frameworks, info.Framework) {
+			summary.Frameworks = append(summary.Frameworks, info.Framework)
+		}
+		languages[info.Language] = true
+		if options.isSidecarContainer(info) {
+			sidecarCounts[info.Language]++
+			sidecarScores[info.Language] += int(info.confidenceScore())
+			continue
+		}
+		counts[info.Language]++
+		scores[info.Language] += int(info.confidenceScore())
+	}
+
+	summary.Languages = slices.Sorted(maps.Keys(languages))
+	sort.Strings(summary.Frameworks)
+	summary.Polyglot = len(summary.Languages) > 1
+
+	if len(counts) == 0 {
+		counts, scores = sidecarCounts, sidecarScores
+	}
+	// Languages are sorted, so equal count and confidence falls back to alphabetical order
+	for _, language := range summary.Languages {
+		if counts[language] == 0 {
+			continue
+		}
+		best := summary.DominantLanguage
+		if best == "" || counts[language] > counts[best] ||
+			(counts[language] == counts[best] && scores[language] > scores[best]) {
+			summary.DominantLanguage = language
+		}
+	}
+	if summary.DominantLanguage == "" {
+		summary.DominantLanguage = "Unknown"
+	}
+
+	// Containers are keyed by name, so order them by name for a deterministic tie-break
+	names := slices.Sorted(maps.Keys(e.Containers))
+	containers := make([]ContainerInfo, 0, len(names))
+	for _, name := range names {
+		containers = append(containers, e.Containers[name])
+	}
+	summary.PrimaryLanguage = "Unknown"
+	if primary, found := options.primaryContainer(containers); found {
+		summary.PrimaryLanguage = primary.Language
+		summary.PrimaryContainer = primary.ContainerName
+	}
+
+	return summary
+}
+
+// GetWorkloadSummary returns the summary of a cached workload
+func (lc *LanguageCache) GetWorkloadSummary(namespace, workloadName string, options DetectionOptions) (WorkloadSummary, bool) {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+
+	entry, exists := lc.workloadCache[namespace+"/"+workloadName]
+	if !exists {
+		return WorkloadSummary{}, false
+	}
+	return entry.Summarize(options), true
+}
+
+// GetAllWorkloadSummaries returns the summary of every cached workload
+func (lc *LanguageCache) GetAllWorkloadSummaries(options DetectionOptions) []WorkloadSummary {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+
+	summaries := make([]WorkloadSummary, 0, len(lc.workloadCache))
+	for _, entry := range lc.workloadCache {
+		summaries = append(summaries, entry.Summarize(options))
+	}
+	return summaries
+}
+
+// GetEnqueueableWorkloadSummaries returns the summary of every cached workload built from
+// only the containers FilterEnqueueable keeps, skipping workloads left with none, so the
+// updater receives summaries that agree with the results it was sent
+func (lc *LanguageCache) GetEnqueueableWorkloadSummaries(options DetectionOptions) []WorkloadSummary {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+
+	var summaries []WorkloadSummary
+	for _, entry := range lc.workloadCache {
+		containers := make(map[string]ContainerInfo)
+		for _, info := range options.FilterEnqueueable(slices.Collect(maps.Values(entry.Containers))) {
+			containers[info.ContainerName] = info
+		}
+		if len(containers) == 0 {
+			continue
+		}
+		enqueueable := *entry
+		enqueueable.Containers = containers
+		summaries = append(summaries, enqueueable.Summarize(options))
+	}
+	return summaries
+}
+
+// SendWorkloadSummaries pushes workload summaries to the updater as part of the cache sync.
+// Like heartbeats, they are skipped while disconnected; the next sync sends them again.
+func (pd *PolylangDetector) SendWorkloadSummaries(summaries []WorkloadSummary) {
+	if pd.ServerAddr == "" || pd.RpcClient == nil || len(summaries) == 0 {
+		return
+	}
+
+	var reply string
+	if err := pd.callOptional("RPCHandler.PushWorkloadSummaries", summaries, &reply); err != nil {
+		pd.Logger.Warn("Failed to send workload summaries", zap.Int("count", len(summaries)), zap.Error(err))
+	}
+}
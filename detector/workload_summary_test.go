@@ -0,0 +1,113 @@
+package detector
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/kloudmate/polylang-detector/detector/inspectors"
+)
+
+// cachedContainer returns a high-confidence container detection for the summary tests
+func cachedContainer(name, language, framework string) ContainerInfo {
+	info := ContainerInfo{Namespace: "shop", ContainerName: name, Language: language, Framework: framework}
+	info.setConfidence(inspectors.ConfidenceHigh)
+	return info
+}
+
+func TestWorkloadSummarySingleLanguage(t *testing.T) {
+	cache := NewLanguageCache(0)
+	cache.UpdateWorkloadContainer("shop", "checkout", "Deployment", cachedContainer("api", "Java", "Spring Boot"))
+	cache.UpdateWorkloadContainer("shop", "checkout", "Deployment", cachedContainer("worker", "Java", ""))
+
+	summary, ok := cache.GetWorkloadSummary("shop", "checkout", DetectionOptions{})
+	if !ok {
+		t.Fatal("expected a summary for the cached workload")
+	}
+	if summary.DominantLanguage != "Java" || summary.Polyglot {
+		t.Errorf("expected a Java-only workload, got %+v", summary)
+	}
+	if !slices.Equal(summary.Frameworks, []string{"Spring Boot"}) {
+		t.Errorf("expected frameworks [Spring Boot], got %v", summary.Frameworks)
+	}
+	if summary.PrimaryLanguage != "Java" || summary.PrimaryContainer != "api" {
+		t.Errorf("expected the Spring Boot container to be primary, got %s (%s)", summary.PrimaryContainer, summary.PrimaryLanguage)
+	}
+}
+
+func TestWorkloadSummaryPrimaryLanguageIgnoresSidecars(t *testing.T) {
+	cache := NewLanguageCache(0)
+	app := cachedContainer("orders", "Python", "FastAPI")
+	app.setConfidence(inspectors.ConfidenceMedium)
+	proxy := cachedContainer("cloudsql", "Go", "")
+	proxy.Image = "gcr.io/cloud-sql-connectors/cloud-sql-proxy:2.8.0"
+	exporter := cachedContainer("metrics", "Go", "")
+	exporter.Image = "oliver006/redis_exporter:v1.58.0"
+	for _, info := range []ContainerInfo{app, proxy, exporter} {
+		cache.UpdateWorkloadContainer("shop", "orders", "Deployment", info)
+	}
+
+	summary, _ := cache.GetWorkloadSummary("shop", "orders", DetectionOptions{})
+	// The sidecars outnumber the app but decide neither the dominant nor the primary language
+	if summary.DominantLanguage != "Python" {
+		t.Errorf("expected the app's Python to dominate despite the sidecars, got %s", summary.DominantLanguage)
+	}
+	if !slices.Equal(summary.Languages, []string{"Go", "Python"}) {
+		t.Errorf("expected languages [Go Python], got %v", summary.Languages)
+	}
+	if summary.PrimaryLanguage != "Python" || summary.PrimaryContainer != "orders" {
+		t.Errorf("expected the app container to be primary, got %s (%s)", summary.PrimaryContainer, summary.PrimaryLanguage)
+	}
+}
+
+func TestWorkloadSummaryPolyglot(t *testing.T) {
+	cache := NewLanguageCache(0)
+	sidecar := cachedContainer("exporter", "Go", "")
+	sidecar.setConfidence(inspectors.ConfidenceMedium)
+	cache.UpdateWorkloadContainer("shop", "checkout", "Deployment", cachedContainer("app", "Java", "Spring Boot"))
+	cache.UpdateWorkloadContainer("shop", "checkout", "Deployment", sidecar)
+	cache.UpdateWorkloadContainer("shop", "checkout", "Deployment", cachedContainer("init", "Unknown", "Flask"))
+
+	summaries := cache.GetAllWorkloadSummaries(DetectionOptions{})
+	if len(summaries) != 1 {
+		t.Fatalf("expected one workload summary, got %d", len(summaries))
+	}
+	summary := summaries[0]
+	// One container each, so the higher-confidence Java detection dominates
+	if summary.DominantLanguage != "Java" || !summary.Polyglot {
+		t.Errorf("expected a polyglot workload dominated by Java, got %+v", summary)
+	}
+	if !slices.Equal(summary.Languages, []string{"Go", "Java"}) {
+		t.Errorf("expected languages [Go Java], got %v", summary.Languages)
+	}
+	if !slices.Equal(summary.Frameworks, []string{"Spring Boot"}) {
+		t.Errorf("expected frameworks [Spring Boot], got %v", summary.Frameworks)
+	}
+}
+
+func TestEnqueueableWorkloadSummariesMatchSentResults(t *testing.T) {
+	cache := NewLanguageCache(0)
+	weak := cachedContainer("worker", "Python", "Celery")
+	weak.setConfidence(inspectors.ConfidenceLow)
+	cache.UpdateWorkloadContainer("shop", "checkout", "Deployment", cachedContainer("app", "Java", "Spring Boot"))
+	cache.UpdateWorkloadContainer("shop", "checkout", "Deployment", weak)
+	cache.UpdateWorkloadContainer("shop", "checkout", "Deployment", cachedContainer("cache", "Redis", ""))
+	cache.UpdateWorkloadContainer("shop", "redis", "StatefulSet", cachedContainer("redis", "Redis", ""))
+
+	options := DetectionOptions{MinConfidence: inspectors.ConfidenceMedium}
+	summaries := cache.GetEnqueueableWorkloadSummaries(options)
+	if len(summaries) != 1 {
+		t.Fatalf("expected only the instrumentable workload, got %+v", summaries)
+	}
+	summary := summaries[0]
+	if summary.WorkloadName != "checkout" || summary.Polyglot || !slices.Equal(summary.Languages, []string{"Java"}) {
+		t.Errorf("expected a Java-only checkout summary, got %+v", summary)
+	}
+	if !slices.Equal(summary.Frameworks, []string{"Spring Boot"}) {
+		t.Errorf("expected frameworks [Spring Boot], got %v", summary.Frameworks)
+	}
+
+	// The cache itself is left untouched
+	if full, _ := cache.GetWorkloadSummary("shop", "checkout", options); len(full.Languages) != 3 {
+		t.Errorf("expected the cached workload to keep all its languages, got %v", full.Languages)
+	}
+}
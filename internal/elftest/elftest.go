@@ -0,0 +1,224 @@
+// Package elftest builds minimal ELF files for use as test fixtures.
+package elftest
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"testing"
+)
+
+// Section is a named section with raw contents
+type Section struct {
+	Name string
+	Type elf.SectionType
+	Data []byte
+	Addr uint64 // when set, the section is mapped at this address with a PT_LOAD program header
+}
+
+// Options describes the fixture to generate
+type Options struct {
+	Machine     elf.Machine // defaults to EM_X86_64
+	Interpreter string      // written to .interp with a PT_INTERP program header
+	Sections    []Section
+	Symbols     []string // written to .symtab as function symbols
+	// StripSectionHeaders omits the section header table, as sstrip does; the section
+	// contents are still written
+	StripSectionHeaders bool
+}
+
+// Write generates a 64-bit little-endian ELF executable in a temp dir and returns its path
+func Write(t testing.TB, name string, opts Options) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, Build(opts), 0o755); err != nil {
+		t.Fatalf("failed to write ELF fixture: %v", err)
+	}
+	return path
+}
+
+// goBuildInfoAddr is where GoBuildInfo sections are mapped; debug/buildinfo requires 16-byte alignment
+const goBuildInfoAddr = 0x400000
+
+// GoBuildInfo returns a .go.buildinfo section in the inline format written by Go 1.18+,
+// readable by debug/buildinfo
+func GoBuildInfo(info *debug.BuildInfo) Section {
+	var data bytes.Buffer
+	header := make([]byte, 32)
+	copy(header, "\xff Go buildinf:")
+	header[14] = 8   // pointer size
+	header[15] = 0x2 // inline strings, little endian
+	data.Write(header)
+
+	// The module info is framed by 16-byte sentinels, which debug/buildinfo strips
+	modinfo := string(make([]byte, 16)) + info.String() + string(make([]byte, 16))
+	for _, str := range []string{info.GoVersion, modinfo} {
+		data.Write(binary.AppendUvarint(nil, uint64(len(str))))
+		data.WriteString(str)
+	}
+
+	return Section{Name: ".go.buildinfo", Type: elf.SHT_PROGBITS, Data: data.Bytes(), Addr: goBuildInfoAddr}
+}
+
+// Build returns the bytes of a 64-bit little-endian ELF executable
+func Build(opts Options) []byte {
+	const (
+		ehdrSize = 64
+		phdrSize = 56
+		shdrSize = 64
+		symSize  = 24
+	)
+
+	machine := opts.Machine
+	if machine == elf.EM_NONE {
+		machine = elf.EM_X86_64
+	}
+
+	sections := append([]Section(nil), opts.Sections...)
+	if opts.Interpreter != "" {
+		sections = append([]Section{{Name: ".interp", Type: elf.SHT_PROGBITS, Data: append([]byte(opts.Interpreter), 0)}}, sections...)
+	}
+
+	// Symbol and string tables
+	var strtab bytes.Buffer
+	strtab.WriteByte(0)
+	var symtab bytes.Buffer
+	symtab.Write(make([]byte, symSize)) // null symbol
+	for _, sym := range opts.Symbols {
+		entry := elf.Sym64{
+			Name:  uint32(strtab.Len()),
+			Info:  elf.ST_INFO(elf.STB_GLOBAL, elf.STT_FUNC),
+			Shndx: uint16(elf.SHN_ABS),
+		}
+		strtab.WriteString(sym)
+		strtab.WriteByte(0)
+		binary.Write(&symtab, binary.LittleEndian, entry)
+	}
+
+	userCount := len(sections)
+	symtabIndex := -1
+	if len(opts.Symbols) > 0 {
+		symtabIndex = userCount + 1
+		sections = append(sections,
+			Section{Name: ".symtab", Type: elf.SHT_SYMTAB, Data: symtab.Bytes()},
+			Section{Name: ".strtab", Type: elf.SHT_STRTAB, Data: strtab.Bytes()},
+		)
+	}
+
+	// Section header string table
+	var shstrtab bytes.Buffer
+	shstrtab.WriteByte(0)
+	nameOffsets := make([]uint32, len(sections)+1)
+	for i, sec := range sections {
+		nameOffsets[i] = uint32(shstrtab.Len())
+		shstrtab.WriteString(sec.Name)
+		shstrtab.WriteByte(0)
+	}
+	nameOffsets[len(sections)] = uint32(shstrtab.Len())
+	shstrtab.WriteString(".shstrtab")
+	shstrtab.WriteByte(0)
+	sections = append(sections, Section{Name: ".shstrtab", Type: elf.SHT_STRTAB, Data: shstrtab.Bytes()})
+
+	phnum := 0
+	if opts.Interpreter != "" {
+		phnum = 1
+	}
+	for _, sec := range sections {
+		if sec.Addr != 0 {
+			phnum++
+		}
+	}
+
+	// Lay out section data after the headers
+	offset := uint64(ehdrSize + phnum*phdrSize)
+	dataOffsets := make([]uint64, len(sections))
+	var body bytes.Buffer
+	for i, sec := range sections {
+		dataOffsets[i] = offset + uint64(body.Len())
+		body.Write(sec.Data)
+	}
+	shoff := offset + uint64(body.Len())
+
+	var out bytes.Buffer
+	header := elf.Header64{
+		Type:      uint16(elf.ET_EXEC),
+		Machine:   uint16(machine),
+		Version:   uint32(elf.EV_CURRENT),
+		Shoff:     shoff,
+		Ehsize:    ehdrSize,
+		Phentsize: phdrSize,
+		Phnum:     uint16(phnum),
+		Shentsize: shdrSize,
+		Shnum:     uint16(len(sections) + 1),
+		Shstrndx:  uint16(len(sections)),
+	}
+	if opts.StripSectionHeaders {
+		header.Shoff, header.Shnum, header.Shstrndx = 0, 0, 0
+	}
+	copy(header.Ident[:], elf.ELFMAG)
+	header.Ident[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	header.Ident[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	header.Ident[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+	if phnum > 0 {
+		header.Phoff = ehdrSize
+	}
+	binary.Write(&out, binary.LittleEndian, header)
+
+	if opts.Interpreter != "" {
+		interp := sections[0]
+		binary.Write(&out, binary.LittleEndian, elf.Prog64{
+			Type:   uint32(elf.PT_INTERP),
+			Flags:  uint32(elf.PF_R),
+			Off:    dataOffsets[0],
+			Filesz: uint64(len(interp.Data)),
+			Memsz:  uint64(len(interp.Data)),
+			Align:  1,
+		})
+	}
+	for i, sec := range sections {
+		if sec.Addr == 0 {
+			continue
+		}
+		binary.Write(&out, binary.LittleEndian, elf.Prog64{
+			Type:   uint32(elf.PT_LOAD),
+			Flags:  uint32(elf.PF_R),
+			Off:    dataOffsets[i],
+			Vaddr:  sec.Addr,
+			Paddr:  sec.Addr,
+			Filesz: uint64(len(sec.Data)),
+			Memsz:  uint64(len(sec.Data)),
+			Align:  1,
+		})
+	}
+
+	out.Write(body.Bytes())
+	if opts.StripSectionHeaders {
+		return out.Bytes()
+	}
+
+	// Section headers, starting with the mandatory null entry
+	binary.Write(&out, binary.LittleEndian, elf.Section64{})
+	for i, sec := range sections {
+		shdr := elf.Section64{
+			Name:      nameOffsets[i],
+			Type:      uint32(sec.Type),
+			Addr:      sec.Addr,
+			Off:       dataOffsets[i],
+			Size:      uint64(len(sec.Data)),
+			Addralign: 1,
+		}
+		if sec.Type == elf.SHT_SYMTAB {
+			shdr.Link = uint32(symtabIndex + 1) // .strtab follows .symtab
+			shdr.Info = 1
+			shdr.Entsize = symSize
+			shdr.Addralign = 8
+		}
+		binary.Write(&out, binary.LittleEndian, shdr)
+	}
+
+	return out.Bytes()
+}
@@ -0,0 +1,145 @@
+// Package proctest builds fake /proc trees for tests of code that reads process state.
+package proctest
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/kloudmate/polylang-detector/detector/process"
+)
+
+// Process describes a /proc/[pid] entry; empty fields leave the file out
+type Process struct {
+	PID  int
+	PPID int
+	Exe  string // target of the exe link
+	Cwd  string // target of the cwd link, against which relative paths resolve
+	// Cmdline is the raw file contents, with NUL-separated arguments (e.g. "java\x00-jar\x00app.jar\x00")
+	Cmdline string
+	Environ []string
+	Cgroup  string
+	Maps    string
+}
+
+// New creates an empty fake /proc tree and points the process package at it for the rest
+// of the test, returning its root
+func New(t testing.TB) string {
+	t.Helper()
+
+	root := t.TempDir()
+	Use(t, root)
+	return root
+}
+
+// Use points the process package at root for the rest of the test
+func Use(t testing.TB, root string) {
+	t.Helper()
+
+	previous := process.GetProcDir()
+	process.SetProcDir(root)
+	t.Cleanup(func() { process.SetProcDir(previous) })
+}
+
+// WriteProcess creates a fake /proc/[pid] entry under root and returns its directory. The
+// status file always carries the PID and parent PID.
+func WriteProcess(t testing.TB, root string, proc Process) string {
+	t.Helper()
+
+	dir := filepath.Join(root, strconv.Itoa(proc.PID))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("failed to create fake proc dir: %v", err)
+	}
+	status := "Pid:\t" + strconv.Itoa(proc.PID) + "\nPPid:\t" + strconv.Itoa(proc.PPID) + "\n"
+	if proc.Exe != "" {
+		if err := os.Symlink(proc.Exe, filepath.Join(dir, "exe")); err != nil {
+			t.Fatalf("failed to link exe: %v", err)
+		}
+		status = "Name:\t" + filepath.Base(proc.Exe) + "\n" + status
+	}
+	if proc.Cwd != "" {
+		if err := os.Symlink(proc.Cwd, filepath.Join(dir, "cwd")); err != nil {
+			t.Fatalf("failed to link cwd: %v", err)
+		}
+	}
+
+	files := map[string]string{
+		"status":  status,
+		"cmdline": proc.Cmdline,
+		"cgroup":  proc.Cgroup,
+		"maps":    proc.Maps,
+	}
+	if proc.Environ != nil {
+		files["environ"] = strings.Join(proc.Environ, "\x00") + "\x00"
+	}
+	for name, content := range files {
+		if content == "" {
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+// WriteMaps writes a /proc/[pid]/maps file listing the given libraries, replacing any
+// previous one
+func WriteMaps(t testing.TB, root string, pid int, libs ...string) {
+	t.Helper()
+
+	var maps strings.Builder
+	for _, lib := range libs {
+		maps.WriteString("7f0000000000-7f0000001000 r-xp 00000000 08:01 1234 " + lib + "\n")
+	}
+	writeFile(t, filepath.Join(root, strconv.Itoa(pid), "maps"), []byte(maps.String()))
+}
+
+// WriteFds creates /proc/[pid]/fd symlinks pointing at targets, numbered from 0
+func WriteFds(t testing.TB, root string, pid int, targets ...string) {
+	t.Helper()
+
+	dir := filepath.Join(root, strconv.Itoa(pid), "fd")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("failed to create fd dir: %v", err)
+	}
+	for fd, target := range targets {
+		if err := os.Symlink(target, filepath.Join(dir, strconv.Itoa(fd))); err != nil {
+			t.Fatalf("failed to link fd %d: %v", fd, err)
+		}
+	}
+}
+
+// WriteChildren writes /proc/[pid]/task/[pid]/children, the children forked by the main thread
+func WriteChildren(t testing.TB, root string, pid int, children ...int) {
+	t.Helper()
+
+	var content strings.Builder
+	for _, child := range children {
+		content.WriteString(strconv.Itoa(child) + " ")
+	}
+	id := strconv.Itoa(pid)
+	writeFile(t, filepath.Join(root, id, "task", id, "children"), []byte(content.String()))
+}
+
+// WriteContainerFile writes a file into the root filesystem of a fake process, as seen
+// through /proc/[pid]/root
+func WriteContainerFile(t testing.TB, root string, pid int, path string, content []byte) {
+	t.Helper()
+
+	writeFile(t, filepath.Join(root, strconv.Itoa(pid), "root", path), content)
+}
+
+// writeFile writes content to path, creating its parent directories
+func writeFile(t testing.TB, path string, content []byte) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("failed to create %s: %v", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
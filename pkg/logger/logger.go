@@ -1,6 +1,9 @@
 package logger
 
 import (
+	"fmt"
+	"os"
+
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -10,14 +13,13 @@ type DomainLogger struct {
 	*zap.Logger
 }
 
-// NewProductionLogger creates a production-ready logger with enterprise formatting
+// NewProductionLogger creates a production-ready logger with enterprise formatting,
+// configured by NewConfigFromEnv.
 func NewProductionLogger() (*DomainLogger, error) {
-	config := zap.NewProductionConfig()
-	config.EncoderConfig.TimeKey = "timestamp"
-	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-	config.EncoderConfig.MessageKey = "message"
-	config.EncoderConfig.LevelKey = "severity"
-	config.EncoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+	config, err := NewConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
 
 	logger, err := config.Build(
 		zap.AddCallerSkip(1),
@@ -30,6 +32,35 @@ func NewProductionLogger() (*DomainLogger, error) {
 	return &DomainLogger{Logger: logger}, nil
 }
 
+// NewConfigFromEnv returns the production logger config with enterprise formatting.
+// KM_LOG_LEVEL (debug/info/warn/error, default info) sets the level and KM_LOG_FORMAT
+// (json/console, default json) selects the encoding.
+func NewConfigFromEnv() (zap.Config, error) {
+	config := zap.NewProductionConfig()
+	level, err := zapcore.ParseLevel(os.Getenv("KM_LOG_LEVEL"))
+	if err != nil {
+		return config, fmt.Errorf("invalid KM_LOG_LEVEL: %w", err)
+	}
+	config.Level = zap.NewAtomicLevelAt(level)
+	if format := os.Getenv("KM_LOG_FORMAT"); format != "" {
+		if format != "json" && format != "console" {
+			return config, fmt.Errorf("invalid KM_LOG_FORMAT %q: must be json or console", format)
+		}
+		config.Encoding = format
+	}
+	config.EncoderConfig.TimeKey = "timestamp"
+	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	config.EncoderConfig.MessageKey = "message"
+	config.EncoderConfig.LevelKey = "severity"
+	config.EncoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+	return config, nil
+}
+
+// WithScanID returns a logger whose events all carry scanID, grouping the events of one scan cycle
+func (l *DomainLogger) WithScanID(scanID string) *DomainLogger {
+	return &DomainLogger{Logger: l.With(zap.String("scan_id", scanID))}
+}
+
 // Language Detection Domain Events
 func (l *DomainLogger) LanguageDetectionStarted(namespace, podName, containerName string) {
 	l.Info("Language detection initiated",
@@ -94,6 +125,50 @@ func (l *DomainLogger) UnsupportedLanguage(language string) {
 	)
 }
 
+func (l *DomainLogger) InfrastructureDetected(language string) {
+	l.Debug("Infrastructure component detected, not instrumenting",
+		zap.String("event", "detection.infrastructure"),
+		zap.String("language", language),
+	)
+}
+
+func (l *DomainLogger) MuslLibcDetected(namespace, podName, containerName, image, language string) {
+	l.Warn("Workload uses musl libc and may need a musl-compatible instrumentation build",
+		zap.String("event", "detection.musl_libc"),
+		zap.String("namespace", namespace),
+		zap.String("pod", podName),
+		zap.String("container", containerName),
+		zap.String("image", image),
+		zap.String("language", language),
+	)
+}
+
+func (l *DomainLogger) LanguageChanged(namespace, workloadName, containerName, previousLanguage, previousFramework, language, framework string) {
+	l.Info("Workload language changed since last detection",
+		zap.String("event", "detection.language_changed"),
+		zap.String("namespace", namespace),
+		zap.String("workload", workloadName),
+		zap.String("container", containerName),
+		zap.String("previous_language", previousLanguage),
+		zap.String("previous_framework", previousFramework),
+		zap.String("language", language),
+		zap.String("framework", framework),
+	)
+}
+
+func (l *DomainLogger) DetectionUpgraded(namespace, workloadName, containerName, language, previousConfidence, confidence, evidence string) {
+	l.Info("Workload detection confidence upgraded since last detection",
+		zap.String("event", "detection.upgraded"),
+		zap.String("namespace", namespace),
+		zap.String("workload", workloadName),
+		zap.String("container", containerName),
+		zap.String("language", language),
+		zap.String("previous_confidence", previousConfidence),
+		zap.String("confidence", confidence),
+		zap.String("evidence", evidence),
+	)
+}
+
 // Cache Domain Events
 func (l *DomainLogger) CacheHit(image, language string) {
 	l.Debug("Cache hit - using cached detection result",
@@ -173,6 +248,16 @@ func (l *DomainLogger) RPCBatchFailed(count int, err error) {
 	)
 }
 
+// Webhook Sink Domain Events
+func (l *DomainLogger) WebhookDeliveryFailed(url string, attempt int, err error) {
+	l.Warn("Failed to deliver detection results to webhook",
+		zap.String("event", "webhook.delivery.failed"),
+		zap.String("url", url),
+		zap.Int("attempt", attempt),
+		zap.Error(err),
+	)
+}
+
 // eBPF Scanning Domain Events
 func (l *DomainLogger) EbpfScanStarted() {
 	l.Info("eBPF-based pod scanning started",
@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNewProductionLoggerLevelFromEnv(t *testing.T) {
+	tests := []struct {
+		env  string
+		want zapcore.Level
+	}{
+		{"", zapcore.InfoLevel},
+		{"debug", zapcore.DebugLevel},
+		{"info", zapcore.InfoLevel},
+		{"warn", zapcore.WarnLevel},
+		{"error", zapcore.ErrorLevel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			t.Setenv("KM_LOG_LEVEL", tt.env)
+
+			l, err := NewProductionLogger()
+			if err != nil {
+				t.Fatalf("failed to build logger: %v", err)
+			}
+			if got := l.Level(); got != tt.want {
+				t.Errorf("expected level %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNewProductionLoggerFormatFromEnv(t *testing.T) {
+	t.Setenv("KM_LOG_FORMAT", "console")
+	if _, err := NewProductionLogger(); err != nil {
+		t.Errorf("expected console format to be accepted: %v", err)
+	}
+
+	t.Setenv("KM_LOG_FORMAT", "xml")
+	if _, err := NewProductionLogger(); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestNewProductionLoggerRejectsInvalidLevel(t *testing.T) {
+	t.Setenv("KM_LOG_LEVEL", "verbose")
+	if _, err := NewProductionLogger(); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+}
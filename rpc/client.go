@@ -15,20 +15,28 @@ func SendDataToUpdater(pd *detector.PolylangDetector, clientset *kubernetes.Clie
 	wg.Add(1)
 	defer wg.Done()
 	var batch []detector.ContainerInfo
-	ticker := time.NewTicker(10 * time.Second)
+	ticker := time.NewTicker(orDefault(pd.FlushInterval, detector.DefaultFlushInterval))
 	defer ticker.Stop()
 
 	// Send all cached workloads on startup (after a short delay to allow initial detection)
-	time.Sleep(10 * time.Second)
-	sendAllCachedWorkloads(pd)
+	select {
+	case <-time.After(orDefault(pd.StartupDelay, detector.DefaultStartupDelay)):
+		sendAllCachedWorkloads(ctx, pd)
+	case <-ctx.Done():
+	}
 
-	// Create a ticker to periodically send all cached workloads (every 5 minutes)
-	cacheSyncTicker := time.NewTicker(15 * time.Second)
+	// Create a ticker to periodically send all cached workloads (every KM_CACHE_SYNC_INTERVAL, 15s by default)
+	cacheSyncTicker := time.NewTicker(orDefault(pd.CacheSyncInterval, detector.DefaultCacheSyncInterval))
 	defer cacheSyncTicker.Stop()
 
+	heartbeatTicker := time.NewTicker(orDefault(pd.HeartbeatInterval, detector.DefaultHeartbeatInterval))
+	defer heartbeatTicker.Stop()
+
 	for {
 		select {
 		case result := <-pd.Queue:
+			pd.WriteToSinks(result)
+
 			pd.BatchMutex.Lock()
 			batch = append(batch, result)
 			currentSize := len(batch)
@@ -38,7 +46,7 @@ func SendDataToUpdater(pd *detector.PolylangDetector, clientset *kubernetes.Clie
 				pd.DomainLogger.(interface {
 					RPCBatchSending(count int, reason string)
 				}).RPCBatchSending(currentSize, "queue_size_threshold_reached")
-				pd.SendBatch(batch)
+				pd.SendBatchContext(ctx, batch)
 				batch = nil
 			} else {
 				pd.DomainLogger.(interface {
@@ -46,18 +54,20 @@ func SendDataToUpdater(pd *detector.PolylangDetector, clientset *kubernetes.Clie
 				}).RPCBatchQueued(currentSize, pd.QueueSize)
 			}
 		case <-ctx.Done():
-			// Keep the client running for a while to allow all batch of deployments to be sent
+			// Keep the client running for a while to allow all batch of deployments to be sent.
+			// ctx is already done, so the batch gets a single attempt without retry backoff.
 			pd.BatchMutex.Lock()
 			if len(batch) > 0 {
 				pd.DomainLogger.(interface {
 					RPCBatchSending(count int, reason string)
 				}).RPCBatchSending(len(batch), "application_shutdown")
-				pd.SendBatch(batch)
+				pd.SendBatchContext(ctx, batch)
 			}
 			pd.BatchMutex.Unlock()
 			if pd.RpcClient != nil {
 				pd.RpcClient.Close()
 			}
+			pd.CloseSinks()
 			return
 		case <-ticker.C:
 			pd.BatchMutex.Lock()
@@ -65,23 +75,38 @@ func SendDataToUpdater(pd *detector.PolylangDetector, clientset *kubernetes.Clie
 				pd.DomainLogger.(interface {
 					RPCBatchSending(count int, reason string)
 				}).RPCBatchSending(len(batch), "periodic_flush_interval")
-				pd.SendBatch(batch)
+				pd.SendBatchContext(ctx, batch)
 				batch = nil
 			}
 			pd.BatchMutex.Unlock()
 		case <-cacheSyncTicker.C:
 			// Periodically send all cached workloads to keep config updater in sync
-			sendAllCachedWorkloads(pd)
+			sendAllCachedWorkloads(ctx, pd)
+		case result := <-pd.PodResults:
+			pd.PushPodDetectionResult(result)
+		case <-heartbeatTicker.C:
+			pd.SendHeartbeat()
 		}
 	}
 
 }
 
-// sendAllCachedWorkloads sends all active workloads from cache to the config updater
-func sendAllCachedWorkloads(pd *detector.PolylangDetector) {
-	allContainers := pd.Cache.GetAllActiveContainers()
+// orDefault returns d, or def when d is not a positive duration
+func orDefault(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
+}
+
+// sendAllCachedWorkloads sends cached workloads to the config updater. Only containers that
+// pass the same filter as queued results and whose detection changed since the last
+// successful send are included; everything is resent on startup and after a reconnect,
+// when nothing has been confirmed yet.
+func sendAllCachedWorkloads(ctx context.Context, pd *detector.PolylangDetector) {
+	allContainers := pd.UnsentChanges(pd.Options.FilterEnqueueable(pd.Cache.GetAllActiveContainers()))
 	if len(allContainers) == 0 {
-		pd.Logger.Sugar().Info("No cached workloads to send")
+		pd.Logger.Sugar().Info("No changed cached workloads to send")
 		return
 	}
 
@@ -101,8 +126,9 @@ func sendAllCachedWorkloads(pd *detector.PolylangDetector) {
 		pd.DomainLogger.(interface {
 			RPCBatchSending(count int, reason string)
 		}).RPCBatchSending(len(batch), "cached_workloads_sync")
-		pd.SendBatch(batch)
+		pd.SendBatchContext(ctx, batch)
 	}
+	pd.SendWorkloadSummaries(pd.Cache.GetEnqueueableWorkloadSummaries(pd.Options))
 
 	pd.Logger.Sugar().Info("Completed sending cached workloads")
 }
@@ -0,0 +1,269 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	netrpc "net/rpc"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kloudmate/polylang-detector/detector"
+	"github.com/kloudmate/polylang-detector/detector/inspectors"
+	"github.com/kloudmate/polylang-detector/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// recordingBatchSink captures batches flushed by the RPC client
+type recordingBatchSink struct {
+	mu      sync.Mutex
+	batches [][]detector.ContainerInfo
+}
+
+func (s *recordingBatchSink) Name() string { return "recording" }
+func (s *recordingBatchSink) Close() error { return nil }
+
+func (s *recordingBatchSink) WriteBatch(_ context.Context, batch []detector.ContainerInfo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, batch)
+	return nil
+}
+
+func (s *recordingBatchSink) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.batches)
+}
+
+// newTestDetector returns a detector without an updater address, so batches only reach sinks
+func newTestDetector(sink detector.BatchSink) *detector.PolylangDetector {
+	return &detector.PolylangDetector{
+		Logger:       zap.NewNop(),
+		DomainLogger: &logger.DomainLogger{Logger: zap.NewNop()},
+		Queue:        make(chan detector.ContainerInfo, 10),
+		QueueSize:    5,
+		Cache:        detector.NewLanguageCache(0),
+		BatchSinks:   []detector.BatchSink{sink},
+	}
+}
+
+// runClient starts SendDataToUpdater and stops it when the test finishes
+func runClient(t *testing.T, pd *detector.PolylangDetector) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var wg sync.WaitGroup
+		SendDataToUpdater(pd, nil, nil, ctx, &wg)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+// waitFor polls cond until it holds or the timeout elapses
+func waitFor(timeout time.Duration, cond func() bool) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return cond()
+}
+
+func TestSendDataToUpdaterUsesFlushInterval(t *testing.T) {
+	sink := &recordingBatchSink{}
+	pd := newTestDetector(sink)
+	pd.StartupDelay = time.Millisecond
+	pd.FlushInterval = 20 * time.Millisecond
+	pd.CacheSyncInterval = time.Hour
+
+	runClient(t, pd)
+	pd.Queue <- detector.ContainerInfo{Namespace: "shop", ContainerName: "app", Language: "Go"}
+
+	// A single queued result is below QueueSize, so only the flush interval can send it
+	if !waitFor(time.Second, func() bool { return sink.count() == 1 }) {
+		t.Fatalf("expected the partial batch to be flushed, got %d batches", sink.count())
+	}
+}
+
+func TestSendDataToUpdaterUsesCacheSyncInterval(t *testing.T) {
+	sink := &recordingBatchSink{}
+	pd := newTestDetector(sink)
+	pd.StartupDelay = time.Millisecond
+	pd.FlushInterval = time.Hour
+	pd.CacheSyncInterval = 20 * time.Millisecond
+	pd.Cache.UpdateWorkloadContainer("shop", "api", "Deployment", detector.ContainerInfo{Namespace: "shop", DeploymentName: "api", ContainerName: "app", Language: "Java"})
+
+	runClient(t, pd)
+
+	if !waitFor(time.Second, func() bool { return sink.count() == 1 }) {
+		t.Fatalf("expected the startup sync, got %d batches", sink.count())
+	}
+
+	// Only the periodic sync can pick up a change made directly in the cache
+	pd.Cache.UpdateWorkloadContainer("shop", "api", "Deployment", detector.ContainerInfo{Namespace: "shop", DeploymentName: "api", ContainerName: "app", Language: "Java", Framework: "Spring Boot"})
+	if !waitFor(time.Second, func() bool { return sink.count() == 2 }) {
+		t.Fatalf("expected a periodic sync of the changed workload, got %d batches", sink.count())
+	}
+}
+
+func TestSendDataToUpdaterSyncFiltersLikeQueue(t *testing.T) {
+	sink := &recordingBatchSink{}
+	pd := newTestDetector(sink)
+	pd.StartupDelay = time.Millisecond
+	pd.FlushInterval = time.Hour
+	pd.CacheSyncInterval = time.Hour
+	pd.Options.MinConfidence = inspectors.ConfidenceHigh
+	pd.Cache.UpdateWorkloadContainer("shop", "api", "Deployment", detector.ContainerInfo{Namespace: "shop", DeploymentName: "api", ContainerName: "app", Language: "Java", ConfidenceScore: int(inspectors.ConfidenceHigh)})
+	pd.Cache.UpdateWorkloadContainer("shop", "worker", "Deployment", detector.ContainerInfo{Namespace: "shop", DeploymentName: "worker", ContainerName: "app", Language: "Python", ConfidenceScore: int(inspectors.ConfidenceMedium)})
+	pd.Cache.UpdateWorkloadContainer("shop", "cache", "StatefulSet", detector.ContainerInfo{Namespace: "shop", DeploymentName: "cache", ContainerName: "redis", Language: "Redis", ConfidenceScore: int(inspectors.ConfidenceHigh)})
+
+	runClient(t, pd)
+
+	if !waitFor(time.Second, func() bool { return sink.count() == 1 }) {
+		t.Fatalf("expected the startup sync, got %d batches", sink.count())
+	}
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if batch := sink.batches[0]; len(batch) != 1 || batch[0].DeploymentName != "api" {
+		t.Errorf("expected only the high-confidence Java workload to be synced, got %+v", batch)
+	}
+}
+
+// heartbeatRecorder stands in for the updater's RPCHandler and records heartbeats
+type heartbeatRecorder struct {
+	mu         sync.Mutex
+	heartbeats []detector.Heartbeat
+}
+
+func (r *heartbeatRecorder) Heartbeat(heartbeat detector.Heartbeat, reply *string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.heartbeats = append(r.heartbeats, heartbeat)
+	return nil
+}
+
+func (r *heartbeatRecorder) received() []detector.Heartbeat {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]detector.Heartbeat(nil), r.heartbeats...)
+}
+
+func TestSendDataToUpdaterSendsHeartbeats(t *testing.T) {
+	recorder := &heartbeatRecorder{}
+	server := netrpc.NewServer()
+	if err := server.RegisterName("RPCHandler", recorder); err != nil {
+		t.Fatalf("failed to register handler: %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go server.Accept(listener)
+
+	client, err := netrpc.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+
+	pd := newTestDetector(&recordingBatchSink{})
+	pd.ServerAddr = listener.Addr().String()
+	pd.RpcClient = client
+	pd.StartupDelay = time.Millisecond
+	pd.FlushInterval = time.Hour
+	pd.CacheSyncInterval = time.Hour
+	pd.HeartbeatInterval = 20 * time.Millisecond
+	pd.NodeName = "node-a"
+	pd.DetectorVersion = "1.2.3"
+
+	runClient(t, pd)
+
+	if !waitFor(time.Second, func() bool { return len(recorder.received()) >= 3 }) {
+		t.Fatalf("expected at least 3 heartbeats, got %d", len(recorder.received()))
+	}
+	heartbeats := recorder.received()
+	for i, heartbeat := range heartbeats {
+		if heartbeat.NodeName != "node-a" || heartbeat.DetectorVersion != "1.2.3" {
+			t.Errorf("unexpected heartbeat %+v", heartbeat)
+		}
+		if i > 0 && heartbeat.Timestamp.Sub(heartbeats[i-1].Timestamp) < 10*time.Millisecond {
+			t.Errorf("heartbeats %d and %d were sent faster than the configured interval", i-1, i)
+		}
+	}
+}
+
+// podResultRecorder is an RPCHandler stand-in that records pod detection results
+type podResultRecorder struct {
+	mu      sync.Mutex
+	results []detector.PodDetectionResult
+}
+
+func (r *podResultRecorder) PushPodDetectionResults(results []detector.PodDetectionResult, reply *string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results = append(r.results, results...)
+	*reply = "ok"
+	return nil
+}
+
+func TestSendPodDetectionResultReachesUpdater(t *testing.T) {
+	recorder := &podResultRecorder{}
+	server := netrpc.NewServer()
+	if err := server.RegisterName("RPCHandler", recorder); err != nil {
+		t.Fatalf("failed to register handler: %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go server.Accept(listener)
+
+	client, err := netrpc.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	pd := newTestDetector(&recordingBatchSink{})
+	pd.ServerAddr = listener.Addr().String()
+	pd.RpcClient = client
+	pd.PodResults = make(chan detector.PodDetectionResult, 10)
+	pd.StartupDelay = time.Millisecond
+	runClient(t, pd)
+
+	app := detector.ContainerInfo{Namespace: "shop", PodName: "orders-0", ContainerName: "orders", Language: "Python", Confidence: "high", ConfidenceScore: 90}
+	proxy := detector.ContainerInfo{Namespace: "shop", PodName: "orders-0", ContainerName: "cloudsql", Image: "gcr.io/cloud-sql-connectors/cloud-sql-proxy:2.8.0", Language: "Go", Confidence: "high", ConfidenceScore: 90}
+	pd.SendPodDetectionResult(detector.NewPodDetectionResult("shop", "orders-0", []detector.ContainerInfo{proxy, app}, pd.Options))
+
+	// A pod with nothing to instrument is not sent
+	redis := detector.ContainerInfo{Namespace: "shop", PodName: "cache-0", ContainerName: "redis", Language: "redis"}
+	pd.SendPodDetectionResult(detector.NewPodDetectionResult("shop", "cache-0", []detector.ContainerInfo{redis}, pd.Options))
+
+	// The pod results are pushed by the updater goroutine, which owns the RPC client
+	received := func() int {
+		recorder.mu.Lock()
+		defer recorder.mu.Unlock()
+		return len(recorder.results)
+	}
+	if !waitFor(2*time.Second, func() bool { return received() > 0 }) {
+		t.Fatal("expected the pod result to reach the updater")
+	}
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if len(recorder.results) != 1 {
+		t.Fatalf("expected one pod result, got %d", len(recorder.results))
+	}
+	if result := recorder.results[0]; result.PodName != "orders-0" || result.PrimaryContainer != "orders" || result.PrimaryLanguage != "Python" {
+		t.Errorf("unexpected pod result %+v", result)
+	}
+}
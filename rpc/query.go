@@ -0,0 +1,125 @@
+package rpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	netrpc "net/rpc"
+	"os"
+
+	"github.com/kloudmate/polylang-detector/detector"
+)
+
+// ErrUnauthorized is returned to query callers that don't present the configured token
+var ErrUnauthorized = errors.New("unauthorized: invalid query token")
+
+// QueryArgs are the arguments of a detection state query
+type QueryArgs struct {
+	// Token must match the server's token when one is configured
+	Token string
+}
+
+// QueryHandler lets the config updater or an operator pull the detector's current
+// detection state, e.g. to reconcile after the updater restarts.
+type QueryHandler struct {
+	pd    *detector.PolylangDetector
+	token string
+}
+
+// NewQueryHandler returns a handler serving pd's cache. An empty token disables authentication.
+func NewQueryHandler(pd *detector.PolylangDetector, token string) *QueryHandler {
+	return &QueryHandler{pd: pd, token: token}
+}
+
+// GetCurrentDetections returns every container currently held in the detector's workload
+// cache, with environment variables redacted.
+func (h *QueryHandler) GetCurrentDetections(args QueryArgs, reply *[]detector.ContainerInfo) error {
+	if h.token != "" && subtle.ConstantTimeCompare([]byte(args.Token), []byte(h.token)) != 1 {
+		return ErrUnauthorized
+	}
+	containers := h.pd.Cache.GetAllActiveContainers()
+	for i := range containers {
+		containers[i] = containers[i].Redacted()
+	}
+	*reply = containers
+	return nil
+}
+
+// QueryServer serves QueryHandler over net/rpc
+type QueryServer struct {
+	Addr    string
+	handler *QueryHandler
+}
+
+// NewQueryServerFromEnv returns a query server listening on KM_RPC_QUERY_ADDR and
+// requiring KM_RPC_QUERY_TOKEN (if set), or nil when KM_RPC_QUERY_ADDR is unset.
+func NewQueryServerFromEnv(pd *detector.PolylangDetector) *QueryServer {
+	addr := os.Getenv("KM_RPC_QUERY_ADDR")
+	if addr == "" {
+		return nil
+	}
+	return &QueryServer{Addr: addr, handler: NewQueryHandler(pd, os.Getenv("KM_RPC_QUERY_TOKEN"))}
+}
+
+// ListenAndServe listens on the server's address and serves queries until ctx is done.
+// The address may be a unix:///path/to.sock endpoint; the socket file is removed on shutdown.
+func (s *QueryServer) ListenAndServe(ctx context.Context) error {
+	network, address := detector.EndpointNetwork(s.Addr)
+	if network == "unix" {
+		if err := removeStaleSocket(address); err != nil {
+			return err
+		}
+	}
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return err
+	}
+	return serveQueries(ctx, listener, s.handler)
+}
+
+// removeStaleSocket removes a socket left behind by an unclean exit, which would make
+// Listen fail. Anything other than a socket at address is left alone.
+func removeStaleSocket(address string) error {
+	info, err := os.Lstat(address)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat query socket %s: %w", address, err)
+	}
+	if info.Mode().Type() != fs.ModeSocket {
+		return fmt.Errorf("query socket path %s exists and is not a socket", address)
+	}
+	if err := os.Remove(address); err != nil {
+		return fmt.Errorf("failed to remove stale query socket %s: %w", address, err)
+	}
+	return nil
+}
+
+// serveQueries serves handler on listener until ctx is done, then closes the listener
+func serveQueries(ctx context.Context, listener net.Listener, handler *QueryHandler) error {
+	server := netrpc.NewServer()
+	if err := server.Register(handler); err != nil {
+		listener.Close()
+		return err
+	}
+
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go server.ServeConn(conn)
+	}
+}
@@ -0,0 +1,123 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	netrpc "net/rpc"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kloudmate/polylang-detector/detector"
+)
+
+// startQueryServer serves a query handler on a loopback port and returns a connected client
+func startQueryServer(t *testing.T, pd *detector.PolylangDetector, token string) *netrpc.Client {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- serveQueries(ctx, listener, NewQueryHandler(pd, token)) }()
+
+	client, err := netrpc.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to dial query server: %v", err)
+	}
+	t.Cleanup(func() {
+		client.Close()
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("query server returned error: %v", err)
+		}
+	})
+	return client
+}
+
+func TestGetCurrentDetectionsReturnsCacheContents(t *testing.T) {
+	pd := newTestDetector(&recordingBatchSink{})
+	pd.Cache.UpdateWorkloadContainer("shop", "checkout", "Deployment", detector.ContainerInfo{Namespace: "shop", ContainerName: "app", Language: "Java", EnvVars: map[string]string{"DB_PASSWORD": "hunter2"}})
+	pd.Cache.UpdateWorkloadContainer("shop", "cart", "Deployment", detector.ContainerInfo{Namespace: "shop", ContainerName: "api", Language: "Go"})
+
+	client := startQueryServer(t, pd, "secret")
+
+	var detections []detector.ContainerInfo
+	if err := client.Call("QueryHandler.GetCurrentDetections", QueryArgs{Token: "secret"}, &detections); err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+
+	want := map[string]string{"app": "Java", "api": "Go"}
+	if len(detections) != len(want) {
+		t.Fatalf("expected %d detections, got %d", len(want), len(detections))
+	}
+	for _, info := range detections {
+		if want[info.ContainerName] != info.Language {
+			t.Errorf("unexpected detection %s=%s", info.ContainerName, info.Language)
+		}
+		if info.EnvVars != nil {
+			t.Errorf("expected env vars of %s to be redacted, got %v", info.ContainerName, info.EnvVars)
+		}
+	}
+}
+
+func TestGetCurrentDetectionsRejectsWrongToken(t *testing.T) {
+	client := startQueryServer(t, newTestDetector(&recordingBatchSink{}), "secret")
+
+	var detections []detector.ContainerInfo
+	err := client.Call("QueryHandler.GetCurrentDetections", QueryArgs{Token: "wrong"}, &detections)
+	var serverErr netrpc.ServerError
+	if !errors.As(err, &serverErr) || string(serverErr) != ErrUnauthorized.Error() {
+		t.Errorf("expected unauthorized error, got %v", err)
+	}
+}
+
+func TestQueryServerRemovesUnixSocketOnShutdown(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "query.sock")
+	server := &QueryServer{Addr: "unix://" + socket, handler: NewQueryHandler(newTestDetector(&recordingBatchSink{}), "")}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.ListenAndServe(ctx) }()
+
+	var client *netrpc.Client
+	if !waitFor(time.Second, func() bool {
+		var err error
+		client, err = netrpc.Dial("unix", socket)
+		return err == nil
+	}) {
+		t.Fatal("query server did not start listening on the unix socket")
+	}
+	var detections []detector.ContainerInfo
+	if err := client.Call("QueryHandler.GetCurrentDetections", QueryArgs{}, &detections); err != nil {
+		t.Errorf("query over unix socket failed: %v", err)
+	}
+	client.Close()
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("query server returned error: %v", err)
+	}
+	if _, err := os.Stat(socket); !os.IsNotExist(err) {
+		t.Errorf("expected the socket file to be removed, got %v", err)
+	}
+}
+
+func TestQueryServerKeepsNonSocketAtUnixAddress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "query.sock")
+	if err := os.WriteFile(path, []byte("not a socket"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	server := &QueryServer{Addr: "unix://" + path, handler: NewQueryHandler(newTestDetector(&recordingBatchSink{}), "")}
+
+	if err := server.ListenAndServe(context.Background()); err == nil {
+		t.Fatal("expected an error for a regular file at the socket path")
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "not a socket" {
+		t.Errorf("expected the file to be left alone, got %q, %v", data, err)
+	}
+}
@@ -3,18 +3,75 @@ package rpc
 import (
 	"fmt"
 	"log"
+	"sync"
+	"time"
 
 	"github.com/kloudmate/polylang-detector/detector"
 )
 
-type RPCHandler struct{}
+type RPCHandler struct {
+	mu       sync.Mutex
+	lastSeen map[string]time.Time // node name -> when its last heartbeat was received
+}
 
 // PushDetectionResults receives a batch of ContainerInfo structs from a client.
 func (h *RPCHandler) PushDetectionResults(results []detector.ContainerInfo, reply *string) error {
 	log.Println("Received a batch of detection results via RPC.", "size", len(results))
 	for _, info := range results {
-		log.Println("Received result", "namespace", info.Namespace, "kind", info.Kind, "container", info.ContainerName, "language", info.Language)
+		log.Println("Received result", "namespace", info.Namespace, "kind", info.Kind, "container", info.ContainerName, "language", info.Language, "version", info.Version, "arch", info.Architecture, "libc", info.LibcType)
 	}
 	*reply = fmt.Sprintf("Successfully processed %d results.", len(results))
 	return nil
 }
+
+// PushWorkloadSummaries receives the per-workload language summaries sent with each cache sync.
+func (h *RPCHandler) PushWorkloadSummaries(summaries []detector.WorkloadSummary, reply *string) error {
+	for _, summary := range summaries {
+		log.Println("Received workload summary", "namespace", summary.Namespace, "workload", summary.WorkloadName, "language", summary.DominantLanguage, "primary_language", summary.PrimaryLanguage, "primary_container", summary.PrimaryContainer, "polyglot", summary.Polyglot, "frameworks", summary.Frameworks)
+	}
+	*reply = fmt.Sprintf("Successfully processed %d workload summaries.", len(summaries))
+	return nil
+}
+
+// PushPodDetectionResults receives the per-pod results, with the language picked for each pod.
+func (h *RPCHandler) PushPodDetectionResults(results []detector.PodDetectionResult, reply *string) error {
+	for _, result := range results {
+		log.Println("Received pod detection result", "namespace", result.Namespace, "pod", result.PodName, "primary_language", result.PrimaryLanguage, "primary_container", result.PrimaryContainer, "containers", len(result.Containers))
+	}
+	*reply = fmt.Sprintf("Successfully processed %d pod detection results.", len(results))
+	return nil
+}
+
+// PushCompressedDetectionResults receives a gzip-compressed batch (sent when the client
+// enables KM_RPC_COMPRESS) and processes it like PushDetectionResults.
+func (h *RPCHandler) PushCompressedDetectionResults(batch detector.CompressedBatch, reply *string) error {
+	results, err := detector.DecompressBatch(batch)
+	if err != nil {
+		return err
+	}
+	log.Println("Decompressed detection batch", "compressed_bytes", len(batch.Payload), "size", len(results))
+	return h.PushDetectionResults(results, reply)
+}
+
+// Heartbeat records that the detector on a node is alive. The time of receipt is kept
+// rather than the detector's Timestamp, so clock skew between nodes can't hide a stale detector.
+func (h *RPCHandler) Heartbeat(heartbeat detector.Heartbeat, reply *string) error {
+	log.Println("Received heartbeat", "node", heartbeat.NodeName, "version", heartbeat.DetectorVersion, "commit", heartbeat.DetectorCommit)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.lastSeen == nil {
+		h.lastSeen = make(map[string]time.Time)
+	}
+	h.lastSeen[heartbeat.NodeName] = time.Now()
+	*reply = "ok"
+	return nil
+}
+
+// LastSeen returns when a heartbeat from the detector on node was last received, so stale detectors can be alerted on.
+func (h *RPCHandler) LastSeen(node string) (time.Time, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	seen, ok := h.lastSeen[node]
+	return seen, ok
+}
@@ -0,0 +1,26 @@
+package rpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kloudmate/polylang-detector/detector"
+)
+
+func TestHeartbeatRecordsLastSeen(t *testing.T) {
+	handler := &RPCHandler{}
+	// The detector's clock lags far behind; its timestamp must not make it look stale
+	skewed := time.Now().Add(-time.Hour)
+
+	before := time.Now()
+	var reply string
+	if err := handler.Heartbeat(detector.Heartbeat{NodeName: "node-a", Timestamp: skewed}, &reply); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if seen, ok := handler.LastSeen("node-a"); !ok || seen.Before(before) || seen.After(time.Now()) {
+		t.Errorf("expected node-a last seen when the heartbeat was received, got %v (%v)", seen, ok)
+	}
+	if _, ok := handler.LastSeen("node-b"); ok {
+		t.Error("expected no heartbeat recorded for node-b")
+	}
+}
@@ -0,0 +1,115 @@
+package sink
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/kloudmate/polylang-detector/detector"
+)
+
+// defaultFileMaxMB is the size at which the output file is rotated
+const defaultFileMaxMB = 100
+
+// FileSink appends detection results to a file as newline-delimited JSON.
+// When the file exceeds maxBytes it is rotated to <path>.1, replacing any previous rotation.
+type FileSink struct {
+	mu       sync.Mutex
+	path     string
+	maxBytes int64
+	file     *os.File
+	size     int64
+}
+
+// NewFileSink opens (or creates) path for appending
+func NewFileSink(path string, maxBytes int64) (*FileSink, error) {
+	fs := &FileSink{path: path, maxBytes: maxBytes}
+	if err := fs.open(); err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+// NewFileSinkFromEnv creates a FileSink from KM_OUTPUT_FILE and KM_OUTPUT_FILE_MAX_MB.
+// It returns nil when KM_OUTPUT_FILE is not set.
+func NewFileSinkFromEnv() (*FileSink, error) {
+	path := os.Getenv("KM_OUTPUT_FILE")
+	if path == "" {
+		return nil, nil
+	}
+
+	maxMB := defaultFileMaxMB
+	if mb, err := strconv.Atoi(os.Getenv("KM_OUTPUT_FILE_MAX_MB")); err == nil && mb > 0 {
+		maxMB = mb
+	}
+
+	return NewFileSink(path, int64(maxMB)*1024*1024)
+}
+
+// Name identifies the sink in logs
+func (fs *FileSink) Name() string {
+	return "file"
+}
+
+// Write appends info as a single JSON line, rotating the file first if it would exceed the limit
+func (fs *FileSink) Write(info detector.ContainerInfo) error {
+	line, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("failed to marshal detection result: %w", err)
+	}
+	line = append(line, '\n')
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.maxBytes > 0 && fs.size > 0 && fs.size+int64(len(line)) > fs.maxBytes {
+		if err := fs.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := fs.file.Write(line)
+	fs.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write to %s: %w", fs.path, err)
+	}
+	return nil
+}
+
+// Close closes the underlying file
+func (fs *FileSink) Close() error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return fs.file.Close()
+}
+
+// open opens the output file in append mode and records its current size
+func (fs *FileSink) open() error {
+	file, err := os.OpenFile(fs.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open output file %s: %w", fs.path, err)
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat output file %s: %w", fs.path, err)
+	}
+
+	fs.file = file
+	fs.size = stat.Size()
+	return nil
+}
+
+// rotate moves the current file to <path>.1 and starts a fresh one
+func (fs *FileSink) rotate() error {
+	if err := fs.file.Close(); err != nil {
+		return fmt.Errorf("failed to close output file for rotation: %w", err)
+	}
+	if err := os.Rename(fs.path, fs.path+".1"); err != nil {
+		return fmt.Errorf("failed to rotate output file: %w", err)
+	}
+	return fs.open()
+}
@@ -0,0 +1,94 @@
+package sink
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kloudmate/polylang-detector/detector"
+)
+
+func readLines(t *testing.T, path string) []map[string]interface{} {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var decoded map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &decoded); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, decoded)
+	}
+	return lines
+}
+
+func TestFileSinkWritesJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "detections.jsonl")
+	fs, err := NewFileSink(path, 0)
+	if err != nil {
+		t.Fatalf("failed to create sink: %v", err)
+	}
+
+	info := detector.ContainerInfo{
+		Namespace:     "shop",
+		PodName:       "checkout-7d9f",
+		ContainerName: "app",
+		Language:      "Java",
+		Confidence:    "high",
+		DetectedAt:    time.Now(),
+		Evidence:      []string{"Detected via /proc inspection with high confidence"},
+	}
+	if err := fs.Write(info); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if err := fs.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	lines := readLines(t, path)
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 line, got %d", len(lines))
+	}
+	if lines[0]["language"] != "Java" || lines[0]["container_name"] != "app" {
+		t.Errorf("unexpected JSON content: %v", lines[0])
+	}
+	if evidence, ok := lines[0]["evidence"].([]interface{}); !ok || len(evidence) != 1 {
+		t.Errorf("expected evidence to be serialized, got %v", lines[0]["evidence"])
+	}
+}
+
+func TestFileSinkRotatesBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "detections.jsonl")
+	fs, err := NewFileSink(path, 300)
+	if err != nil {
+		t.Fatalf("failed to create sink: %v", err)
+	}
+	defer fs.Close()
+
+	for i := 0; i < 5; i++ {
+		if err := fs.Write(detector.ContainerInfo{Namespace: "default", ContainerName: "app", Language: "Go"}); err != nil {
+			t.Fatalf("write %d failed: %v", i, err)
+		}
+	}
+
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Fatalf("expected rotated file to exist: %v", err)
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("expected current file to exist: %v", err)
+	}
+	if stat.Size() > 300 {
+		t.Errorf("expected current file under the rotation limit, got %d bytes", stat.Size())
+	}
+}
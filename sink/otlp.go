@@ -0,0 +1,178 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+
+	"github.com/kloudmate/polylang-detector/detector"
+)
+
+const (
+	otlpScopeName     = "github.com/kloudmate/polylang-detector"
+	otlpMetricName    = "polylang.workload.detected"
+	otlpUpgradesName  = "polylang.detection.confidence_upgrades"
+	otlpServiceName   = "polylang-detector"
+	otlpExportTimeout = 10 * time.Second
+)
+
+// Attribute keys for detection details that have no semantic convention
+const (
+	otlpFrameworkKey  = attribute.Key("polylang.detection.framework")
+	otlpConfidenceKey = attribute.Key("polylang.detection.confidence")
+)
+
+// sdkLanguages maps detected language names to telemetry.sdk.language values
+var sdkLanguages = map[string]string{
+	".NET":    "dotnet",
+	"Node.js": "nodejs",
+	"nodejs":  "nodejs",
+}
+
+// metricExporter is the subset of the OTLP metric exporter used by OTLPSink
+type metricExporter interface {
+	Export(ctx context.Context, rm *metricdata.ResourceMetrics) error
+	Shutdown(ctx context.Context) error
+}
+
+// OTLPSink exports each detected workload as an OTLP gauge whose resource carries
+// service.name, telemetry.sdk.language and the detection framework and confidence
+type OTLPSink struct {
+	exporter metricExporter
+	started  time.Time // start of the cumulative counters
+
+	// ConfidenceUpgrades, if set, is exported with each result as the cumulative
+	// polylang.detection.confidence_upgrades counter of the detector itself
+	ConfidenceUpgrades func() int64
+}
+
+// NewOTLPSink creates an OTLPSink exporting over OTLP/HTTP to endpoint (e.g. http://collector:4318)
+func NewOTLPSink(ctx context.Context, endpoint string) (*OTLPSink, error) {
+	exporter, err := otlpmetrichttp.New(ctx,
+		otlpmetrichttp.WithEndpointURL(endpoint),
+		otlpmetrichttp.WithTimeout(otlpExportTimeout),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter for %s: %w", endpoint, err)
+	}
+	return &OTLPSink{exporter: exporter, started: time.Now()}, nil
+}
+
+// NewOTLPSinkFromEnv creates an OTLPSink from KM_OTLP_ENDPOINT.
+// It returns nil when KM_OTLP_ENDPOINT is not set.
+func NewOTLPSinkFromEnv(ctx context.Context) (*OTLPSink, error) {
+	endpoint := os.Getenv("KM_OTLP_ENDPOINT")
+	if endpoint == "" {
+		return nil, nil
+	}
+	return NewOTLPSink(ctx, endpoint)
+}
+
+// Name identifies the sink in logs
+func (ots *OTLPSink) Name() string {
+	return "otlp"
+}
+
+// Write exports a single detection result
+func (ots *OTLPSink) Write(info detector.ContainerInfo) error {
+	ctx, cancel := context.WithTimeout(context.Background(), otlpExportTimeout)
+	defer cancel()
+
+	if err := ots.exporter.Export(ctx, workloadMetrics(info)); err != nil {
+		return fmt.Errorf("failed to export detection result via OTLP: %w", err)
+	}
+	if ots.ConfidenceUpgrades != nil {
+		if err := ots.exporter.Export(ctx, detectorMetrics(ots.started, ots.ConfidenceUpgrades())); err != nil {
+			return fmt.Errorf("failed to export detector metrics via OTLP: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close shuts down the exporter
+func (ots *OTLPSink) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), otlpExportTimeout)
+	defer cancel()
+	return ots.exporter.Shutdown(ctx)
+}
+
+// workloadMetrics describes a detected workload as resource metrics with a single gauge point
+func workloadMetrics(info detector.ContainerInfo) *metricdata.ResourceMetrics {
+	serviceName := info.DeploymentName
+	if serviceName == "" {
+		serviceName = info.PodName
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.ServiceName(serviceName),
+		semconv.TelemetrySDKLanguageKey.String(sdkLanguage(info.Language)),
+		semconv.K8SNamespaceName(info.Namespace),
+		semconv.K8SContainerName(info.ContainerName),
+		semconv.ContainerImageName(info.Image),
+		otlpConfidenceKey.String(info.Confidence),
+	}
+	if info.Framework != "" {
+		attrs = append(attrs, otlpFrameworkKey.String(info.Framework))
+	}
+
+	detectedAt := info.DetectedAt
+	if detectedAt.IsZero() {
+		detectedAt = time.Now()
+	}
+
+	return &metricdata.ResourceMetrics{
+		Resource: resource.NewSchemaless(attrs...),
+		ScopeMetrics: []metricdata.ScopeMetrics{{
+			Scope: instrumentation.Scope{Name: otlpScopeName},
+			Metrics: []metricdata.Metrics{{
+				Name:        otlpMetricName,
+				Description: "Workload detected by the polylang detector",
+				Unit:        "1",
+				Data: metricdata.Gauge[int64]{
+					DataPoints: []metricdata.DataPoint[int64]{{Time: detectedAt, Value: 1}},
+				},
+			}},
+		}},
+	}
+}
+
+// detectorMetrics describes the detector's own counters as resource metrics
+func detectorMetrics(started time.Time, confidenceUpgrades int64) *metricdata.ResourceMetrics {
+	return &metricdata.ResourceMetrics{
+		Resource: resource.NewSchemaless(semconv.ServiceName(otlpServiceName)),
+		ScopeMetrics: []metricdata.ScopeMetrics{{
+			Scope: instrumentation.Scope{Name: otlpScopeName},
+			Metrics: []metricdata.Metrics{{
+				Name:        otlpUpgradesName,
+				Description: "Cached detections re-detected with higher confidence than ever before",
+				Unit:        "1",
+				Data: metricdata.Sum[int64]{
+					Temporality: metricdata.CumulativeTemporality,
+					IsMonotonic: true,
+					DataPoints: []metricdata.DataPoint[int64]{{
+						StartTime: started,
+						Time:      time.Now(),
+						Value:     confidenceUpgrades,
+					}},
+				},
+			}},
+		}},
+	}
+}
+
+// sdkLanguage converts a detected language name to its telemetry.sdk.language value
+func sdkLanguage(language string) string {
+	if value, ok := sdkLanguages[language]; ok {
+		return value
+	}
+	return strings.ToLower(language)
+}
@@ -0,0 +1,126 @@
+package sink
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/kloudmate/polylang-detector/detector"
+)
+
+// otlpReceiver is an in-memory OTLP/HTTP metrics receiver
+type otlpReceiver struct {
+	mu       sync.Mutex
+	requests []*colmetricpb.ExportMetricsServiceRequest
+}
+
+func (r *otlpReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	export := &colmetricpb.ExportMetricsServiceRequest{}
+	if err := proto.Unmarshal(body, export); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	r.mu.Lock()
+	r.requests = append(r.requests, export)
+	r.mu.Unlock()
+
+	resp, _ := proto.Marshal(&colmetricpb.ExportMetricsServiceResponse{})
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Write(resp)
+}
+
+func TestOTLPSinkExportsResourceAttributes(t *testing.T) {
+	receiver := &otlpReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	ots, err := NewOTLPSink(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("failed to create sink: %v", err)
+	}
+
+	info := detector.ContainerInfo{
+		Namespace:      "shop",
+		DeploymentName: "checkout",
+		ContainerName:  "app",
+		Language:       "Node.js",
+		Framework:      "Express",
+		Confidence:     "high",
+	}
+	if err := ots.Write(info); err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+	if err := ots.Close(); err != nil {
+		t.Fatalf("failed to close sink: %v", err)
+	}
+
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	if len(receiver.requests) != 1 || len(receiver.requests[0].ResourceMetrics) != 1 {
+		t.Fatalf("expected a single resource export, got %v", receiver.requests)
+	}
+
+	attrs := map[string]string{}
+	for _, kv := range receiver.requests[0].ResourceMetrics[0].Resource.Attributes {
+		attrs[kv.Key] = kv.Value.GetStringValue()
+	}
+
+	expected := map[string]string{
+		"service.name":                  "checkout",
+		"telemetry.sdk.language":        "nodejs",
+		"k8s.namespace.name":            "shop",
+		"polylang.detection.framework":  "Express",
+		"polylang.detection.confidence": "high",
+	}
+	for key, value := range expected {
+		if attrs[key] != value {
+			t.Errorf("expected %s=%q, got %q", key, value, attrs[key])
+		}
+	}
+}
+
+func TestOTLPSinkExportsConfidenceUpgrades(t *testing.T) {
+	receiver := &otlpReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	ots, err := NewOTLPSink(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("failed to create sink: %v", err)
+	}
+	ots.ConfidenceUpgrades = func() int64 { return 3 }
+
+	if err := ots.Write(detector.ContainerInfo{Namespace: "shop", DeploymentName: "checkout", Language: "Java"}); err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+	if err := ots.Close(); err != nil {
+		t.Fatalf("failed to close sink: %v", err)
+	}
+
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	if len(receiver.requests) != 2 {
+		t.Fatalf("expected the workload and the detector counters to be exported, got %d requests", len(receiver.requests))
+	}
+	metric := receiver.requests[1].ResourceMetrics[0].ScopeMetrics[0].Metrics[0]
+	if metric.Name != "polylang.detection.confidence_upgrades" {
+		t.Fatalf("expected the confidence upgrades counter, got %s", metric.Name)
+	}
+	sum := metric.GetSum()
+	if sum == nil || !sum.IsMonotonic || len(sum.DataPoints) != 1 || sum.DataPoints[0].GetAsInt() != 3 {
+		t.Errorf("expected a monotonic sum of 3, got %v", sum)
+	}
+}
@@ -0,0 +1,127 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/kloudmate/polylang-detector/detector"
+)
+
+const (
+	defaultWebhookTimeout    = 10 * time.Second
+	defaultWebhookMaxRetries = 3
+	defaultWebhookBackoff    = time.Second
+)
+
+// WebhookLogger receives webhook delivery failures (implemented by the domain logger)
+type WebhookLogger interface {
+	WebhookDeliveryFailed(url string, attempt int, err error)
+}
+
+// WebhookSink POSTs each detection batch as a JSON array to an HTTP endpoint
+type WebhookSink struct {
+	URL        string
+	AuthHeader string // sent as the Authorization header when set
+	MaxRetries int
+	Backoff    time.Duration
+	Client     *http.Client
+	Logger     WebhookLogger
+}
+
+// NewWebhookSinkFromEnv creates a WebhookSink from KM_WEBHOOK_URL, KM_WEBHOOK_AUTH_HEADER,
+// KM_WEBHOOK_TIMEOUT and KM_WEBHOOK_MAX_RETRIES. It returns nil when KM_WEBHOOK_URL is not set.
+func NewWebhookSinkFromEnv(logger WebhookLogger) *WebhookSink {
+	url := os.Getenv("KM_WEBHOOK_URL")
+	if url == "" {
+		return nil
+	}
+
+	timeout := defaultWebhookTimeout
+	if parsed, err := time.ParseDuration(os.Getenv("KM_WEBHOOK_TIMEOUT")); err == nil && parsed > 0 {
+		timeout = parsed
+	}
+
+	maxRetries := defaultWebhookMaxRetries
+	if parsed, err := strconv.Atoi(os.Getenv("KM_WEBHOOK_MAX_RETRIES")); err == nil && parsed >= 0 {
+		maxRetries = parsed
+	}
+
+	return &WebhookSink{
+		URL:        url,
+		AuthHeader: os.Getenv("KM_WEBHOOK_AUTH_HEADER"),
+		MaxRetries: maxRetries,
+		Backoff:    defaultWebhookBackoff,
+		Client:     &http.Client{Timeout: timeout},
+		Logger:     logger,
+	}
+}
+
+// Name identifies the sink in logs
+func (ws *WebhookSink) Name() string {
+	return "webhook"
+}
+
+// WriteBatch POSTs the batch, retrying non-2xx responses and transport errors with
+// exponential backoff up to MaxRetries times. It gives up early once ctx is done.
+func (ws *WebhookSink) WriteBatch(ctx context.Context, batch []detector.ContainerInfo) error {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("failed to marshal detection batch: %w", err)
+	}
+
+	backoff := ws.Backoff
+	var lastErr error
+	for attempt := 1; attempt <= ws.MaxRetries+1; attempt++ {
+		if lastErr = ws.post(ctx, body); lastErr == nil {
+			return nil
+		}
+
+		if ws.Logger != nil {
+			ws.Logger.WebhookDeliveryFailed(ws.URL, attempt, lastErr)
+		}
+		if attempt <= ws.MaxRetries {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("webhook delivery abandoned after %d attempts: %w", attempt, ctx.Err())
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+	}
+
+	return fmt.Errorf("webhook delivery failed after %d attempts: %w", ws.MaxRetries+1, lastErr)
+}
+
+// Close is a no-op; the HTTP client holds no resources that need releasing
+func (ws *WebhookSink) Close() error {
+	return nil
+}
+
+// post sends a single request and treats any non-2xx status as an error
+func (ws *WebhookSink) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ws.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if ws.AuthHeader != "" {
+		req.Header.Set("Authorization", ws.AuthHeader)
+	}
+
+	resp, err := ws.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kloudmate/polylang-detector/detector"
+)
+
+type recordingWebhookLogger struct {
+	mu       sync.Mutex
+	failures int
+}
+
+func (l *recordingWebhookLogger) WebhookDeliveryFailed(url string, attempt int, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.failures++
+}
+
+func TestWebhookSinkRetriesUntilSuccess(t *testing.T) {
+	var mu sync.Mutex
+	var requests int
+	var received []detector.ContainerInfo
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("expected auth header, got %q", r.Header.Get("Authorization"))
+		}
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	logger := &recordingWebhookLogger{}
+	ws := &WebhookSink{
+		URL:        server.URL,
+		AuthHeader: "Bearer secret",
+		MaxRetries: 2,
+		Client:     server.Client(),
+		Logger:     logger,
+	}
+
+	batch := []detector.ContainerInfo{{Namespace: "shop", ContainerName: "app", Language: "Python"}}
+	if err := ws.WriteBatch(context.Background(), batch); err != nil {
+		t.Fatalf("expected delivery to succeed, got %v", err)
+	}
+
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+	if logger.failures != 1 {
+		t.Errorf("expected 1 logged failure, got %d", logger.failures)
+	}
+	if len(received) != 1 || received[0].Language != "Python" {
+		t.Errorf("unexpected payload: %+v", received)
+	}
+}
+
+func TestWebhookSinkGivesUpAfterMaxRetries(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	ws := &WebhookSink{URL: server.URL, MaxRetries: 2, Client: server.Client()}
+	if err := ws.WriteBatch(context.Background(), []detector.ContainerInfo{{Language: "Go"}}); err == nil {
+		t.Fatal("expected an error after exhausting retries")
+	}
+	if requests != 3 {
+		t.Errorf("expected 3 attempts, got %d", requests)
+	}
+}
+
+func TestWebhookSinkStopsRetryingWhenContextIsDone(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	ws := &WebhookSink{URL: server.URL, MaxRetries: 5, Backoff: time.Minute, Client: server.Client()}
+
+	start := time.Now()
+	err := ws.WriteBatch(ctx, []detector.ContainerInfo{{Language: "Go"}})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected delivery to be abandoned with the context, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected WriteBatch to return once the context was done, took %s", elapsed)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("expected no retry after the context was done, got %d requests", requests.Load())
+	}
+}
@@ -6,7 +6,7 @@ import (
 	"time"
 
 	"github.com/kloudmate/polylang-detector/detector"
-	corev1 "k8s.io/api/core/v1"
+	"github.com/kloudmate/polylang-detector/pkg/logger"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
@@ -30,6 +30,10 @@ func ScanPodsEbpf(ctx context.Context, clientset *kubernetes.Clientset, pd *dete
 		scanPodsPeriodicFallback(ctx, clientset, pd)
 		return
 	}
+	ebpfDetector.Options = pd.Options
+	ebpfDetector.ShouldMonitorNamespace = pd.ShouldMonitorNamespace
+	ebpfDetector.ShouldEnqueue = pd.ShouldEnqueue
+	ebpfDetector.SendPodResult = pd.SendPodDetectionResult
 
 	// Start the eBPF detector (pod watching + mount-based detection)
 	if err := ebpfDetector.Start(ctx); err != nil {
@@ -38,70 +42,71 @@ func ScanPodsEbpf(ctx context.Context, clientset *kubernetes.Clientset, pd *dete
 		return
 	}
 
-	// Wait for context cancellation
+	// Wait for context cancellation, then tear the detector down before reporting the scan stopped
 	<-ctx.Done()
+	ebpfDetector.Stop()
 	pd.DomainLogger.(interface {
 		EbpfScanStopped()
 	}).EbpfScanStopped()
 }
 
-// scanPodsPeriodicFallback is the fallback when eBPF is not available
+// scanPodsPeriodicFallback is the fallback when eBPF is not available. It rescans pods every
+// KM_RESCAN_INTERVAL and forgets which pods were processed every KM_RECONCILE_INTERVAL, so
+// they are detected again.
 func scanPodsPeriodicFallback(ctx context.Context, clientset *kubernetes.Clientset, pd *detector.PolylangDetector) {
 	// Track processed pods to avoid duplicate processing
 	processedPods := sync.Map{}
+	lastResync := time.Now()
 
-	// Periodic scanning with configurable interval
-	scanInterval := 30 * time.Second
-	ticker := time.NewTicker(scanInterval)
-	defer ticker.Stop()
-
-	// Perform initial scan immediately
-	scanAllPods(ctx, clientset, pd, &processedPods)
-
-	// Periodic re-sync: Clear processedPods cache every 5 minutes to allow re-detection
-	resyncTicker := time.NewTicker(6 * time.Minute)
-	defer resyncTicker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			// Scan pods periodically
-			scanAllPods(ctx, clientset, pd, &processedPods)
-		case <-resyncTicker.C:
-			// Clear the processed pods map to allow re-detection
+	detector.RunPeriodic(ctx, "fallback pod rescan", pd.Options.RescanInterval, func(ctx context.Context) {
+		// Clear the processed pods map to allow re-detection; runs never overlap, so no scan sees it half-cleared
+		if time.Since(lastResync) >= pd.Options.ReconcileInterval {
 			processedPods.Range(func(key, value interface{}) bool {
 				processedPods.Delete(key)
 				return true
 			})
+			lastResync = time.Now()
 			pd.Logger.Sugar().Info("Cleared processed pods cache for re-sync")
 		}
-	}
+		scanAllPods(ctx, clientset, pd, &processedPods)
+	}, pd.Logger)
 }
 
-// scanAllPods scans all running pods in the cluster
+// scanAllPods scans all running pods in the cluster, returning once every detection it
+// started has finished
 func scanAllPods(ctx context.Context, clientset *kubernetes.Clientset, pd *detector.PolylangDetector, processedPods *sync.Map) {
+	// Every event of this cycle carries its scan ID, including those of concurrent detections
+	ctx, scanID := detector.WithNewScanID(ctx)
+	scanLogger := detector.ScanLogger(ctx, pd.Logger)
+	log := scanLogger.Sugar()
+	// Loggers without WithScanID fall back to domain events on the scan-scoped zap logger
+	events := &logger.DomainLogger{Logger: scanLogger}
+	if scoped, ok := pd.DomainLogger.(interface {
+		WithScanID(scanID string) *logger.DomainLogger
+	}); ok {
+		events = scoped.WithScanID(scanID)
+	}
+
 	pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
 	if err != nil {
-		pd.Logger.Sugar().Errorf("Error fetching pods: %v", err)
+		log.Errorf("Error fetching pods: %v", err)
 		return
 	}
 
-	pd.DomainLogger.(interface {
-		EbpfScanCycleStarted(count int)
-	}).EbpfScanCycleStarted(len(pods.Items))
+	events.EbpfScanCycleStarted(len(pods.Items))
 
 	var detectedCount int
-	for _, pod := range pods.Items {
+	var detections sync.WaitGroup
+	// Interleave namespaces so large namespaces don't starve smaller ones within a cycle
+	for _, pod := range detector.InterleavePodsByNamespace(pods.Items) {
 		// Check if namespace should be monitored
 		// Priority: KM_K8S_MONITORED_NAMESPACES > KM_IGNORED_NS
 		if !pd.ShouldMonitorNamespace(pod.Namespace) {
 			continue
 		}
 
-		// Only scan running pods
-		if pod.Status.Phase != corev1.PodRunning {
+		// Only scan running, ready pods that aren't terminating
+		if !pd.Options.ShouldDetectPod(&pod) {
 			continue
 		}
 
@@ -113,26 +118,47 @@ func scanAllPods(ctx context.Context, clientset *kubernetes.Clientset, pd *detec
 			continue
 		}
 
+		// Skip pods backing off after repeated detection failures
+		if !pd.FailureBackoff.ShouldAttempt(&pod) {
+			continue
+		}
+
 		// Mark as processed
 		processedPods.Store(key, true)
 
-		// Detect language using /proc inspection
-		go func(p corev1.Pod) {
-			containerInfos, err := pd.DetectLanguageWithProcInspection(p.Namespace, p.Name)
+		// Detect language using /proc inspection, bounded by the scan worker pool
+		p := pod
+		detections.Add(1)
+		if !pd.ScanPool.Go(ctx, func() {
+			defer detections.Done()
+			containerInfos, err := pd.DetectLanguageWithProcInspectionContext(ctx, p.Namespace, p.Name)
 			if err != nil {
-				pd.DomainLogger.LanguageDetectionFailed(p.Namespace, p.Name, "", err)
-				// Remove from processed so we can retry
+				events.LanguageDetectionFailed(p.Namespace, p.Name, "", err)
+				if failures, undetectable := pd.FailureBackoff.RecordFailure(&p); undetectable {
+					log.Warnw("Pod marked undetectable until its images change",
+						"namespace", p.Namespace,
+						"pod", p.Name,
+						"failures", failures,
+					)
+				}
+				// Remove from processed so we can retry once the backoff expires
 				processedPods.Delete(p.Namespace + "/" + p.Name)
 				return
 			}
+			pd.FailureBackoff.RecordSuccess(&p)
 
 			for _, info := range containerInfos {
-				pd.Logger.Sugar().Infow("/proc inspection completed",
+				log.Infow("/proc inspection completed",
 					"container_name", info.ContainerName,
 					"image", info.Image,
+					"runtime", info.Runtime,
 					"language", info.Language,
 					"framework", info.Framework,
+					"version", info.Version,
 					"confidence", info.Confidence,
+					"ports", info.Ports,
+					"architecture", info.Architecture,
+					"libc", info.LibcType,
 					"namespace", info.Namespace,
 					"deployment_name", info.DeploymentName,
 					"deployment_kind", info.Kind,
@@ -140,17 +166,30 @@ func scanAllPods(ctx context.Context, clientset *kubernetes.Clientset, pd *detec
 					"detected_at", info.DetectedAt,
 				)
 
-				// Send to queue if supported language
-				if _, ok := detector.OtelSupportedLanguages[info.Language]; ok {
+				// Send to queue if supported language and confident enough
+				if pd.ShouldEnqueue(info, scanLogger) {
 					pd.Queue <- info
 				}
 			}
-		}(pod)
+
+			podResult := detector.NewPodDetectionResult(p.Namespace, p.Name, containerInfos, pd.Options)
+			log.Infow("Pod language resolved",
+				"namespace", podResult.Namespace,
+				"pod_name", podResult.PodName,
+				"primary_container", podResult.PrimaryContainer,
+				"primary_language", podResult.PrimaryLanguage,
+				"containers", len(podResult.Containers),
+			)
+			pd.SendPodDetectionResult(podResult)
+		}) {
+			detections.Done()
+			detections.Wait()
+			return
+		}
 
 		detectedCount++
 	}
 
-	pd.DomainLogger.(interface {
-		EbpfScanCycleCompleted(scanned, detected int)
-	}).EbpfScanCycleCompleted(len(pods.Items), detectedCount)
+	detections.Wait()
+	events.EbpfScanCycleCompleted(len(pods.Items), detectedCount)
 }